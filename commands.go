@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rancher/eks-operator/controller"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	awsservices "github.com/rancher/eks-operator/pkg/eks"
+	ekscontrollers "github.com/rancher/eks-operator/pkg/generated/controllers/eks.cattle.io"
+	core3 "github.com/rancher/wrangler/pkg/generated/controllers/core"
+	"github.com/rancher/wrangler/pkg/kubeconfig"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// runCommand runs the subcommand named by the first argument instead of the operator.
+func runCommand(ctx context.Context, args []string) error {
+	switch args[0] {
+	case "discover":
+		return discover(ctx, args[1:])
+	case "validate":
+		return validate(args[1:])
+	case "render":
+		return render(args[1:])
+	default:
+		return fmt.Errorf("unknown command [%s]", args[0])
+	}
+}
+
+// discover generates an imported EKSClusterConfig for each EKS cluster, in the given regions, that does not
+// have one yet. The configs are created, or printed as YAML with --dry-run.
+func discover(ctx context.Context, args []string) error {
+	var secret, regions, namespace string
+	var dryRun bool
+	flags := flag.NewFlagSet("discover", flag.ContinueOnError)
+	flags.StringVar(&secret, "secret", "", "Credential secret, as namespace:name, used to list the clusters.")
+	flags.StringVar(&regions, "regions", "", "Comma separated list of the regions to discover clusters in.")
+	flags.StringVar(&namespace, "namespace", "", "Namespace of the generated configs. Defaults to the namespace of the credential secret.")
+	flags.BoolVar(&dryRun, "dry-run", false, "Print the generated configs as YAML instead of creating them.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	secretNamespace, _, ok := strings.Cut(secret, ":")
+	if !ok {
+		return errors.New("--secret must be set as namespace:name")
+	}
+	if regions == "" {
+		return errors.New("--regions must be set")
+	}
+	if namespace == "" {
+		namespace = secretNamespace
+	}
+
+	cfg, err := kubeconfig.GetNonInteractiveClientConfig(kubeconfigFile).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("error building kubeconfig: %w", err)
+	}
+	core, err := core3.NewFactoryFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("error building core factory: %w", err)
+	}
+	eks, err := ekscontrollers.NewFactoryFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("error building eks factory: %w", err)
+	}
+
+	secretsCache := core.Core().V1().Secret().Cache()
+	if err := core.Sync(ctx); err != nil {
+		return fmt.Errorf("error syncing secrets cache: %w", err)
+	}
+
+	eksCC := eks.Eks().V1().EKSClusterConfig()
+	existingConfigs, err := eksCC.List("", metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing EKSClusterConfigs: %w", err)
+	}
+
+	configs, err := controller.DiscoverClusters(&controller.DiscoverClustersOpts{
+		SecretsCache:           secretsCache,
+		AmazonCredentialSecret: secret,
+		Regions:                strings.Split(regions, ","),
+		Namespace:              namespace,
+		ExistingConfigs:        existingConfigs.Items,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, config := range configs {
+		if dryRun {
+			if i != 0 {
+				fmt.Fprintln(os.Stdout, "---")
+			}
+			if err := printConfig(config); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := eksCC.Create(config); err != nil {
+			return fmt.Errorf("error creating EKSClusterConfig [%s/%s]: %w", config.Namespace, config.Name, err)
+		}
+		logrus.Infof("created EKSClusterConfig [%s/%s] for cluster [%s] in region [%s]",
+			config.Namespace, config.Name, config.Spec.DisplayName, config.Spec.Region)
+	}
+
+	return nil
+}
+
+// printConfig prints the config as YAML without its empty status and creation timestamp.
+func printConfig(config *eksv1.EKSClusterConfig) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	delete(obj, "status")
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+
+	data, err = yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// validate checks the EKSClusterConfigs of a manifest without access to AWS or to a cluster.
+func validate(args []string) error {
+	var file string
+	var update bool
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.StringVar(&file, "f", "", "Manifest of the EKSClusterConfigs to validate, - to read from stdin.")
+	flags.BoolVar(&update, "update", false, "Validate the configs as updates of existing clusters instead of new ones.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	configs, err := readConfigs(file)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, config := range configs {
+		validateConfig := controller.ValidateCreate
+		if update {
+			validateConfig = controller.ValidateUpdate
+		}
+		if err := validateConfig(config); err != nil {
+			errs = append(errs, fmt.Sprintf("EKSClusterConfig [%s]: %v", config.Name, err))
+			continue
+		}
+		logrus.Infof("EKSClusterConfig [%s] is valid", config.Name)
+	}
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+// render prints, as YAML, the requests the operator sends to create the clusters of the EKSClusterConfigs of a
+// manifest.
+func render(args []string) error {
+	var file string
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.StringVar(&file, "f", "", "Manifest of the EKSClusterConfigs to render, - to read from stdin.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	configs, err := readConfigs(file)
+	if err != nil {
+		return err
+	}
+
+	for i, config := range configs {
+		rendered, err := awsservices.RenderCluster(&awsservices.RenderClusterOpts{
+			Config: config,
+		})
+		if err != nil {
+			return fmt.Errorf("error rendering EKSClusterConfig [%s]: %w", config.Name, err)
+		}
+		data, err := yaml.Marshal(rendered)
+		if err != nil {
+			return err
+		}
+		if i != 0 {
+			fmt.Fprintln(os.Stdout, "---")
+		}
+		if _, err := os.Stdout.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readConfigs reads the EKSClusterConfigs of a YAML or JSON manifest, skipping documents of other kinds.
+func readConfigs(file string) ([]*eksv1.EKSClusterConfig, error) {
+	var reader io.Reader
+	switch file {
+	case "":
+		return nil, errors.New("-f must be set")
+	case "-":
+		reader = os.Stdin
+	default:
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	var configs []*eksv1.EKSClusterConfig
+	decoder := utilyaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		config := &eksv1.EKSClusterConfig{}
+		if err := decoder.Decode(config); err == io.EOF {
+			return configs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("error reading manifest [%s]: %w", file, err)
+		}
+		if config.Kind != "EKSClusterConfig" {
+			continue
+		}
+		configs = append(configs, config)
+	}
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	awsservices "github.com/rancher/eks-operator/pkg/eks"
+	"github.com/rancher/wrangler/pkg/condition"
+)
+
+const (
+	// capacityIssuesCondition is true while node groups to create would exceed the vCPU quotas of the account or
+	// use instance types not offered in the availability zones of their subnets.
+	capacityIssuesCondition condition.Cond = "CapacityIssues"
+
+	nodegroupCapacityIssuesReason = "NodeGroupCapacityIssues"
+)
+
+// checkNodegroupCapacity checks the capacity of the node groups about to be created and records the problems
+// found in the capacity issues condition of the config, which is only updated if it changed. It returns an error
+// if a node group cannot be created.
+func (h *Handler) checkNodegroupCapacity(config *eksv1.EKSClusterConfig, nodegroups []eksv1.NodeGroup, awsSVCs *awsServices) (*eksv1.EKSClusterConfig, error) {
+	var problems []string
+	for _, ng := range nodegroups {
+		ngProblems, err := awsservices.CheckNodeGroupCapacity(&awsservices.CheckNodeGroupCapacityOpts{
+			EC2Service:           awsSVCs.ec2,
+			ServiceQuotasService: awsSVCs.servicequotas,
+			Config:               config,
+			NodeGroup:            ng,
+		})
+		if err != nil {
+			return config, err
+		}
+		problems = append(problems, ngProblems...)
+	}
+
+	updated := config.DeepCopy()
+	if len(problems) != 0 {
+		capacityIssuesCondition.True(updated)
+		capacityIssuesCondition.Reason(updated, nodegroupCapacityIssuesReason)
+		capacityIssuesCondition.Message(updated, strings.Join(problems, "; "))
+	} else if capacityIssuesCondition.IsTrue(config) {
+		capacityIssuesCondition.False(updated)
+		capacityIssuesCondition.Reason(updated, "")
+		capacityIssuesCondition.Message(updated, "")
+	}
+
+	if !reflect.DeepEqual(config.Status, updated.Status) {
+		var err error
+		if config, err = h.eksCC.UpdateStatus(updated); err != nil {
+			return config, err
+		}
+	}
+	if len(problems) != 0 {
+		return config, fmt.Errorf("insufficient capacity for nodegroups of cluster [%s]: %s", config.Name, strings.Join(problems, "; "))
+	}
+
+	return config, nil
+}
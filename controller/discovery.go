@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	awsservices "github.com/rancher/eks-operator/pkg/eks"
+	"github.com/rancher/eks-operator/pkg/eks/services"
+	wranglerv1 "github.com/rancher/wrangler/pkg/generated/controllers/core/v1"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type DiscoverClustersOpts struct {
+	SecretsCache           wranglerv1.SecretCache
+	AmazonCredentialSecret string
+	Regions                []string
+	// Namespace is the namespace of the generated configs
+	Namespace string
+	// ExistingConfigs are the configs of the clusters already represented, clusters with the same display name and
+	// region are skipped
+	ExistingConfigs []eksv1.EKSClusterConfig
+}
+
+// DiscoverClusters returns an imported config, with the spec built from the upstream cluster, for each EKS cluster
+// in the regions that is not represented by one of the existing configs.
+func DiscoverClusters(opts *DiscoverClustersOpts) ([]*eksv1.EKSClusterConfig, error) {
+	existing := make(map[string]bool, len(opts.ExistingConfigs))
+	names := make(map[string]bool, len(opts.ExistingConfigs))
+	for _, config := range opts.ExistingConfigs {
+		existing[config.Spec.Region+"/"+config.Spec.DisplayName] = true
+		if config.Namespace == opts.Namespace {
+			names[config.Name] = true
+		}
+	}
+
+	var configs []*eksv1.EKSClusterConfig
+	for _, region := range opts.Regions {
+		spec := eksv1.EKSClusterConfigSpec{
+			AmazonCredentialSecret: opts.AmazonCredentialSecret,
+			Region:                 region,
+			Imported:               true,
+		}
+		awsSVCs, err := newAWSServices(opts.SecretsCache, spec)
+		if err != nil {
+			return nil, fmt.Errorf("error creating new AWS services for region [%s]: %w", region, err)
+		}
+
+		regionConfigs, err := discoverRegionClusters(spec, opts.Namespace, existing, names, awsSVCs)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, regionConfigs...)
+	}
+
+	return configs, nil
+}
+
+// discoverRegionClusters returns an imported config for each EKS cluster in the region of the spec that is not
+// in existing, the names of the returned configs are added to names.
+func discoverRegionClusters(spec eksv1.EKSClusterConfigSpec, namespace string, existing, names map[string]bool, awsSVCs *awsServices) ([]*eksv1.EKSClusterConfig, error) {
+	region := spec.Region
+	clusterNames, err := listClusters(awsSVCs.eks)
+	if err != nil {
+		return nil, fmt.Errorf("error listing clusters in region [%s]: %w", region, err)
+	}
+
+	var configs []*eksv1.EKSClusterConfig
+	for _, clusterName := range clusterNames {
+		if existing[region+"/"+clusterName] {
+			logrus.Infof("cluster [%s] in region [%s] already has a config, skipping", clusterName, region)
+			continue
+		}
+
+		config, err := discoverCluster(spec, clusterName, awsSVCs)
+		if err != nil {
+			return nil, err
+		}
+		config.Namespace = namespace
+		config.Name = discoveredConfigName(clusterName, region, names)
+		names[config.Name] = true
+		configs = append(configs, config)
+	}
+
+	return configs, nil
+}
+
+func listClusters(eksService services.EKSServiceInterface) ([]string, error) {
+	var clusterNames []string
+	input := &eks.ListClustersInput{}
+	for {
+		output, err := eksService.ListClusters(input)
+		if err != nil {
+			return nil, err
+		}
+		clusterNames = append(clusterNames, aws.StringValueSlice(output.Clusters)...)
+		if aws.StringValue(output.NextToken) == "" {
+			return clusterNames, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+func listNodegroups(eksService services.EKSServiceInterface, clusterName string) ([]string, error) {
+	var nodegroupNames []string
+	input := &eks.ListNodegroupsInput{ClusterName: aws.String(clusterName)}
+	for {
+		output, err := eksService.ListNodegroups(input)
+		if err != nil {
+			return nil, err
+		}
+		nodegroupNames = append(nodegroupNames, aws.StringValueSlice(output.Nodegroups)...)
+		if aws.StringValue(output.NextToken) == "" {
+			return nodegroupNames, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+func discoverCluster(spec eksv1.EKSClusterConfigSpec, clusterName string, awsSVCs *awsServices) (*eksv1.EKSClusterConfig, error) {
+	spec.DisplayName = clusterName
+	config := &eksv1.EKSClusterConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: eksv1.SchemeGroupVersion.String(),
+			Kind:       eksClusterConfigKind,
+		},
+		Spec: spec,
+	}
+
+	clusterState, err := awsservices.GetClusterState(&awsservices.GetClusterStatusOpts{
+		EKSService: awsSVCs.eks,
+		Config:     config,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing cluster [%s] in region [%s]: %w", clusterName, spec.Region, err)
+	}
+
+	nodegroupNames, err := listNodegroups(awsSVCs.eks, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("error listing nodegroups of cluster [%s] in region [%s]: %w", clusterName, spec.Region, err)
+	}
+	nodeGroupStates := make([]*eks.DescribeNodegroupOutput, 0, len(nodegroupNames))
+	for _, ngName := range nodegroupNames {
+		ng, err := awsSVCs.eks.DescribeNodegroup(&eks.DescribeNodegroupInput{
+			ClusterName:   aws.String(clusterName),
+			NodegroupName: aws.String(ngName),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing nodegroup [%s] of cluster [%s] in region [%s]: %w", ngName, clusterName, spec.Region, err)
+		}
+		nodeGroupStates = append(nodeGroupStates, ng)
+	}
+
+	upstreamSpec, _, err := BuildUpstreamClusterState(clusterName, "", clusterState, nodeGroupStates, awsSVCs.ec2, false)
+	if err != nil {
+		return nil, err
+	}
+	upstreamSpec.AmazonCredentialSecret = spec.AmazonCredentialSecret
+	upstreamSpec.Region = spec.Region
+	upstreamSpec.Imported = true
+	config.Spec = *upstreamSpec
+
+	return config, nil
+}
+
+// discoveredConfigName returns a name for the config of a discovered cluster that is not in use. The region is
+// appended when clusters in different regions have the same name, followed by a counter if that name is in use too.
+func discoveredConfigName(clusterName, region string, names map[string]bool) string {
+	baseName := strings.ToLower(strings.ReplaceAll(clusterName, "_", "-"))
+	if !names[baseName] {
+		return baseName
+	}
+
+	name := fmt.Sprintf("%s-%s", baseName, region)
+	for i := 2; names[name]; i++ {
+		name = fmt.Sprintf("%s-%s-%d", baseName, region, i)
+	}
+
+	return name
+}
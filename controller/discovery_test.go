@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/golang/mock/gomock"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoveredConfigName(t *testing.T) {
+	type discoveredConfigNameTestCase struct {
+		name         string
+		clusterName  string
+		names        map[string]bool
+		expectedName string
+	}
+	testCases := []discoveredConfigNameTestCase{
+		{
+			name:         "cluster name is used when it is free",
+			clusterName:  "test",
+			names:        map[string]bool{},
+			expectedName: "test",
+		},
+		{
+			name:         "cluster name is lowercased and underscores are replaced",
+			clusterName:  "My_Cluster",
+			names:        map[string]bool{},
+			expectedName: "my-cluster",
+		},
+		{
+			name:         "region is appended when the cluster name is in use",
+			clusterName:  "test",
+			names:        map[string]bool{"test": true},
+			expectedName: "test-us-west-2",
+		},
+		{
+			name:         "counter is appended until the name is free",
+			clusterName:  "test",
+			names:        map[string]bool{"test": true, "test-us-west-2": true, "test-us-west-2-2": true},
+			expectedName: "test-us-west-2-3",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedName, discoveredConfigName(tc.clusterName, "us-west-2", tc.names))
+		})
+	}
+}
+
+func TestListClusters(t *testing.T) {
+	type listClustersTestCase struct {
+		name             string
+		pages            []*eks.ListClustersOutput
+		err              error
+		expectedClusters []string
+	}
+	testCases := []listClustersTestCase{
+		{
+			name:  "no clusters",
+			pages: []*eks.ListClustersOutput{{}},
+		},
+		{
+			name:             "single page",
+			pages:            []*eks.ListClustersOutput{{Clusters: aws.StringSlice([]string{"a", "b"})}},
+			expectedClusters: []string{"a", "b"},
+		},
+		{
+			name: "clusters of all pages are returned",
+			pages: []*eks.ListClustersOutput{
+				{Clusters: aws.StringSlice([]string{"a", "b"}), NextToken: aws.String("token-1")},
+				{Clusters: aws.StringSlice([]string{"c"}), NextToken: aws.String("token-2")},
+				{Clusters: aws.StringSlice([]string{"d"})},
+			},
+			expectedClusters: []string{"a", "b", "c", "d"},
+		},
+		{
+			name: "error listing a page",
+			pages: []*eks.ListClustersOutput{
+				{Clusters: aws.StringSlice([]string{"a"}), NextToken: aws.String("token-1")},
+				nil,
+			},
+			err: errors.New("error listing clusters"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserts := assert.New(t)
+			mockController := gomock.NewController(t)
+			defer mockController.Finish()
+			eksServiceMock := mock_services.NewMockEKSServiceInterface(mockController)
+
+			var nextToken *string
+			for i, page := range tc.pages {
+				var err error
+				if i == len(tc.pages)-1 {
+					err = tc.err
+				}
+				eksServiceMock.EXPECT().ListClusters(&eks.ListClustersInput{NextToken: nextToken}).Return(page, err)
+				if page != nil {
+					nextToken = page.NextToken
+				}
+			}
+
+			clusters, err := listClusters(eksServiceMock)
+			if tc.err != nil {
+				asserts.ErrorIs(err, tc.err)
+				return
+			}
+			asserts.Nil(err)
+			asserts.Equal(tc.expectedClusters, clusters)
+		})
+	}
+}
+
+func TestListNodegroups(t *testing.T) {
+	type listNodegroupsTestCase struct {
+		name               string
+		pages              []*eks.ListNodegroupsOutput
+		err                error
+		expectedNodegroups []string
+	}
+	testCases := []listNodegroupsTestCase{
+		{
+			name:  "no nodegroups",
+			pages: []*eks.ListNodegroupsOutput{{}},
+		},
+		{
+			name:               "single page",
+			pages:              []*eks.ListNodegroupsOutput{{Nodegroups: aws.StringSlice([]string{"a", "b"})}},
+			expectedNodegroups: []string{"a", "b"},
+		},
+		{
+			name: "nodegroups of all pages are returned",
+			pages: []*eks.ListNodegroupsOutput{
+				{Nodegroups: aws.StringSlice([]string{"a", "b"}), NextToken: aws.String("token-1")},
+				{Nodegroups: aws.StringSlice([]string{"c"}), NextToken: aws.String("token-2")},
+				{Nodegroups: aws.StringSlice([]string{"d"})},
+			},
+			expectedNodegroups: []string{"a", "b", "c", "d"},
+		},
+		{
+			name: "error listing a page",
+			pages: []*eks.ListNodegroupsOutput{
+				{Nodegroups: aws.StringSlice([]string{"a"}), NextToken: aws.String("token-1")},
+				nil,
+			},
+			err: errors.New("error listing nodegroups"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserts := assert.New(t)
+			mockController := gomock.NewController(t)
+			defer mockController.Finish()
+			eksServiceMock := mock_services.NewMockEKSServiceInterface(mockController)
+
+			var nextToken *string
+			for i, page := range tc.pages {
+				var err error
+				if i == len(tc.pages)-1 {
+					err = tc.err
+				}
+				eksServiceMock.EXPECT().ListNodegroups(&eks.ListNodegroupsInput{ClusterName: aws.String("test"), NextToken: nextToken}).Return(page, err)
+				if page != nil {
+					nextToken = page.NextToken
+				}
+			}
+
+			nodegroups, err := listNodegroups(eksServiceMock, "test")
+			if tc.err != nil {
+				asserts.ErrorIs(err, tc.err)
+				return
+			}
+			asserts.Nil(err)
+			asserts.Equal(tc.expectedNodegroups, nodegroups)
+		})
+	}
+}
+
+func TestDiscoverRegionClusters(t *testing.T) {
+	type discoverRegionClustersTestCase struct {
+		name string
+		// clusters are the clusters of the region
+		clusters []string
+		existing map[string]bool
+		names    map[string]bool
+		// expectedConfigs maps the names of the discovered configs to the display names of their clusters
+		expectedConfigs map[string]string
+	}
+	testCases := []discoverRegionClustersTestCase{
+		{
+			name:            "all clusters are discovered",
+			clusters:        []string{"a", "b"},
+			existing:        map[string]bool{},
+			names:           map[string]bool{},
+			expectedConfigs: map[string]string{"a": "a", "b": "b"},
+		},
+		{
+			name:            "clusters with a config are skipped",
+			clusters:        []string{"a", "b"},
+			existing:        map[string]bool{"us-west-2/a": true},
+			names:           map[string]bool{"a": true},
+			expectedConfigs: map[string]string{"b": "b"},
+		},
+		{
+			name:     "clusters with the name of a config in another region get unique names",
+			clusters: []string{"a", "A"},
+			existing: map[string]bool{"us-east-1/a": true},
+			names:    map[string]bool{"a": true},
+			expectedConfigs: map[string]string{
+				"a-us-west-2":   "a",
+				"a-us-west-2-2": "A",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserts := assert.New(t)
+			mockController := gomock.NewController(t)
+			defer mockController.Finish()
+			eksServiceMock := mock_services.NewMockEKSServiceInterface(mockController)
+			awsSVCs := &awsServices{eks: eksServiceMock}
+
+			eksServiceMock.EXPECT().ListClusters(gomock.Any()).Return(&eks.ListClustersOutput{
+				Clusters: aws.StringSlice(tc.clusters),
+			}, nil)
+			for _, clusterName := range tc.clusters {
+				if tc.existing["us-west-2/"+clusterName] {
+					continue
+				}
+				eksServiceMock.EXPECT().DescribeCluster(&eks.DescribeClusterInput{Name: aws.String(clusterName)}).Return(&eks.DescribeClusterOutput{
+					Cluster: &eks.Cluster{
+						Name:               aws.String(clusterName),
+						Version:            aws.String("1.27"),
+						ResourcesVpcConfig: &eks.VpcConfigResponse{},
+					},
+				}, nil)
+				eksServiceMock.EXPECT().ListNodegroups(&eks.ListNodegroupsInput{ClusterName: aws.String(clusterName)}).Return(&eks.ListNodegroupsOutput{}, nil)
+			}
+
+			spec := eksv1.EKSClusterConfigSpec{
+				AmazonCredentialSecret: "cattle-global-data:cc-test",
+				Region:                 "us-west-2",
+				Imported:               true,
+			}
+			configs, err := discoverRegionClusters(spec, "default", tc.existing, tc.names, awsSVCs)
+			asserts.Nil(err)
+
+			discovered := make(map[string]string, len(configs))
+			for _, config := range configs {
+				asserts.Equal("default", config.Namespace)
+				asserts.Equal("us-west-2", config.Spec.Region)
+				asserts.Equal("cattle-global-data:cc-test", config.Spec.AmazonCredentialSecret)
+				asserts.True(config.Spec.Imported)
+				asserts.True(tc.names[config.Name])
+				discovered[config.Name] = config.Spec.DisplayName
+			}
+			asserts.Equal(tc.expectedConfigs, discovered)
+		})
+	}
+}
@@ -3,7 +3,10 @@ package controller
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"net"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +31,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/retry"
 )
 
@@ -40,6 +44,12 @@ const (
 	eksConfigUpdatingPhase   = "updating"
 	eksConfigImportingPhase  = "importing"
 	eksClusterConfigKind     = "EKSClusterConfig"
+
+	kubeconfigSecretKey = "kubeconfig"
+	// tokenExpirationAnnotation records on the secret of the cluster when the token of its kubeconfig expires
+	tokenExpirationAnnotation = "eks.cattle.io/token-expiration"
+	// tokenRefreshMargin is how long before it expires the token of the kubeconfig is refreshed
+	tokenRefreshMargin = 5 * time.Minute
 )
 
 type Handler struct {
@@ -55,6 +65,8 @@ type awsServices struct {
 	eks            services.EKSServiceInterface
 	ec2            services.EC2ServiceInterface
 	iam            services.IAMServiceInterface
+	sts            services.STSServiceInterface
+	servicequotas  services.ServiceQuotasServiceInterface
 }
 
 func Register(
@@ -203,13 +215,38 @@ func removeErrorMetadata(message string) (string, error) {
 }
 
 func (h *Handler) OnEksConfigRemoved(_ string, config *eksv1.EKSClusterConfig) (*eksv1.EKSClusterConfig, error) {
+	if aws.BoolValue(config.Spec.DeletionProtection) {
+		message := fmt.Sprintf("cluster [%s] has deletion protection enabled, clear deletionProtection to delete it", config.Name)
+		if config.Status.FailureMessage != message {
+			config = config.DeepCopy()
+			config.Status.FailureMessage = message
+			var err error
+			config, err = h.eksCC.UpdateStatus(config)
+			if err != nil {
+				return config, err
+			}
+		}
+		return config, errors.New(message)
+	}
+
 	awsSVCs, err := newAWSServices(h.secretsCache, config.Spec)
 	if err != nil {
 		return config, fmt.Errorf("error creating new AWS services: %w", err)
 	}
 
-	if config.Spec.Imported {
-		logrus.Infof("cluster [%s] is imported, will not delete EKS cluster", config.Name)
+	return h.deleteCluster(config, awsSVCs)
+}
+
+// deleteCluster deletes the EKS cluster of the config along with the resources created for it, unless the
+// ownership or the deletion policy of the config retains them.
+func (h *Handler) deleteCluster(config *eksv1.EKSClusterConfig, awsSVCs *awsServices) (*eksv1.EKSClusterConfig, error) {
+	if ownership := awsservices.GetOwnership(config.Spec); ownership != awsservices.OwnershipOwned {
+		logrus.Infof("cluster [%s] has ownership [%s], will not delete EKS cluster", config.Name, ownership)
+		return config, nil
+	}
+	deletionPolicy := aws.StringValue(config.Spec.DeletionPolicy)
+	if deletionPolicy == awsservices.DeletionPolicyRetain {
+		logrus.Infof("cluster [%s] has deletion policy [%s], will not delete EKS cluster", config.Name, deletionPolicy)
 		return config, nil
 	}
 	if config.Status.Phase == eksConfigNotCreatedPhase {
@@ -218,17 +255,38 @@ func (h *Handler) OnEksConfigRemoved(_ string, config *eksv1.EKSClusterConfig) (
 		return config, nil
 	}
 
+	nodeGroups, ignoredNodeGroups, err := getNodeGroupsToDelete(config, awsSVCs.eks)
+	if err != nil {
+		return config, fmt.Errorf("error listing nodegroups for config [%s]: %w", config.Spec.DisplayName, err)
+	}
+	if len(ignoredNodeGroups) != 0 {
+		// EKS does not delete clusters with node groups, and ignored node groups are never deleted
+		message := fmt.Sprintf("cluster [%s] has nodegroups [%s] with the ignore policy, delete them or remove them from "+
+			"nodeGroupPolicies to delete the cluster", config.Name, strings.Join(ignoredNodeGroups, ", "))
+		if config.Status.FailureMessage != message {
+			config = config.DeepCopy()
+			config.Status.FailureMessage = message
+			config, err = h.eksCC.UpdateStatus(config)
+			if err != nil {
+				return config, err
+			}
+		}
+		return config, errors.New(message)
+	}
+
 	logrus.Infof("deleting cluster [%s]", config.Name)
 
 	logrus.Infof("starting node group deletion for config [%s]", config.Spec.DisplayName)
-	waitingForNodegroupDeletion := true
-	for waitingForNodegroupDeletion {
-		waitingForNodegroupDeletion, err = deleteNodeGroups(config, config.Spec.NodeGroups, awsSVCs.eks)
+	for {
+		waitingForNodegroupDeletion, err := deleteNodeGroups(config, nodeGroups, awsSVCs.eks)
 		if err != nil {
 			return config, fmt.Errorf("error deleting nodegroups for config [%s]", config.Spec.DisplayName)
 		}
-		time.Sleep(10 * time.Second)
+		if !waitingForNodegroupDeletion {
+			break
+		}
 		logrus.Infof("waiting for config [%s] node groups to delete", config.Name)
+		time.Sleep(10 * time.Second)
 	}
 
 	if config.Status.ManagedLaunchTemplateID != "" {
@@ -252,6 +310,14 @@ func (h *Handler) OnEksConfigRemoved(_ string, config *eksv1.EKSClusterConfig) (
 		}
 	}
 
+	if config.Spec.Imported {
+		// imported clusters promoted to owned only delete the resources discovered for them
+		if err := deleteDiscoveredStacks(config, awsSVCs.cloudformation); err != nil {
+			return config, err
+		}
+		return config, deleteDiscoveredRoles(config, awsSVCs.eks, awsSVCs.iam)
+	}
+
 	if aws.BoolValue(config.Spec.EBSCSIDriver) {
 		logrus.Infof("deleting ebs csi driver role for config [%s]", config.Name)
 		if err := deleteStack(awsSVCs.cloudformation, getEBSCSIDriverRoleStackName(config.Spec.DisplayName), getEBSCSIDriverRoleStackName(config.Spec.DisplayName)); err != nil {
@@ -266,7 +332,17 @@ func (h *Handler) OnEksConfigRemoved(_ string, config *eksv1.EKSClusterConfig) (
 		}
 	}
 
-	if len(config.Spec.Subnets) == 0 {
+	if config.Status.GeneratedKmsKey != "" {
+		logrus.Infof("deleting kms key for config [%s], it will be deleted after [%d] days", config.Name, awsservices.GetKMSKeyDeletionWindow(config.Spec))
+		if err := deleteStack(awsSVCs.cloudformation, getKMSKeyStackName(config.Spec.DisplayName), getKMSKeyStackName(config.Spec.DisplayName)); err != nil {
+			return config, fmt.Errorf("error deleting kms key stack: %v", err)
+		}
+	}
+
+	generatedNetwork := config.Status.NetworkFieldsSource == "generated"
+	if generatedNetwork && deletionPolicy == awsservices.DeletionPolicyRetainNetwork {
+		logrus.Infof("cluster [%s] has deletion policy [%s], will not delete vpc, subnets, and security groups", config.Name, deletionPolicy)
+	} else if generatedNetwork {
 		logrus.Infof("deleting vpc, subnets, and security groups for config [%s]", config.Name)
 		if err := deleteStack(awsSVCs.cloudformation, getVPCStackName(config.Spec.DisplayName), getVPCStackName(config.Spec.DisplayName)); err != nil {
 			return config, fmt.Errorf("error deleting vpc stack: %v", err)
@@ -286,7 +362,29 @@ func (h *Handler) checkAndUpdate(config *eksv1.EKSClusterConfig, awsSVCs *awsSer
 		return config, fmt.Errorf("aws services not initialized")
 	}
 
-	if err := validateUpdate(config); err != nil {
+	clusterState, err := awsservices.GetClusterState(&awsservices.GetClusterStatusOpts{
+		EKSService: awsSVCs.eks,
+		Config:     config,
+	})
+	if err != nil {
+		return config, err
+	}
+
+	// the secret is kept up to date with the cluster even while the config fails validation
+	if err := h.createOrUpdateCASecret(config, clusterState, awsSVCs.sts); err != nil {
+		return config, fmt.Errorf("error updating secret of cluster [%s]: %w", config.Name, err)
+	}
+
+	if awsservices.NeedsDiscovery(config) {
+		// the resources are discovered before the spec is validated, as imported clusters can only be promoted to owned
+		// once they are
+		config, err = h.recordDiscoveredResources(config, clusterState, awsSVCs)
+		if err != nil {
+			return config, err
+		}
+	}
+
+	if err := ValidateUpdate(config); err != nil {
 		// validation failed, will be considered a failing update until resolved
 		config = config.DeepCopy()
 		config.Status.Phase = eksConfigUpdatingPhase
@@ -298,13 +396,21 @@ func (h *Handler) checkAndUpdate(config *eksv1.EKSClusterConfig, awsSVCs *awsSer
 		return config, err
 	}
 
-	clusterState, err := awsservices.GetClusterState(&awsservices.GetClusterStatusOpts{
-		EKSService: awsSVCs.eks,
-		Config:     config,
-	})
+	config, updatesInProgress, err := h.checkUpdates(config, awsSVCs.eks)
 	if err != nil {
 		return config, err
 	}
+	if updatesInProgress {
+		// updates sent by the operator must finish before their result is known
+		logrus.Infof("waiting for updates of cluster [%s] to finish", config.Name)
+		if config.Status.Phase != eksConfigUpdatingPhase {
+			config = config.DeepCopy()
+			config.Status.Phase = eksConfigUpdatingPhase
+			return h.eksCC.UpdateStatus(config)
+		}
+		h.eksEnqueueAfter(config.Namespace, config.Name, 30*time.Second)
+		return config, nil
+	}
 
 	if aws.StringValue(clusterState.Cluster.Status) == eks.ClusterStatusUpdating {
 		// upstream cluster is already updating, must wait until sending next update
@@ -318,6 +424,13 @@ func (h *Handler) checkAndUpdate(config *eksv1.EKSClusterConfig, awsSVCs *awsSer
 		return config, nil
 	}
 
+	if clusterSecurityGroupID := aws.StringValue(clusterState.Cluster.ResourcesVpcConfig.ClusterSecurityGroupId); clusterSecurityGroupID != config.Status.ClusterSecurityGroupID {
+		// The cluster security group is needed to build launch templates for node groups with their own security groups.
+		config = config.DeepCopy()
+		config.Status.ClusterSecurityGroupID = clusterSecurityGroupID
+		return h.eksCC.UpdateStatus(config)
+	}
+
 	ngs, err := awsSVCs.eks.ListNodegroups(
 		&eks.ListNodegroupsInput{
 			ClusterName: aws.String(config.Spec.DisplayName),
@@ -357,7 +470,13 @@ func (h *Handler) checkAndUpdate(config *eksv1.EKSClusterConfig, awsSVCs *awsSer
 		nodegroupARNs[aws.StringValue(ngName)] = aws.StringValue(ng.Nodegroup.NodegroupArn)
 	}
 
-	if config.Status.Phase == eksConfigActivePhase && len(config.Status.TemplateVersionsToDelete) != 0 {
+	config, err = h.recordNodeGroupStatus(config, nodeGroupStates)
+	if err != nil {
+		return config, err
+	}
+
+	ownership := awsservices.GetOwnership(config.Spec)
+	if ownership != awsservices.OwnershipObserveOnly && config.Status.Phase == eksConfigActivePhase && len(config.Status.TemplateVersionsToDelete) != 0 {
 		// If there are any launch template versions that need to be cleaned up, we do it now.
 		awsservices.DeleteLaunchTemplateVersions(awsSVCs.ec2, config.Status.ManagedLaunchTemplateID, aws.StringSlice(config.Status.TemplateVersionsToDelete))
 		config = config.DeepCopy()
@@ -370,41 +489,77 @@ func (h *Handler) checkAndUpdate(config *eksv1.EKSClusterConfig, awsSVCs *awsSer
 		return config, err
 	}
 
-	return h.updateUpstreamClusterState(upstreamSpec, config, awsSVCs, clusterARN, nodegroupARNs)
+	config, err = h.recordUpstreamSpec(config, upstreamSpec, clusterState)
+	if err != nil {
+		return config, err
+	}
+
+	if ownership == awsservices.OwnershipObserveOnly {
+		// the state of the cluster is recorded but changes to the spec are not applied
+		if config.Status.Phase != eksConfigActivePhase {
+			config = config.DeepCopy()
+			config.Status.Phase = eksConfigActivePhase
+			return h.eksCC.UpdateStatus(config)
+		}
+		return config, nil
+	}
+
+	return h.updateUpstreamClusterState(upstreamSpec, config, awsSVCs, clusterState, clusterARN, nodegroupARNs)
 }
 
-func validateUpdate(config *eksv1.EKSClusterConfig) error {
-	var clusterVersion *semver.Version
-	if config.Spec.KubernetesVersion != nil {
-		var err error
-		clusterVersion, err = semver.New(fmt.Sprintf("%s.0", aws.StringValue(config.Spec.KubernetesVersion)))
-		if err != nil {
-			return fmt.Errorf("improper version format for cluster [%s]: %s", config.Name, aws.StringValue(config.Spec.KubernetesVersion))
+// ValidateUpdate checks the spec of a config whose cluster already exists. It does not require access to AWS.
+// While the control plane is upgraded to the kubernetes version of the spec, node group versions compatible with
+// the upstream version recorded in the status are also accepted, as the upgrade brings them along step by step.
+func ValidateUpdate(config *eksv1.EKSClusterConfig) error {
+	clusterVersion := aws.StringValue(config.Spec.KubernetesVersion)
+	if clusterVersion != "" {
+		if _, err := semver.New(fmt.Sprintf("%s.0", clusterVersion)); err != nil {
+			return fmt.Errorf("improper version format for cluster [%s]: %s", config.Name, clusterVersion)
+		}
+	}
+	// the control plane is being upgraded when the upstream version can be upgraded to the version of the spec
+	var upstreamVersion string
+	if config.Status.UpstreamSpec != nil && clusterVersion != "" {
+		if version := config.Status.UpstreamSpec.KubernetesVersion; version != clusterVersion {
+			if _, err := awsservices.GetUpgradeSteps(version, clusterVersion); err == nil {
+				upstreamVersion = version
+			}
 		}
 	}
 
 	errs := make([]string, 0)
+	for _, err := range validateCluster(config) {
+		errs = append(errs, err.Error())
+	}
+	if err := awsservices.ValidateKMSKey(config); err != nil {
+		errs = append(errs, err.Error())
+	}
 	// validate nodegroup versions
 	for _, ng := range config.Spec.NodeGroups {
+		for _, err := range validateNodeGroup(ng) {
+			errs = append(errs, err.Error())
+		}
 		if ng.Version == nil {
 			continue
 		}
-		version, err := semver.New(fmt.Sprintf("%s.0", aws.StringValue(ng.Version)))
-		if err != nil {
-			errs = append(errs, fmt.Sprintf("improper version format for nodegroup [%s]: %s", aws.StringValue(ng.NodegroupName), aws.StringValue(ng.Version)))
+		version := aws.StringValue(ng.Version)
+		if _, err := semver.New(fmt.Sprintf("%s.0", version)); err != nil {
+			errs = append(errs, fmt.Sprintf("improper version format for nodegroup [%s]: %s", aws.StringValue(ng.NodegroupName), version))
 			continue
 		}
-		if clusterVersion == nil {
+		if clusterVersion == "" {
 			continue
 		}
-		if clusterVersion.EQ(*version) {
+		if withinSkew, _ := awsservices.IsWithinVersionSkew(version, clusterVersion); withinSkew {
 			continue
 		}
-		if clusterVersion.Minor-version.Minor == 1 {
-			continue
+		if upstreamVersion != "" {
+			if withinSkew, _ := awsservices.IsWithinVersionSkew(version, upstreamVersion); withinSkew {
+				continue
+			}
 		}
 		errs = append(errs, fmt.Sprintf("versions for cluster [%s] and nodegroup [%s] not compatible: all nodegroup kubernetes versions"+
-			"must be equal to or one minor version lower than the cluster kubernetes version", aws.StringValue(config.Spec.KubernetesVersion), aws.StringValue(ng.Version)))
+			"must be equal to or one minor version lower than the cluster kubernetes version", clusterVersion, version))
 	}
 	if len(errs) != 0 {
 		return fmt.Errorf(strings.Join(errs, ";"))
@@ -427,7 +582,13 @@ func (h *Handler) create(config *eksv1.EKSClusterConfig, awsSVCs *awsServices) (
 		return h.eksCC.UpdateStatus(config)
 	}
 
-	config, err := h.generateAndSetNetworking(config, awsSVCs)
+	// the provided roles are checked against IAM before the cluster is created, missing policies are only reported
+	config, err := h.checkRolePolicies(config, config.Spec.NodeGroups, awsSVCs)
+	if err != nil {
+		return config, err
+	}
+
+	config, err = h.generateAndSetNetworking(config, awsSVCs)
 	if err != nil {
 		return config, fmt.Errorf("error generating and setting networking: %w", err)
 	}
@@ -437,6 +598,11 @@ func (h *Handler) create(config *eksv1.EKSClusterConfig, awsSVCs *awsServices) (
 		return config, fmt.Errorf("error creating or getting service role: %w", err)
 	}
 
+	config, err = h.generateKMSKey(config, awsSVCs, roleARN)
+	if err != nil {
+		return config, fmt.Errorf("error generating kms key: %w", err)
+	}
+
 	if err := awsservices.CreateCluster(&awsservices.CreateClusterOptions{
 		EKSService: awsSVCs.eks,
 		Config:     config,
@@ -482,7 +648,6 @@ func (h *Handler) validateCreate(config *eksv1.EKSClusterConfig, awsSVCs *awsSer
 		}
 	}
 
-	// validate nodegroup version
 	if !config.Spec.Imported {
 		// Check for existing clusters in EKS with the same display name
 		listOutput, err := awsSVCs.eks.ListClusters(&eks.ListClustersInput{})
@@ -494,6 +659,31 @@ func (h *Handler) validateCreate(config *eksv1.EKSClusterConfig, awsSVCs *awsSer
 				return fmt.Errorf("cannot create cluster [%s] because a cluster in EKS exists with the same name", config.Spec.DisplayName)
 			}
 		}
+	}
+
+	if err := ValidateCreate(config); err != nil {
+		return err
+	}
+	if config.Spec.Imported {
+		return nil
+	}
+
+	// check the provided network against AWS, so that mistakes are reported before the cluster is created
+	return awsservices.ValidateNetwork(&awsservices.ValidateNetworkOpts{
+		EC2Service: awsSVCs.ec2,
+		Config:     config,
+	})
+}
+
+// ValidateCreate checks the spec of a config whose cluster has not been created or imported yet. It does not
+// require access to AWS, the checks against existing clusters are left to the controller.
+func ValidateCreate(config *eksv1.EKSClusterConfig) error {
+	if errs := validateCluster(config); len(errs) != 0 {
+		return fmt.Errorf("%w in cluster [%s]", errs[0], config.Name)
+	}
+
+	// validate nodegroup version
+	if !config.Spec.Imported {
 		cannotBeNilError := "field [%s] cannot be nil for non-import cluster [%s]"
 		if config.Spec.KubernetesVersion == nil {
 			return fmt.Errorf(cannotBeNilError, "kubernetesVersion", config.Name)
@@ -522,6 +712,12 @@ func (h *Handler) validateCreate(config *eksv1.EKSClusterConfig, awsSVCs *awsSer
 		if config.Spec.PublicAccessSources == nil {
 			return fmt.Errorf(cannotBeNilError, "publicAccessSources", config.Name)
 		}
+		if err := validateKubernetesNetworkConfig(config); err != nil {
+			return err
+		}
+		if err := awsservices.ValidateKMSKey(config); err != nil {
+			return fmt.Errorf("%w in cluster [%s]", err, config.Name)
+		}
 	}
 	for _, ng := range config.Spec.NodeGroups {
 		cannotBeNilError := "field [%s] cannot be nil for nodegroup [%s] in non-nil cluster [%s]"
@@ -543,7 +739,7 @@ func (h *Handler) validateCreate(config *eksv1.EKSClusterConfig, awsSVCs *awsSer
 				if ng.DiskSize == nil {
 					return fmt.Errorf(cannotBeNilError, "diskSize", *ng.NodegroupName, config.Name)
 				}
-				if !aws.BoolValue(ng.RequestSpotInstances) && ng.InstanceType == nil {
+				if !aws.BoolValue(ng.RequestSpotInstances) && ng.InstanceType == nil && ng.InstanceRequirements == nil {
 					return fmt.Errorf(cannotBeNilError, "instanceType", *ng.NodegroupName, config.Name)
 				}
 			}
@@ -577,12 +773,15 @@ func (h *Handler) validateCreate(config *eksv1.EKSClusterConfig, awsSVCs *awsSer
 			if ng.NodeRole == nil {
 				logrus.Warnf("nodeRole is not specified for nodegroup [%s] in cluster [%s], the controller will generate it", *ng.NodegroupName, config.Name)
 			}
-			if aws.BoolValue(ng.RequestSpotInstances) {
+			if errs := validateNodeGroup(ng); len(errs) != 0 {
+				return fmt.Errorf("%w in cluster [%s]", errs[0], config.Name)
+			}
+			if aws.BoolValue(ng.RequestSpotInstances) && ng.InstanceRequirements == nil {
 				if len(ng.SpotInstanceTypes) == 0 {
-					return fmt.Errorf("nodegroup [%s] in cluster [%s]: spotInstanceTypes must be specified when requesting spot instances", *ng.NodegroupName, config.Name)
+					return fmt.Errorf("nodegroup [%s] in cluster [%s]: spotInstanceTypes or instanceRequirements must be specified when requesting spot instances", *ng.NodegroupName, config.Name)
 				}
 				if aws.StringValue(ng.InstanceType) != "" {
-					return fmt.Errorf("nodegroup [%s] in cluster [%s]: instance type should not be specified when requestSpotInstances is specified, use spotInstanceTypes instead",
+					return fmt.Errorf("nodegroup [%s] in cluster [%s]: instance type should not be specified when requestSpotInstances is specified, use spotInstanceTypes or instanceRequirements instead",
 						*ng.NodegroupName, config.Name)
 				}
 			}
@@ -594,6 +793,70 @@ func (h *Handler) validateCreate(config *eksv1.EKSClusterConfig, awsSVCs *awsSer
 	return nil
 }
 
+// validateCluster runs the checks of the cluster options that apply on create and update alike and returns the
+// errors of the options that fail them.
+func validateCluster(config *eksv1.EKSClusterConfig) []error {
+	var errs []error
+	for _, err := range []error{
+		awsservices.ValidateKubeconfigAuth(config.Spec),
+		awsservices.ValidateNodeGroupPolicies(config.Spec),
+		awsservices.ValidateDeletionPolicy(config.Spec),
+		awsservices.ValidateOwnership(config),
+		awsservices.ValidateKMSKeyDeletionWindow(config.Spec),
+	} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// validateNodeGroup runs the checks of the node group options that apply on create and update alike and returns
+// the errors of the options that fail them.
+func validateNodeGroup(ng eksv1.NodeGroup) []error {
+	var errs []error
+	for _, err := range []error{
+		awsservices.ValidateAMIType(ng),
+		awsservices.ValidateBlockDeviceMappings(ng),
+		awsservices.ValidateMetadataOptions(ng),
+		awsservices.ValidateNetworkInterfaces(ng),
+		awsservices.ValidateUpdateConfig(ng),
+		awsservices.ValidateRemediation(ng),
+		awsservices.ValidateInstanceRequirements(ng),
+	} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func validateKubernetesNetworkConfig(config *eksv1.EKSClusterConfig) error {
+	networkConfig := config.Spec.KubernetesNetworkConfig
+	if networkConfig == nil {
+		return nil
+	}
+
+	switch ipFamily := aws.StringValue(networkConfig.IPFamily); ipFamily {
+	case "", eks.IpFamilyIpv4:
+		if cidr := aws.StringValue(networkConfig.ServiceIpv4Cidr); cidr != "" {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("invalid serviceIpv4Cidr [%s] for cluster [%s]: %w", cidr, config.Name, err)
+			}
+		}
+	case eks.IpFamilyIpv6:
+		if aws.StringValue(networkConfig.ServiceIpv4Cidr) != "" {
+			return fmt.Errorf("serviceIpv4Cidr cannot be set for cluster [%s] with ipFamily [%s]", config.Name, ipFamily)
+		}
+	default:
+		return fmt.Errorf("invalid ipFamily [%s] for cluster [%s], valid values are [%s]", ipFamily, config.Name, strings.Join(eks.IpFamily_Values(), ", "))
+	}
+
+	return nil
+}
+
 func (h *Handler) generateAndSetNetworking(config *eksv1.EKSClusterConfig, awsSVCs *awsServices) (*eksv1.EKSClusterConfig, error) {
 	if awsSVCs == nil {
 		return nil, fmt.Errorf("aws services not initialized")
@@ -613,11 +876,15 @@ func (h *Handler) generateAndSetNetworking(config *eksv1.EKSClusterConfig, awsSV
 		config.Status.NetworkFieldsSource = "provided"
 	} else {
 		logrus.Infof("Bringing up vpc")
+		vpcTemplate := templates.VpcTemplate
+		if awsservices.IsIPv6Cluster(config) {
+			vpcTemplate = templates.VpcIPv6Template
+		}
 		stack, err := awsservices.CreateStack(&awsservices.CreateStackOptions{
 			CloudFormationService: awsSVCs.cloudformation,
 			StackName:             getVPCStackName(config.Spec.DisplayName),
 			DisplayName:           config.Spec.DisplayName,
-			TemplateBody:          templates.VpcTemplate,
+			TemplateBody:          vpcTemplate,
 			Capabilities:          []string{},
 			Parameters:            []*cloudformation.Parameter{},
 		})
@@ -678,6 +945,29 @@ func (h *Handler) createOrGetServiceRole(config *eksv1.EKSClusterConfig, awsSVCs
 	return roleARN, nil
 }
 
+// generateKMSKey creates the KMS key used to encrypt secrets when the config enables secrets encryption without a
+// key, and records its ARN on the status. The role of the cluster is granted the use of the key.
+func (h *Handler) generateKMSKey(config *eksv1.EKSClusterConfig, awsSVCs *awsServices, roleARN string) (*eksv1.EKSClusterConfig, error) {
+	if !awsservices.NeedsGeneratedKMSKey(config) {
+		return config, nil
+	}
+
+	logrus.Infof("creating kms key for cluster [%s]", config.Name)
+	keyARN, err := awsservices.CreateKMSKey(&awsservices.CreateKMSKeyOpts{
+		CloudFormationService: awsSVCs.cloudformation,
+		Config:                config,
+		StackName:             getKMSKeyStackName(config.Spec.DisplayName),
+		RoleARN:               roleARN,
+	})
+	if err != nil {
+		return config, err
+	}
+
+	config = config.DeepCopy()
+	config.Status.GeneratedKmsKey = keyARN
+	return h.eksCC.UpdateStatus(config)
+}
+
 func newAWSServices(secretsCache wranglerv1.SecretCache, spec eksv1.EKSClusterConfigSpec) (*awsServices, error) {
 	sess, err := newAWSSession(secretsCache, spec)
 	if err != nil {
@@ -689,6 +979,8 @@ func newAWSServices(secretsCache wranglerv1.SecretCache, spec eksv1.EKSClusterCo
 		cloudformation: services.NewCloudFormationService(sess),
 		iam:            services.NewIAMService(sess),
 		ec2:            services.NewEC2Service(sess),
+		sts:            services.NewSTSService(sess),
+		servicequotas:  services.NewServiceQuotasService(sess),
 	}, nil
 }
 
@@ -757,12 +1049,13 @@ func (h *Handler) waitForCreationComplete(config *eksv1.EKSClusterConfig, awsSVC
 	}
 
 	if status == eks.ClusterStatusActive {
-		if err := h.createCASecret(config, state); err != nil {
+		if err := h.createOrUpdateCASecret(config, state, awsSVCs.sts); err != nil {
 			return config, err
 		}
 		logrus.Infof("cluster [%s] created successfully", config.Name)
 		config = config.DeepCopy()
 		config.Status.Phase = eksConfigActivePhase
+		config.Status.ClusterSecurityGroupID = aws.StringValue(state.Cluster.ResourcesVpcConfig.ClusterSecurityGroupId)
 		return h.eksCC.UpdateStatus(config)
 	}
 
@@ -841,6 +1134,7 @@ func BuildUpstreamClusterState(name, managedTemplateID string, clusterState *eks
 			Tags:                 ng.Nodegroup.Tags,
 			Version:              ng.Nodegroup.Version,
 			RequestSpotInstances: aws.Bool(aws.StringValue(ng.Nodegroup.CapacityType) == eks.CapacityTypesSpot),
+			UpdateConfig:         awsservices.GetUpdateConfig(ng.Nodegroup.UpdateConfig),
 		}
 
 		if aws.BoolValue(ngToAdd.RequestSpotInstances) {
@@ -886,6 +1180,10 @@ func BuildUpstreamClusterState(name, managedTemplateID string, clusterState *eks
 				launchTemplateData := launchTemplateRequestOutput.LaunchTemplateVersions[0].LaunchTemplateData
 
 				ngToAdd.DiskSize = launchTemplateData.BlockDeviceMappings[0].Ebs.VolumeSize
+				ngToAdd.BlockDeviceMappings = awsservices.GetBlockDeviceMappings(launchTemplateData.BlockDeviceMappings)
+				ngToAdd.MetadataOptions = awsservices.GetMetadataOptions(launchTemplateData.MetadataOptions)
+				ngToAdd.SecurityGroups, ngToAdd.NetworkInterfaces = awsservices.GetNetworkConfig(launchTemplateData,
+					aws.StringValue(clusterState.Cluster.ResourcesVpcConfig.ClusterSecurityGroupId))
 				ngToAdd.Ec2SshKey = launchTemplateData.KeyName
 				ngToAdd.ImageID = launchTemplateData.ImageId
 				ngToAdd.InstanceType = launchTemplateData.InstanceType
@@ -914,10 +1212,9 @@ func BuildUpstreamClusterState(name, managedTemplateID string, clusterState *eks
 				ngToAdd.Ec2SshKey = ng.Nodegroup.RemoteAccess.Ec2SshKey
 			}
 		}
-		if aws.StringValue(ng.Nodegroup.AmiType) == eks.AMITypesAl2X8664Gpu {
-			ngToAdd.Gpu = aws.Bool(true)
-		} else if aws.StringValue(ng.Nodegroup.AmiType) == eks.AMITypesAl2X8664 {
-			ngToAdd.Gpu = aws.Bool(false)
+		if amiType := aws.StringValue(ng.Nodegroup.AmiType); amiType != "" && amiType != eks.AMITypesCustom {
+			ngToAdd.AMIType = ng.Nodegroup.AmiType
+			ngToAdd.Gpu = aws.Bool(awsservices.IsGPUAMIType(amiType))
 		}
 		upstreamSpec.NodeGroups = append(upstreamSpec.NodeGroups, ngToAdd)
 	}
@@ -937,86 +1234,67 @@ func BuildUpstreamClusterState(name, managedTemplateID string, clusterState *eks
 	if upstreamSpec.ServiceRole == nil {
 		upstreamSpec.ServiceRole = aws.String("")
 	}
+
+	if networkConfig := clusterState.Cluster.KubernetesNetworkConfig; networkConfig != nil {
+		upstreamSpec.KubernetesNetworkConfig = &eksv1.KubernetesNetworkConfig{
+			IPFamily:        networkConfig.IpFamily,
+			ServiceIpv4Cidr: networkConfig.ServiceIpv4Cidr,
+		}
+	}
 	return upstreamSpec, aws.StringValue(clusterState.Cluster.Arn), nil
 }
 
+// recordUpstreamSpec sets a trimmed copy of the upstream spec, along with the facts of the cluster that cannot be
+// configured, on the status. The status is only updated if it changed.
+func (h *Handler) recordUpstreamSpec(config *eksv1.EKSClusterConfig, upstreamSpec *eksv1.EKSClusterConfigSpec, clusterState *eks.DescribeClusterOutput) (*eksv1.EKSClusterConfig, error) {
+	cluster := clusterState.Cluster
+	status := &eksv1.UpstreamSpec{
+		KubernetesVersion:       aws.StringValue(upstreamSpec.KubernetesVersion),
+		PlatformVersion:         aws.StringValue(cluster.PlatformVersion),
+		ARN:                     aws.StringValue(cluster.Arn),
+		Endpoint:                aws.StringValue(cluster.Endpoint),
+		VPCID:                   aws.StringValue(cluster.ResourcesVpcConfig.VpcId),
+		ClusterSecurityGroupID:  aws.StringValue(cluster.ResourcesVpcConfig.ClusterSecurityGroupId),
+		Subnets:                 upstreamSpec.Subnets,
+		SecurityGroups:          upstreamSpec.SecurityGroups,
+		PublicAccess:            aws.BoolValue(upstreamSpec.PublicAccess),
+		PrivateAccess:           aws.BoolValue(upstreamSpec.PrivateAccess),
+		PublicAccessSources:     upstreamSpec.PublicAccessSources,
+		LoggingTypes:            upstreamSpec.LoggingTypes,
+		Tags:                    upstreamSpec.Tags,
+		SecretsEncryption:       aws.BoolValue(upstreamSpec.SecretsEncryption),
+		KmsKey:                  aws.StringValue(upstreamSpec.KmsKey),
+		ServiceRole:             aws.StringValue(upstreamSpec.ServiceRole),
+		KubernetesNetworkConfig: upstreamSpec.KubernetesNetworkConfig,
+	}
+	if cluster.Identity != nil && cluster.Identity.Oidc != nil {
+		status.OIDCIssuer = aws.StringValue(cluster.Identity.Oidc.Issuer)
+	}
+
+	if reflect.DeepEqual(config.Status.UpstreamSpec, status) {
+		return config, nil
+	}
+
+	config = config.DeepCopy()
+	config.Status.UpstreamSpec = status
+	return h.eksCC.UpdateStatus(config)
+}
+
 // updateUpstreamClusterState compares the upstream spec with the config spec, then updates the upstream EKS cluster to
 // match the config spec. Function often returns after a single update because once the cluster is in updating phase in EKS,
 // no more updates will be accepted until the current update is finished.
-func (h *Handler) updateUpstreamClusterState(upstreamSpec *eksv1.EKSClusterConfigSpec, config *eksv1.EKSClusterConfig, awsSVCs *awsServices, clusterARN string, ngARNs map[string]string) (*eksv1.EKSClusterConfig, error) {
+func (h *Handler) updateUpstreamClusterState(upstreamSpec *eksv1.EKSClusterConfigSpec, config *eksv1.EKSClusterConfig, awsSVCs *awsServices, clusterState *eks.DescribeClusterOutput, clusterARN string, ngARNs map[string]string) (*eksv1.EKSClusterConfig, error) {
 	if awsSVCs == nil {
 		return config, fmt.Errorf("aws services not initialized")
 	}
 
-	// check kubernetes version for update
-	if config.Spec.KubernetesVersion != nil {
-		updated, err := awsservices.UpdateClusterVersion(&awsservices.UpdateClusterVersionOpts{
-			EKSService:          awsSVCs.eks,
-			Config:              config,
-			UpstreamClusterSpec: upstreamSpec,
-		})
-		if err != nil {
-			return config, fmt.Errorf("error updating cluster version: %w", err)
-		}
-		if updated {
-			return h.enqueueUpdate(config)
-		}
-	}
-
-	// check tags for update
-	if config.Spec.Tags != nil {
-		updated, err := awsservices.UpdateResourceTags(&awsservices.UpdateResourceTagsOpts{
-			EKSService:   awsSVCs.eks,
-			Tags:         config.Spec.Tags,
-			UpstreamTags: upstreamSpec.Tags,
-			ResourceARN:  clusterARN,
-		})
-		if err != nil {
-			return config, fmt.Errorf("error updating cluster tags: %w", err)
-		}
-		if updated {
-			return h.enqueueUpdate(config)
-		}
-	}
-
-	if config.Spec.LoggingTypes != nil {
-		// check logging for update
-		updated, err := awsservices.UpdateClusterLoggingTypes(&awsservices.UpdateLoggingTypesOpts{
-			EKSService:          awsSVCs.eks,
-			Config:              config,
-			UpstreamClusterSpec: upstreamSpec,
-		})
-		if err != nil {
-			return config, fmt.Errorf("error updating logging types: %w", err)
-		}
-		if updated {
-			return h.enqueueUpdate(config)
-		}
-	}
-
-	updated, err := awsservices.UpdateClusterAccess(&awsservices.UpdateClusterAccessOpts{
-		EKSService:          awsSVCs.eks,
-		Config:              config,
-		UpstreamClusterSpec: upstreamSpec,
-	})
-	if err != nil {
-		return config, fmt.Errorf("error updating cluster access config: %w", err)
-	}
-	if updated {
-		return h.enqueueUpdate(config)
-	}
-
-	if config.Spec.PublicAccessSources != nil {
-		updated, err := awsservices.UpdateClusterPublicAccessSources(&awsservices.UpdateClusterPublicAccessSourcesOpts{
-			EKSService:          awsSVCs.eks,
-			Config:              config,
-			UpstreamClusterSpec: upstreamSpec,
-		})
-		if err != nil {
-			return config, fmt.Errorf("error updating cluster public access sources: %w", err)
-		}
-		if updated {
-			return h.enqueueUpdate(config)
+	var err error
+	// cluster updates that failed for the current generation of the config are not retried until it changes
+	if config.Status.ClusterUpdate == nil || !updateFailed(*config.Status.ClusterUpdate, config.Generation) {
+		var updating bool
+		config, updating, err = h.updateUpstreamClusterProperties(upstreamSpec, config, awsSVCs, clusterState, clusterARN)
+		if err != nil || updating {
+			return config, err
 		}
 	}
 
@@ -1040,6 +1318,64 @@ func (h *Handler) updateUpstreamClusterState(upstreamSpec *eksv1.EKSClusterConfi
 		ngs[aws.StringValue(ng.NodegroupName)] = ng
 	}
 
+	// add node groups created outside of the operator with the adopt policy to the spec
+	var adoptedNodegroups []string
+	for _, upstreamNg := range upstreamSpec.NodeGroups {
+		nodegroupName := aws.StringValue(upstreamNg.NodegroupName)
+		if _, ok := ngs[nodegroupName]; ok || config.Spec.NodeGroupPolicies[nodegroupName] != awsservices.NodeGroupPolicyAdopt {
+			continue
+		}
+		if adoptedNodegroups == nil {
+			config = config.DeepCopy()
+		}
+		config.Spec.NodeGroups = append(config.Spec.NodeGroups, awsservices.AdoptNodeGroup(upstreamNg, config.Status.ManagedLaunchTemplateID))
+		adoptedNodegroups = append(adoptedNodegroups, nodegroupName)
+	}
+	if len(adoptedNodegroups) != 0 {
+		logrus.Infof("adopting nodegroups [%s] of cluster [%s]", strings.Join(adoptedNodegroups, ", "), config.Name)
+		return h.eksCC.Update(config)
+	}
+
+	for _, ng := range config.Spec.NodeGroups {
+		if upstreamNg, ok := upstreamNgs[aws.StringValue(ng.NodegroupName)]; ok {
+			if err := awsservices.ValidateAMITypeUpdate(upstreamNg, ng); err != nil {
+				return config, fmt.Errorf("%w in cluster [%s]", err, config.Name)
+			}
+		}
+	}
+
+	if config, err = h.resolveInstanceRequirements(config, upstreamNgs, awsSVCs); err != nil {
+		return config, err
+	}
+
+	// check that the node groups to create can be launched before creating any of them
+	var newNodegroups []eksv1.NodeGroup
+	for _, ng := range config.Spec.NodeGroups {
+		if _, ok := upstreamNgs[aws.StringValue(ng.NodegroupName)]; !ok {
+			newNodegroups = append(newNodegroups, ng)
+		}
+	}
+	if len(newNodegroups) != 0 && config.Status.UpstreamSpec != nil {
+		if err := awsservices.ValidateNodeGroupSubnets(&awsservices.ValidateNodeGroupSubnetsOpts{
+			EC2Service: awsSVCs.ec2,
+			Config:     config,
+			NodeGroups: newNodegroups,
+			VPCID:      config.Status.UpstreamSpec.VPCID,
+		}); err != nil {
+			return config, err
+		}
+	}
+	if len(newNodegroups) != 0 || missingRolePoliciesCondition.IsTrue(config) {
+		if config, err = h.checkRolePolicies(config, newNodegroups, awsSVCs); err != nil {
+			return config, err
+		}
+	}
+	if len(newNodegroups) != 0 || capacityIssuesCondition.IsTrue(config) {
+		if config, err = h.checkNodegroupCapacity(config, newNodegroups, awsSVCs); err != nil {
+			return config, err
+		}
+	}
+
 	// Deep copy the config object here, so it's not copied multiple times for each
 	// nodegroup create/delete.
 	config = config.DeepCopy()
@@ -1051,6 +1387,18 @@ func (h *Handler) updateUpstreamClusterState(upstreamSpec *eksv1.EKSClusterConfi
 		if _, ok := upstreamNgs[aws.StringValue(ng.NodegroupName)]; ok {
 			continue
 		}
+		if err := awsservices.ValidateRemoteAccess(&awsservices.ValidateRemoteAccessOpts{
+			EC2Service: awsSVCs.ec2,
+			NodeGroup:  ng,
+		}); err != nil {
+			return config, fmt.Errorf("%w in cluster [%s]", err, config.Name)
+		}
+		if err := awsservices.ValidateLaunchTemplateAMIType(&awsservices.ValidateLaunchTemplateAMITypeOpts{
+			EC2Service: awsSVCs.ec2,
+			NodeGroup:  ng,
+		}); err != nil {
+			return config, fmt.Errorf("%w in cluster [%s]", err, config.Name)
+		}
 		if err := awsservices.CreateLaunchTemplate(&awsservices.CreateLaunchTemplateOptions{
 			EC2Service: awsSVCs.ec2,
 			Config:     config,
@@ -1097,11 +1445,15 @@ func (h *Handler) updateUpstreamClusterState(upstreamSpec *eksv1.EKSClusterConfi
 		if _, ok := ngs[aws.StringValue(ng.NodegroupName)]; ok {
 			continue
 		}
+		if config.Spec.NodeGroupPolicies[aws.StringValue(ng.NodegroupName)] == awsservices.NodeGroupPolicyIgnore {
+			continue
+		}
 		templateVersionToDelete, _, err := deleteNodeGroup(config, ng, awsSVCs.eks)
 		if err != nil {
 			return config, err
 		}
 		updatingNodegroups = true
+		delete(config.Status.NodeGroupUpdates, aws.StringValue(ng.NodegroupName))
 		if templateVersionToDelete != nil {
 			templateVersionsToDelete[aws.StringValue(ng.NodegroupName)] = *templateVersionToDelete
 		}
@@ -1130,7 +1482,7 @@ func (h *Handler) updateUpstreamClusterState(upstreamSpec *eksv1.EKSClusterConfi
 		}
 	}
 
-	var updateNodegroupProperties bool
+	var updateNodegroupProperties, nodegroupUpdatesRecorded bool
 	templateVersionsToDelete = make(map[string]string)
 	for _, upstreamNg := range upstreamSpec.NodeGroups {
 		// if continue is used after an update, it means that update
@@ -1138,10 +1490,24 @@ func (h *Handler) updateUpstreamClusterState(upstreamSpec *eksv1.EKSClusterConfi
 		// Some updates such as minSize, maxSize, and desiredSize can
 		// happen together
 
-		ng := ngs[aws.StringValue(upstreamNg.NodegroupName)]
+		specNg, ok := ngs[aws.StringValue(upstreamNg.NodegroupName)]
+		if !ok {
+			// ignored node groups are only observed
+			continue
+		}
+		nodegroupName := aws.StringValue(specNg.NodegroupName)
+		previousStatus := getNodeGroupStatus(config, nodegroupName).DeepCopy()
+		ng, rollLaunchTemplate := remediateNodegroup(config, specNg)
+		remediated := previousStatus != nil && getNodeGroupStatus(config, nodegroupName).LastRemediationTime != previousStatus.LastRemediationTime
+		if update, ok := config.Status.NodeGroupUpdates[nodegroupName]; ok && updateFailed(update, config.Generation) && !remediated {
+			// node group updates that failed for the current generation of the config are not retried until it
+			// changes, unless the node group is remediated
+			continue
+		}
 		ngVersionInput := &eks.UpdateNodegroupVersionInput{
 			NodegroupName: aws.String(aws.StringValue(ng.NodegroupName)),
 			ClusterName:   aws.String(config.Spec.DisplayName),
+			Force:         aws.Bool(awsservices.IsForceUpgrade(ng)),
 		}
 
 		if upstreamNg.LaunchTemplate != nil {
@@ -1155,6 +1521,12 @@ func (h *Handler) updateUpstreamClusterState(upstreamSpec *eksv1.EKSClusterConfi
 				if err != nil {
 					return config, err
 				}
+				if lt == nil && rollLaunchTemplate {
+					lt, err = awsservices.CreateNewLaunchTemplateVersion(awsSVCs.ec2, config.Status.ManagedLaunchTemplateID, config.Status.ClusterSecurityGroupID, ng)
+					if err != nil {
+						return config, err
+					}
+				}
 
 				if lt != nil {
 					if upstreamTemplateVersion > 0 {
@@ -1180,26 +1552,33 @@ func (h *Handler) updateUpstreamClusterState(upstreamSpec *eksv1.EKSClusterConfi
 
 		if ngVersionInput.Version != nil || ngVersionInput.LaunchTemplate != nil {
 			updateNodegroupProperties = true
-			if err := awsservices.UpdateNodegroupVersion(&awsservices.UpdateNodegroupVersionOpts{
+			update, err := awsservices.UpdateNodegroupVersion(&awsservices.UpdateNodegroupVersionOpts{
 				EKSService:     awsSVCs.eks,
 				EC2Service:     awsSVCs.ec2,
 				Config:         config,
 				NodeGroup:      &ng,
 				NGVersionInput: ngVersionInput,
 				LTVersions:     templateVersionsToAdd,
-			}); err != nil {
+			})
+			if err != nil {
 				return config, err
 			}
+			recordNodegroupUpdate(config, aws.StringValue(ng.NodegroupName), update)
+			nodegroupUpdatesRecorded = nodegroupUpdatesRecorded || update != nil
 			continue
 		}
 		updateNodegroupConfig, sendUpdateNodegroupConfig := getNodegroupConfigUpdate(config.Spec.DisplayName, ng, upstreamNg)
 
 		if sendUpdateNodegroupConfig {
 			updateNodegroupProperties = true
-			_, err := awsSVCs.eks.UpdateNodegroupConfig(&updateNodegroupConfig)
+			output, err := awsSVCs.eks.UpdateNodegroupConfig(&updateNodegroupConfig)
 			if err != nil {
 				return config, err
 			}
+			if output != nil {
+				recordNodegroupUpdate(config, aws.StringValue(ng.NodegroupName), output.Update)
+				nodegroupUpdatesRecorded = nodegroupUpdatesRecorded || output.Update != nil
+			}
 			continue
 		}
 
@@ -1221,7 +1600,7 @@ func (h *Handler) updateUpstreamClusterState(upstreamSpec *eksv1.EKSClusterConfi
 		// if any updates are taking place on nodegroups, the config's phase needs
 		// to be set to "updating" and the controller will wait for the updates to
 		// finish before proceeding
-		if len(templateVersionsToDelete) != 0 || len(templateVersionsToAdd) != 0 {
+		if len(templateVersionsToDelete) != 0 || len(templateVersionsToAdd) != 0 || nodegroupUpdatesRecorded {
 			config = config.DeepCopy()
 			config.Status.TemplateVersionsToDelete = append(config.Status.TemplateVersionsToDelete, utils.ValuesFromMap(templateVersionsToDelete)...)
 			config.Status.ManagedLaunchTemplateVersions = utils.SubtractMaps(config.Status.ManagedLaunchTemplateVersions, templateVersionsToAdd)
@@ -1265,6 +1644,129 @@ func (h *Handler) updateUpstreamClusterState(upstreamSpec *eksv1.EKSClusterConfi
 	return config, nil
 }
 
+// updateUpstreamClusterProperties sends the updates of the cluster itself, as opposed to its node groups, that the
+// upstream cluster needs to match the config. It returns true if an update was sent and must finish before the next
+// one.
+func (h *Handler) updateUpstreamClusterProperties(upstreamSpec *eksv1.EKSClusterConfigSpec, config *eksv1.EKSClusterConfig, awsSVCs *awsServices, clusterState *eks.DescribeClusterOutput, clusterARN string) (*eksv1.EKSClusterConfig, bool, error) {
+	// check kubernetes version for update
+	if config.Spec.KubernetesVersion != nil {
+		var upgrading bool
+		var err error
+		config, upgrading, err = h.upgradeKubernetesVersion(config, upstreamSpec, awsSVCs)
+		if err != nil || upgrading {
+			return config, true, err
+		}
+	}
+
+	// check tags for update
+	if config.Spec.Tags != nil {
+		updated, err := awsservices.UpdateResourceTags(&awsservices.UpdateResourceTagsOpts{
+			EKSService:   awsSVCs.eks,
+			Tags:         config.Spec.Tags,
+			UpstreamTags: upstreamSpec.Tags,
+			ResourceARN:  clusterARN,
+		})
+		if err != nil {
+			return config, false, fmt.Errorf("error updating cluster tags: %w", err)
+		}
+		if updated {
+			config, err = h.enqueueUpdate(config)
+			return config, true, err
+		}
+	}
+
+	if config.Spec.LoggingTypes != nil {
+		// check logging for update
+		update, err := awsservices.UpdateClusterLoggingTypes(&awsservices.UpdateLoggingTypesOpts{
+			EKSService:          awsSVCs.eks,
+			Config:              config,
+			UpstreamClusterSpec: upstreamSpec,
+		})
+		if err != nil {
+			return config, false, fmt.Errorf("error updating logging types: %w", err)
+		}
+		if update != nil {
+			config, err = h.recordClusterUpdate(config, update)
+			return config, true, err
+		}
+	}
+
+	update, err := awsservices.UpdateClusterAccess(&awsservices.UpdateClusterAccessOpts{
+		EKSService:          awsSVCs.eks,
+		Config:              config,
+		UpstreamClusterSpec: upstreamSpec,
+	})
+	if err != nil {
+		return config, false, fmt.Errorf("error updating cluster access config: %w", err)
+	}
+	if update != nil {
+		config, err = h.recordClusterUpdate(config, update)
+		return config, true, err
+	}
+
+	if config.Spec.PublicAccessSources != nil {
+		update, err := awsservices.UpdateClusterPublicAccessSources(&awsservices.UpdateClusterPublicAccessSourcesOpts{
+			EKSService:          awsSVCs.eks,
+			Config:              config,
+			UpstreamClusterSpec: upstreamSpec,
+		})
+		if err != nil {
+			return config, false, fmt.Errorf("error updating cluster public access sources: %w", err)
+		}
+		if update != nil {
+			config, err = h.recordClusterUpdate(config, update)
+			return config, true, err
+		}
+	}
+
+	if len(config.Spec.Subnets) != 0 {
+		update, err := awsservices.UpdateClusterNetwork(&awsservices.UpdateClusterNetworkOpts{
+			EKSService:          awsSVCs.eks,
+			EC2Service:          awsSVCs.ec2,
+			Config:              config,
+			UpstreamClusterSpec: upstreamSpec,
+			VPCID:               aws.StringValue(clusterState.Cluster.ResourcesVpcConfig.VpcId),
+		})
+		if err != nil {
+			return config, false, fmt.Errorf("error updating cluster subnets and security groups: %w", err)
+		}
+		if update != nil {
+			// node groups without their own subnets are created in the subnets of the cluster
+			config = config.DeepCopy()
+			config.Status.Subnets = config.Spec.Subnets
+			if config.Spec.SecurityGroups != nil {
+				config.Status.SecurityGroups = config.Spec.SecurityGroups
+			}
+			config, err = h.recordClusterUpdate(config, update)
+			return config, true, err
+		}
+	}
+
+	if config.Spec.SecretsEncryption != nil {
+		if !aws.BoolValue(upstreamSpec.SecretsEncryption) {
+			var err error
+			config, err = h.generateKMSKey(config, awsSVCs, aws.StringValue(upstreamSpec.ServiceRole))
+			if err != nil {
+				return config, false, fmt.Errorf("error generating kms key: %w", err)
+			}
+		}
+		update, err := awsservices.EnableSecretsEncryption(&awsservices.EnableSecretsEncryptionOpts{
+			EKSService:          awsSVCs.eks,
+			Config:              config,
+			UpstreamClusterSpec: upstreamSpec,
+		})
+		if err != nil {
+			return config, false, fmt.Errorf("error updating secrets encryption: %w", err)
+		}
+		if update != nil {
+			config, err = h.recordClusterUpdate(config, update)
+			return config, true, err
+		}
+	}
+
+	return config, false, nil
+}
+
 // importCluster cluster returns a spec representing the upstream state of the cluster matching to the
 // given config's displayName and region.
 func (h *Handler) importCluster(config *eksv1.EKSClusterConfig, awsSVCs *awsServices) (*eksv1.EKSClusterConfig, error) {
@@ -1280,10 +1782,8 @@ func (h *Handler) importCluster(config *eksv1.EKSClusterConfig, awsSVCs *awsServ
 		return config, err
 	}
 
-	if err := h.createCASecret(config, clusterState); err != nil {
-		if !apierrors.IsAlreadyExists(err) {
-			return config, err
-		}
+	if err := h.createOrUpdateCASecret(config, clusterState, awsSVCs.sts); err != nil {
+		return config, err
 	}
 
 	launchTemplatesOutput, err := awsSVCs.ec2.DescribeLaunchTemplates(&ec2.DescribeLaunchTemplatesInput{
@@ -1295,18 +1795,64 @@ func (h *Handler) importCluster(config *eksv1.EKSClusterConfig, awsSVCs *awsServ
 
 	config.Status.Subnets = aws.StringValueSlice(clusterState.Cluster.ResourcesVpcConfig.SubnetIds)
 	config.Status.SecurityGroups = aws.StringValueSlice(clusterState.Cluster.ResourcesVpcConfig.SecurityGroupIds)
+	config.Status.ClusterSecurityGroupID = aws.StringValue(clusterState.Cluster.ResourcesVpcConfig.ClusterSecurityGroupId)
 	config.Status.Phase = eksConfigActivePhase
 	return h.eksCC.UpdateStatus(config)
 }
 
-// createCASecret creates a secret containing ca and endpoint. These can be used to create a kubeconfig via
-// the go sdk
-func (h *Handler) createCASecret(config *eksv1.EKSClusterConfig, clusterState *eks.DescribeClusterOutput) error {
+// createOrUpdateCASecret creates or updates a secret containing ca, endpoint and a kubeconfig for the cluster. The
+// ca and endpoint can be used to create a kubeconfig via the go sdk. When the kubeconfig authenticates with a token,
+// the token is refreshed before it expires.
+func (h *Handler) createOrUpdateCASecret(config *eksv1.EKSClusterConfig, clusterState *eks.DescribeClusterOutput, stsService services.STSServiceInterface) error {
 	endpoint := aws.StringValue(clusterState.Cluster.Endpoint)
 	ca := aws.StringValue(clusterState.Cluster.CertificateAuthority.Data)
 
-	_, err := h.secrets.Create(
-		&corev1.Secret{
+	existing, err := h.secretsCache.Get(config.Namespace, config.Name)
+	if apierrors.IsNotFound(err) {
+		existing = nil
+	} else if err != nil {
+		return err
+	}
+
+	var token string
+	var tokenExpiration time.Time
+	if aws.StringValue(config.Spec.KubeconfigAuth) == awsservices.KubeconfigAuthToken {
+		token, tokenExpiration = kubeconfigToken(existing, config.Spec.DisplayName)
+		if token == "" || time.Until(tokenExpiration) < tokenRefreshMargin {
+			token, tokenExpiration, err = awsservices.GenerateToken(&awsservices.GenerateTokenOpts{
+				STSService:  stsService,
+				ClusterName: config.Spec.DisplayName,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		h.eksEnqueueAfter(config.Namespace, config.Name, time.Until(tokenExpiration)-tokenRefreshMargin)
+	}
+
+	kubeconfig, err := awsservices.GenerateKubeconfig(&awsservices.GenerateKubeconfigOpts{
+		ClusterName: config.Spec.DisplayName,
+		Region:      config.Spec.Region,
+		Endpoint:    endpoint,
+		CA:          ca,
+		Token:       token,
+	})
+	if err != nil {
+		return err
+	}
+
+	data := map[string][]byte{
+		"endpoint":          []byte(endpoint),
+		"ca":                []byte(ca),
+		kubeconfigSecretKey: kubeconfig,
+	}
+	var expiration string
+	if token != "" {
+		expiration = tokenExpiration.UTC().Format(time.RFC3339)
+	}
+
+	if existing == nil {
+		secret := &corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      config.Name,
 				Namespace: config.Namespace,
@@ -1319,14 +1865,55 @@ func (h *Handler) createCASecret(config *eksv1.EKSClusterConfig, clusterState *e
 					},
 				},
 			},
-			Data: map[string][]byte{
-				"endpoint": []byte(endpoint),
-				"ca":       []byte(ca),
-			},
-		})
+			Data: data,
+		}
+		if expiration != "" {
+			secret.Annotations = map[string]string{tokenExpirationAnnotation: expiration}
+		}
+		_, err = h.secrets.Create(secret)
+		return err
+	}
+
+	if reflect.DeepEqual(existing.Data, data) && existing.Annotations[tokenExpirationAnnotation] == expiration {
+		return nil
+	}
+
+	secret := existing.DeepCopy()
+	secret.Data = data
+	if expiration != "" {
+		if secret.Annotations == nil {
+			secret.Annotations = make(map[string]string)
+		}
+		secret.Annotations[tokenExpirationAnnotation] = expiration
+	} else {
+		delete(secret.Annotations, tokenExpirationAnnotation)
+	}
+	_, err = h.secrets.Update(secret)
 	return err
 }
 
+// kubeconfigToken returns the token of the kubeconfig in the secret of the cluster and when it expires, or an empty
+// token if the kubeconfig does not use one.
+func kubeconfigToken(secret *corev1.Secret, clusterName string) (string, time.Time) {
+	if secret == nil {
+		return "", time.Time{}
+	}
+	expiration, err := time.Parse(time.RFC3339, secret.Annotations[tokenExpirationAnnotation])
+	if err != nil {
+		return "", time.Time{}
+	}
+	kubeconfig, err := clientcmd.Load(secret.Data[kubeconfigSecretKey])
+	if err != nil {
+		return "", time.Time{}
+	}
+	authInfo, ok := kubeconfig.AuthInfos[clusterName]
+	if !ok {
+		return "", time.Time{}
+	}
+
+	return authInfo.Token, expiration
+}
+
 // enqueueUpdate enqueues the config if it is already in the updating phase. Otherwise, the
 // phase is updated to "updating". This is important because the object needs to reenter the
 // onChange handler to start waiting on the update.
@@ -1344,6 +1931,10 @@ func getVPCStackName(name string) string {
 	return name + "-eks-vpc"
 }
 
+func getKMSKeyStackName(name string) string {
+	return name + "-eks-kms-key"
+}
+
 func getEBSCSIDriverRoleStackName(name string) string {
 	return name + "-ebs-csi-driver-role"
 }
@@ -0,0 +1,402 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/golang/mock/gomock"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	awsservices "github.com/rancher/eks-operator/pkg/eks"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+	ekscontrollers "github.com/rancher/eks-operator/pkg/generated/controllers/eks.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeEKSClusterConfigClient records the configs written by the handler, the methods the handler does not use
+// are left to the embedded nil interface.
+type fakeEKSClusterConfigClient struct {
+	ekscontrollers.EKSClusterConfigClient
+	updated       []*eksv1.EKSClusterConfig
+	statusUpdated []*eksv1.EKSClusterConfig
+}
+
+func (c *fakeEKSClusterConfigClient) Update(config *eksv1.EKSClusterConfig) (*eksv1.EKSClusterConfig, error) {
+	c.updated = append(c.updated, config)
+	return config, nil
+}
+
+func (c *fakeEKSClusterConfigClient) UpdateStatus(config *eksv1.EKSClusterConfig) (*eksv1.EKSClusterConfig, error) {
+	c.statusUpdated = append(c.statusUpdated, config)
+	return config, nil
+}
+
+func TestRecordUpstreamSpec(t *testing.T) {
+	asserts := assert.New(t)
+	clusterState := &eks.DescribeClusterOutput{
+		Cluster: &eks.Cluster{
+			Name:            aws.String("test"),
+			Arn:             aws.String("arn:aws:eks:us-west-2:123456789012:cluster/test"),
+			Version:         aws.String("1.27"),
+			PlatformVersion: aws.String("eks.5"),
+			Endpoint:        aws.String("https://test.eks.amazonaws.com"),
+			RoleArn:         aws.String("arn:aws:iam::123456789012:role/test"),
+			Identity: &eks.Identity{
+				Oidc: &eks.OIDC{Issuer: aws.String("https://oidc.eks.amazonaws.com/id/test")},
+			},
+			ResourcesVpcConfig: &eks.VpcConfigResponse{
+				VpcId:                  aws.String("vpc-1"),
+				ClusterSecurityGroupId: aws.String("sg-cluster"),
+				SubnetIds:              aws.StringSlice([]string{"subnet-1", "subnet-2"}),
+				SecurityGroupIds:       aws.StringSlice([]string{"sg-1"}),
+				EndpointPublicAccess:   aws.Bool(true),
+				EndpointPrivateAccess:  aws.Bool(false),
+				PublicAccessCidrs:      aws.StringSlice([]string{"0.0.0.0/0"}),
+			},
+			Logging: &eks.Logging{
+				ClusterLogging: []*eks.LogSetup{
+					{Enabled: aws.Bool(true), Types: aws.StringSlice([]string{eks.LogTypeApi, eks.LogTypeAudit})},
+				},
+			},
+			Tags: aws.StringMap(map[string]string{"team": "test"}),
+			EncryptionConfig: []*eks.EncryptionConfig{
+				{Provider: &eks.Provider{KeyArn: aws.String("arn:aws:kms:us-west-2:123456789012:key/test")}},
+			},
+			KubernetesNetworkConfig: &eks.KubernetesNetworkConfigResponse{
+				IpFamily:        aws.String(eks.IpFamilyIpv4),
+				ServiceIpv4Cidr: aws.String("10.100.0.0/16"),
+			},
+		},
+	}
+	upstreamSpec, _, err := BuildUpstreamClusterState("test", "", clusterState, nil, nil, true)
+	asserts.Nil(err)
+
+	eksCC := &fakeEKSClusterConfigClient{}
+	h := &Handler{eksCC: eksCC}
+	config, err := h.recordUpstreamSpec(&eksv1.EKSClusterConfig{}, upstreamSpec, clusterState)
+	asserts.Nil(err)
+	asserts.Equal(&eksv1.UpstreamSpec{
+		KubernetesVersion:      "1.27",
+		PlatformVersion:        "eks.5",
+		ARN:                    "arn:aws:eks:us-west-2:123456789012:cluster/test",
+		Endpoint:               "https://test.eks.amazonaws.com",
+		OIDCIssuer:             "https://oidc.eks.amazonaws.com/id/test",
+		VPCID:                  "vpc-1",
+		ClusterSecurityGroupID: "sg-cluster",
+		Subnets:                []string{"subnet-1", "subnet-2"},
+		SecurityGroups:         []string{"sg-1"},
+		PublicAccess:           true,
+		PrivateAccess:          false,
+		PublicAccessSources:    []string{"0.0.0.0/0"},
+		LoggingTypes:           []string{eks.LogTypeApi, eks.LogTypeAudit},
+		Tags:                   map[string]string{"team": "test"},
+		SecretsEncryption:      true,
+		KmsKey:                 "arn:aws:kms:us-west-2:123456789012:key/test",
+		ServiceRole:            "arn:aws:iam::123456789012:role/test",
+		KubernetesNetworkConfig: &eksv1.KubernetesNetworkConfig{
+			IPFamily:        aws.String(eks.IpFamilyIpv4),
+			ServiceIpv4Cidr: aws.String("10.100.0.0/16"),
+		},
+	}, config.Status.UpstreamSpec)
+	asserts.Len(eksCC.statusUpdated, 1)
+
+	// recording the same upstream spec again does not update the status
+	_, err = h.recordUpstreamSpec(config, upstreamSpec, clusterState)
+	asserts.Nil(err)
+	asserts.Len(eksCC.statusUpdated, 1)
+}
+
+func TestOnEksConfigRemovedDeletionProtection(t *testing.T) {
+	asserts := assert.New(t)
+	eksCC := &fakeEKSClusterConfigClient{}
+	h := &Handler{eksCC: eksCC}
+	config := &eksv1.EKSClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       eksv1.EKSClusterConfigSpec{DisplayName: "test", DeletionProtection: aws.Bool(true)},
+	}
+
+	// the cluster is not deleted and the credentials are not even read
+	config, err := h.OnEksConfigRemoved("", config)
+	asserts.EqualError(err, "cluster [test] has deletion protection enabled, clear deletionProtection to delete it")
+	asserts.Equal("cluster [test] has deletion protection enabled, clear deletionProtection to delete it", config.Status.FailureMessage)
+	asserts.Len(eksCC.statusUpdated, 1)
+}
+
+func TestDeleteCluster(t *testing.T) {
+	type deleteClusterTestCase struct {
+		name           string
+		deletionPolicy *string
+		// deletedStacks are the CloudFormation stacks expected to be deleted, nil if the cluster is kept
+		deletedStacks []string
+	}
+	testCases := []deleteClusterTestCase{
+		{
+			name:           "delete cluster and all its stacks",
+			deletionPolicy: aws.String(awsservices.DeletionPolicyDelete),
+			deletedStacks:  []string{"test-eks-service-role", "test-eks-vpc", "test-node-instance-role"},
+		},
+		{
+			name:           "retain cluster and all its resources",
+			deletionPolicy: aws.String(awsservices.DeletionPolicyRetain),
+		},
+		{
+			name:           "delete cluster and keep the vpc stack",
+			deletionPolicy: aws.String(awsservices.DeletionPolicyRetainNetwork),
+			deletedStacks:  []string{"test-eks-service-role", "test-node-instance-role"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserts := assert.New(t)
+			mockController := gomock.NewController(t)
+			defer mockController.Finish()
+			cfServiceMock := mock_services.NewMockCloudFormationServiceInterface(mockController)
+			eksServiceMock := mock_services.NewMockEKSServiceInterface(mockController)
+			ec2ServiceMock := mock_services.NewMockEC2ServiceInterface(mockController)
+			awsSVCs := &awsServices{cloudformation: cfServiceMock, eks: eksServiceMock, ec2: ec2ServiceMock}
+			config := &eksv1.EKSClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: eksv1.EKSClusterConfigSpec{
+					DisplayName:    "test",
+					DeletionPolicy: tc.deletionPolicy,
+					NodeGroups:     []eksv1.NodeGroup{{NodegroupName: aws.String("ng1")}},
+					// the subnets of the generated vpc stack can be synced into the spec from the upstream cluster
+					Subnets: []string{"subnet-1", "subnet-2"},
+				},
+				Status: eksv1.EKSClusterConfigStatus{
+					Phase:                   eksConfigActivePhase,
+					ManagedLaunchTemplateID: "lt-1",
+					Subnets:                 []string{"subnet-1", "subnet-2"},
+					NetworkFieldsSource:     "generated",
+				},
+			}
+
+			// mocks without expectations fail the test on any call
+			if tc.deletedStacks != nil {
+				// nodegroups missing from the spec are deleted along with the cluster
+				eksServiceMock.EXPECT().ListNodegroups(&eks.ListNodegroupsInput{ClusterName: aws.String("test")}).Return(
+					&eks.ListNodegroupsOutput{Nodegroups: aws.StringSlice([]string{"ng1", "ng2"})}, nil)
+				for _, ngName := range []string{"ng1", "ng2"} {
+					eksServiceMock.EXPECT().DescribeNodegroup(&eks.DescribeNodegroupInput{
+						ClusterName:   aws.String("test"),
+						NodegroupName: aws.String(ngName),
+					}).Return(nil, awserr.New(eks.ErrCodeResourceNotFoundException, "nodegroup not found", nil))
+				}
+				ec2ServiceMock.EXPECT().DeleteLaunchTemplate(&ec2.DeleteLaunchTemplateInput{
+					LaunchTemplateId: aws.String("lt-1"),
+				}).Return(nil, nil)
+				eksServiceMock.EXPECT().DeleteCluster(&eks.DeleteClusterInput{Name: aws.String("test")}).Return(nil, nil)
+				cfServiceMock.EXPECT().DescribeStacks(gomock.Any()).Return(nil, nil).Times(len(tc.deletedStacks))
+				for _, stackName := range tc.deletedStacks {
+					cfServiceMock.EXPECT().DeleteStack(&cloudformation.DeleteStackInput{
+						StackName: aws.String(stackName),
+					}).Return(nil, nil)
+				}
+			}
+
+			h := &Handler{eksCC: &fakeEKSClusterConfigClient{}}
+			_, err := h.deleteCluster(config, awsSVCs)
+			asserts.Nil(err)
+		})
+	}
+}
+
+func TestDeleteClusterIgnoredNodegroups(t *testing.T) {
+	asserts := assert.New(t)
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	eksServiceMock := mock_services.NewMockEKSServiceInterface(mockController)
+	awsSVCs := &awsServices{eks: eksServiceMock}
+	eksCC := &fakeEKSClusterConfigClient{}
+	h := &Handler{eksCC: eksCC}
+	config := &eksv1.EKSClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: eksv1.EKSClusterConfigSpec{
+			DisplayName:       "test",
+			NodeGroups:        []eksv1.NodeGroup{{NodegroupName: aws.String("ng1")}},
+			NodeGroupPolicies: map[string]string{"ng2": awsservices.NodeGroupPolicyIgnore},
+		},
+		Status: eksv1.EKSClusterConfigStatus{Phase: eksConfigActivePhase},
+	}
+
+	// nothing is deleted while an ignored nodegroup would keep EKS from deleting the cluster
+	eksServiceMock.EXPECT().ListNodegroups(&eks.ListNodegroupsInput{ClusterName: aws.String("test")}).Return(
+		&eks.ListNodegroupsOutput{Nodegroups: aws.StringSlice([]string{"ng1", "ng2"})}, nil)
+	config, err := h.deleteCluster(config, awsSVCs)
+	message := "cluster [test] has nodegroups [ng2] with the ignore policy, delete them or remove them from nodeGroupPolicies to delete the cluster"
+	asserts.EqualError(err, message)
+	asserts.Equal(message, config.Status.FailureMessage)
+	asserts.Len(eksCC.statusUpdated, 1)
+}
+
+func TestValidateKubernetesNetworkConfig(t *testing.T) {
+	type kubernetesNetworkConfigTestCase struct {
+		name          string
+		networkConfig *eksv1.KubernetesNetworkConfig
+		expectedError string
+	}
+	testCases := []kubernetesNetworkConfigTestCase{
+		{
+			name: "no network config",
+		},
+		{
+			name:          "ipv4 with a service cidr",
+			networkConfig: &eksv1.KubernetesNetworkConfig{IPFamily: aws.String(eks.IpFamilyIpv4), ServiceIpv4Cidr: aws.String("10.100.0.0/16")},
+		},
+		{
+			name:          "default ip family with a service cidr",
+			networkConfig: &eksv1.KubernetesNetworkConfig{ServiceIpv4Cidr: aws.String("10.100.0.0/16")},
+		},
+		{
+			name:          "ipv6 without a service cidr",
+			networkConfig: &eksv1.KubernetesNetworkConfig{IPFamily: aws.String(eks.IpFamilyIpv6)},
+		},
+		{
+			name:          "invalid ip family",
+			networkConfig: &eksv1.KubernetesNetworkConfig{IPFamily: aws.String("ipv5")},
+			expectedError: "invalid ipFamily [ipv5] for cluster [test], valid values are [ipv4, ipv6]",
+		},
+		{
+			name:          "invalid service cidr",
+			networkConfig: &eksv1.KubernetesNetworkConfig{IPFamily: aws.String(eks.IpFamilyIpv4), ServiceIpv4Cidr: aws.String("10.100.0.0")},
+			expectedError: "invalid serviceIpv4Cidr [10.100.0.0] for cluster [test]",
+		},
+		{
+			name:          "ipv6 with a service cidr",
+			networkConfig: &eksv1.KubernetesNetworkConfig{IPFamily: aws.String(eks.IpFamilyIpv6), ServiceIpv4Cidr: aws.String("10.100.0.0/16")},
+			expectedError: "serviceIpv4Cidr cannot be set for cluster [test] with ipFamily [ipv6]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &eksv1.EKSClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec:       eksv1.EKSClusterConfigSpec{KubernetesNetworkConfig: tc.networkConfig},
+			}
+			err := validateKubernetesNetworkConfig(config)
+			if tc.expectedError == "" {
+				assert.Nil(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestValidateUpdateNodegroupVersions(t *testing.T) {
+	type nodegroupVersionsTestCase struct {
+		name             string
+		upstreamVersion  string
+		nodegroupVersion string
+		expectedError    bool
+	}
+	testCases := []nodegroupVersionsTestCase{
+		{
+			name:             "nodegroup one minor version behind the cluster",
+			upstreamVersion:  "1.29",
+			nodegroupVersion: "1.28",
+		},
+		{
+			name:             "nodegroup two minor versions behind the cluster",
+			upstreamVersion:  "1.29",
+			nodegroupVersion: "1.27",
+			expectedError:    true,
+		},
+		{
+			name:             "nodegroup pinned during a two minor versions upgrade",
+			upstreamVersion:  "1.27",
+			nodegroupVersion: "1.27",
+		},
+		{
+			name:             "nodegroup pinned after the first step of a two minor versions upgrade",
+			upstreamVersion:  "1.28",
+			nodegroupVersion: "1.27",
+		},
+		{
+			name:             "nodegroup upgraded with the cluster in a two minor versions upgrade",
+			upstreamVersion:  "1.27",
+			nodegroupVersion: "1.29",
+		},
+		{
+			name:             "nodegroup too far behind the upstream version during an upgrade",
+			upstreamVersion:  "1.28",
+			nodegroupVersion: "1.26",
+			expectedError:    true,
+		},
+		{
+			name:             "nodegroup ahead of the cluster",
+			upstreamVersion:  "1.29",
+			nodegroupVersion: "1.30",
+			expectedError:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &eksv1.EKSClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec: eksv1.EKSClusterConfigSpec{
+					KubernetesVersion: aws.String("1.29"),
+					NodeGroups:        []eksv1.NodeGroup{{NodegroupName: aws.String("ng1"), Version: aws.String(tc.nodegroupVersion)}},
+				},
+				Status: eksv1.EKSClusterConfigStatus{
+					UpstreamSpec: &eksv1.UpstreamSpec{KubernetesVersion: tc.upstreamVersion},
+				},
+			}
+			err := ValidateUpdate(config)
+			if !tc.expectedError {
+				assert.Nil(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, "versions for cluster [1.29] and nodegroup ["+tc.nodegroupVersion+"] not compatible")
+		})
+	}
+}
+
+func TestBuildUpstreamClusterStateKubernetesNetworkConfig(t *testing.T) {
+	type upstreamNetworkConfigTestCase struct {
+		name                  string
+		networkConfig         *eks.KubernetesNetworkConfigResponse
+		expectedNetworkConfig *eksv1.KubernetesNetworkConfig
+	}
+	testCases := []upstreamNetworkConfigTestCase{
+		{
+			name: "no network config",
+		},
+		{
+			name:          "ipv4 cluster",
+			networkConfig: &eks.KubernetesNetworkConfigResponse{IpFamily: aws.String(eks.IpFamilyIpv4), ServiceIpv4Cidr: aws.String("10.100.0.0/16")},
+			expectedNetworkConfig: &eksv1.KubernetesNetworkConfig{
+				IPFamily:        aws.String(eks.IpFamilyIpv4),
+				ServiceIpv4Cidr: aws.String("10.100.0.0/16"),
+			},
+		},
+		{
+			name:                  "ipv6 cluster",
+			networkConfig:         &eks.KubernetesNetworkConfigResponse{IpFamily: aws.String(eks.IpFamilyIpv6), ServiceIpv6Cidr: aws.String("fd00::/108")},
+			expectedNetworkConfig: &eksv1.KubernetesNetworkConfig{IPFamily: aws.String(eks.IpFamilyIpv6)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserts := assert.New(t)
+			clusterState := &eks.DescribeClusterOutput{
+				Cluster: &eks.Cluster{
+					Version:                 aws.String("1.27"),
+					ResourcesVpcConfig:      &eks.VpcConfigResponse{},
+					KubernetesNetworkConfig: tc.networkConfig,
+				},
+			}
+			upstreamSpec, _, err := BuildUpstreamClusterState("test", "", clusterState, nil, nil, true)
+			asserts.Nil(err)
+			asserts.Equal(tc.expectedNetworkConfig, upstreamSpec.KubernetesNetworkConfig)
+		})
+	}
+}
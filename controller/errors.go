@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -27,7 +28,8 @@ func doesNotExist(err error) bool {
 }
 
 func notFound(err error) bool {
-	if awsErr, ok := err.(awserr.Error); ok {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
 		return awsErr.Code() == eks.ErrCodeResourceNotFoundException ||
 			strings.Contains(awsErr.Code(), "VersionNotFound")
 	}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	awsservices "github.com/rancher/eks-operator/pkg/eks"
+	"github.com/rancher/wrangler/pkg/condition"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// degradedCondition is true while upstream node groups report health issues.
+	degradedCondition condition.Cond = "Degraded"
+
+	nodegroupHealthIssuesReason = "NodeGroupHealthIssues"
+	// nodegroupRemediationInterval is the minimum time between two remediations of a node group, so that the
+	// health of the node group reflects the last remediation before the next one is applied.
+	nodegroupRemediationInterval = 15 * time.Minute
+)
+
+// recordNodeGroupStatus sets the status of the upstream node groups, as described by EKS, and the degraded
+// condition of the config from the health issues they report. The instance types resolved for node groups of the
+// spec that are not created yet are kept, the updates of node groups that no longer exist upstream are dropped. The
+// status is only updated if it changed.
+func (h *Handler) recordNodeGroupStatus(config *eksv1.EKSClusterConfig, nodeGroupStates []*eks.DescribeNodegroupOutput) (*eksv1.EKSClusterConfig, error) {
+	previousStatuses := make(map[string]eksv1.NodeGroupStatus, len(config.Status.NodeGroups))
+	for _, status := range config.Status.NodeGroups {
+		previousStatuses[status.Name] = status
+	}
+
+	updated := config.DeepCopy()
+	updated.Status.NodeGroups = make([]eksv1.NodeGroupStatus, 0, len(nodeGroupStates))
+	upstreamNodegroups := make(map[string]bool, len(nodeGroupStates))
+	var issues []string
+	for _, ngState := range nodeGroupStates {
+		nodegroupName := aws.StringValue(ngState.Nodegroup.NodegroupName)
+		status := awsservices.NewNodeGroupStatus(ngState.Nodegroup, previousStatuses[nodegroupName])
+		if update, ok := config.Status.NodeGroupUpdates[nodegroupName]; ok {
+			status.LastUpdateID = update.ID
+		}
+		for _, issue := range status.Issues {
+			issues = append(issues, fmt.Sprintf("nodegroup [%s]: %s: %s", status.Name, issue.Code, issue.Message))
+		}
+		updated.Status.NodeGroups = append(updated.Status.NodeGroups, status)
+		delete(previousStatuses, nodegroupName)
+		upstreamNodegroups[nodegroupName] = true
+	}
+	for nodegroupName := range updated.Status.NodeGroupUpdates {
+		if !upstreamNodegroups[nodegroupName] {
+			delete(updated.Status.NodeGroupUpdates, nodegroupName)
+		}
+	}
+	for _, ng := range config.Spec.NodeGroups {
+		previous, ok := previousStatuses[aws.StringValue(ng.NodegroupName)]
+		if !ok || len(previous.ResolvedInstanceTypes) == 0 {
+			continue
+		}
+		updated.Status.NodeGroups = append(updated.Status.NodeGroups, eksv1.NodeGroupStatus{
+			Name:                         previous.Name,
+			ResolvedInstanceRequirements: previous.ResolvedInstanceRequirements,
+			ResolvedInstanceTypes:        previous.ResolvedInstanceTypes,
+			DroppedInstanceTypes:         previous.DroppedInstanceTypes,
+		})
+	}
+
+	if len(issues) != 0 {
+		degradedCondition.True(updated)
+		degradedCondition.Reason(updated, nodegroupHealthIssuesReason)
+		degradedCondition.Message(updated, strings.Join(issues, "; "))
+	} else if degradedCondition.IsTrue(config) {
+		degradedCondition.False(updated)
+		degradedCondition.Reason(updated, "")
+		degradedCondition.Message(updated, "")
+	}
+
+	if reflect.DeepEqual(config.Status, updated.Status) {
+		return config, nil
+	}
+	if len(issues) != 0 && !degradedCondition.IsTrue(config) {
+		logrus.Warnf("nodegroups of cluster [%s] are degraded: %s", config.Name, degradedCondition.GetMessage(updated))
+	}
+
+	return h.eksCC.UpdateStatus(updated)
+}
+
+// remediateNodegroup applies the remediation configured for a node group whose health issues can be fixed by
+// updating it. It returns the node group to compare with its upstream state, using the fallback instance type in
+// effect, and true if the node group must be rolled to a new version of the managed launch template. The
+// remediation is recorded in the status of the config, which must not be shared.
+func remediateNodegroup(config *eksv1.EKSClusterConfig, ng eksv1.NodeGroup) (eksv1.NodeGroup, bool) {
+	status := getNodeGroupStatus(config, aws.StringValue(ng.NodegroupName))
+	if status == nil {
+		return ng, false
+	}
+
+	remediatedNg := awsservices.WithFallbackInstanceType(ng, status.FallbackInstanceType)
+	if ng.Remediation == nil || ng.LaunchTemplate != nil {
+		return remediatedNg, false
+	}
+
+	var issueCodes []string
+	for _, issue := range status.Issues {
+		if NodeGroupIssueIsUpdatable(issue.Code) {
+			issueCodes = append(issueCodes, issue.Code)
+		}
+	}
+	if len(issueCodes) == 0 {
+		return remediatedNg, false
+	}
+	if lastRemediation, err := time.Parse(time.RFC3339, status.LastRemediationTime); err == nil && time.Since(lastRemediation) < nodegroupRemediationInterval {
+		return remediatedNg, false
+	}
+
+	var remediation string
+	var rollLaunchTemplate bool
+	if fallbackInstanceType := awsservices.NextFallbackInstanceType(ng, status.FallbackInstanceType); fallbackInstanceType != "" {
+		status.FallbackInstanceType = fallbackInstanceType
+		remediatedNg.InstanceType = aws.String(fallbackInstanceType)
+		remediation = fmt.Sprintf("switched to fallback instance type [%s]", fallbackInstanceType)
+	} else if aws.BoolValue(ng.Remediation.NewLaunchTemplateVersion) {
+		rollLaunchTemplate = true
+		remediation = "rolled to a new launch template version"
+	} else {
+		return remediatedNg, false
+	}
+
+	status.LastRemediation = fmt.Sprintf("%s because of issues [%s]", remediation, strings.Join(issueCodes, ", "))
+	status.LastRemediationTime = time.Now().UTC().Format(time.RFC3339)
+	logrus.Infof("nodegroup [%s] of cluster [%s] %s", status.Name, config.Name, status.LastRemediation)
+
+	return remediatedNg, rollLaunchTemplate
+}
+
+// getNodeGroupStatus returns the status of the node group with the given name in the status of the config, or nil if
+// it has none.
+func getNodeGroupStatus(config *eksv1.EKSClusterConfig, nodegroupName string) *eksv1.NodeGroupStatus {
+	for i := range config.Status.NodeGroups {
+		if config.Status.NodeGroups[i].Name == nodegroupName {
+			return &config.Status.NodeGroups[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRemediateNodegroup(t *testing.T) {
+	type remediateNodegroupTestCase struct {
+		name                 string
+		remediation          *eksv1.NodeGroupRemediation
+		launchTemplate       *eksv1.LaunchTemplate
+		noStatus             bool
+		issueCodes           []string
+		fallbackInstanceType string
+		lastRemediationTime  time.Time
+		expectedInstanceType string
+		expectedRoll         bool
+		expectedFallback     string
+		// expectedRemediation is the recorded remediation, empty if none is applied
+		expectedRemediation string
+	}
+	fallbacks := &eksv1.NodeGroupRemediation{FallbackInstanceTypes: []string{"t3.large", "m5.large"}}
+	testCases := []remediateNodegroupTestCase{
+		{
+			name:                 "nodegroup without a status",
+			remediation:          fallbacks,
+			noStatus:             true,
+			issueCodes:           []string{eks.NodegroupIssueCodeInstanceLimitExceeded},
+			expectedInstanceType: "t3.medium",
+		},
+		{
+			name:                 "nodegroup without remediation keeps its instance type",
+			issueCodes:           []string{eks.NodegroupIssueCodeInstanceLimitExceeded},
+			fallbackInstanceType: "t3.large",
+			expectedInstanceType: "t3.medium",
+			expectedFallback:     "t3.large",
+		},
+		{
+			name:                 "issues that cannot be fixed by an update are not remediated",
+			remediation:          fallbacks,
+			issueCodes:           []string{eks.NodegroupIssueCodeAccessDenied},
+			fallbackInstanceType: "t3.large",
+			expectedInstanceType: "t3.large",
+			expectedFallback:     "t3.large",
+		},
+		{
+			name:                 "nodegroups with a user provided launch template are not remediated",
+			remediation:          fallbacks,
+			launchTemplate:       &eksv1.LaunchTemplate{ID: aws.String("lt-1")},
+			issueCodes:           []string{eks.NodegroupIssueCodeInstanceLimitExceeded},
+			expectedInstanceType: "t3.medium",
+		},
+		{
+			name:                 "first fallback instance type is switched to",
+			remediation:          fallbacks,
+			issueCodes:           []string{eks.NodegroupIssueCodeInstanceLimitExceeded, eks.NodegroupIssueCodeAccessDenied},
+			expectedInstanceType: "t3.large",
+			expectedFallback:     "t3.large",
+			expectedRemediation:  "switched to fallback instance type [t3.large] because of issues [InstanceLimitExceeded]",
+		},
+		{
+			name:                 "fallback instance types are switched to in order",
+			remediation:          fallbacks,
+			issueCodes:           []string{eks.NodegroupIssueCodeAsgInstanceLaunchFailures},
+			fallbackInstanceType: "t3.large",
+			lastRemediationTime:  time.Now().Add(-nodegroupRemediationInterval - time.Minute),
+			expectedInstanceType: "m5.large",
+			expectedFallback:     "m5.large",
+			expectedRemediation:  "switched to fallback instance type [m5.large] because of issues [AsgInstanceLaunchFailures]",
+		},
+		{
+			name:                 "nodegroup is not remediated again within the remediation interval",
+			remediation:          fallbacks,
+			issueCodes:           []string{eks.NodegroupIssueCodeAsgInstanceLaunchFailures},
+			fallbackInstanceType: "t3.large",
+			lastRemediationTime:  time.Now().Add(-time.Minute),
+			expectedInstanceType: "t3.large",
+			expectedFallback:     "t3.large",
+		},
+		{
+			name: "nodegroup is rolled to a new launch template version when the fallbacks are exhausted",
+			remediation: &eksv1.NodeGroupRemediation{
+				FallbackInstanceTypes:    []string{"t3.large"},
+				NewLaunchTemplateVersion: aws.Bool(true),
+			},
+			issueCodes:           []string{eks.NodegroupIssueCodeClusterUnreachable},
+			fallbackInstanceType: "t3.large",
+			expectedInstanceType: "t3.large",
+			expectedRoll:         true,
+			expectedFallback:     "t3.large",
+			expectedRemediation:  "rolled to a new launch template version because of issues [ClusterUnreachable]",
+		},
+		{
+			name:                 "nodegroup is not remediated when the fallbacks are exhausted",
+			remediation:          fallbacks,
+			issueCodes:           []string{eks.NodegroupIssueCodeInstanceLimitExceeded},
+			fallbackInstanceType: "m5.large",
+			expectedInstanceType: "m5.large",
+			expectedFallback:     "m5.large",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserts := assert.New(t)
+			ng := eksv1.NodeGroup{
+				NodegroupName:  aws.String("ng-1"),
+				InstanceType:   aws.String("t3.medium"),
+				Remediation:    tc.remediation,
+				LaunchTemplate: tc.launchTemplate,
+			}
+			status := eksv1.NodeGroupStatus{
+				Name:                 "ng-1",
+				FallbackInstanceType: tc.fallbackInstanceType,
+			}
+			for _, code := range tc.issueCodes {
+				status.Issues = append(status.Issues, eksv1.NodeGroupIssue{Code: code, Message: "issue"})
+			}
+			if !tc.lastRemediationTime.IsZero() {
+				status.LastRemediationTime = tc.lastRemediationTime.UTC().Format(time.RFC3339)
+			}
+			config := &eksv1.EKSClusterConfig{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+			if !tc.noStatus {
+				config.Status.NodeGroups = []eksv1.NodeGroupStatus{status}
+			}
+
+			remediatedNg, roll := remediateNodegroup(config, ng)
+			asserts.Equal(tc.expectedInstanceType, aws.StringValue(remediatedNg.InstanceType))
+			asserts.Equal(tc.expectedRoll, roll)
+			asserts.Equal("t3.medium", aws.StringValue(ng.InstanceType))
+			if tc.noStatus {
+				asserts.Empty(config.Status.NodeGroups)
+				return
+			}
+
+			recorded := config.Status.NodeGroups[0]
+			asserts.Equal(tc.expectedFallback, recorded.FallbackInstanceType)
+			asserts.Equal(tc.expectedRemediation, recorded.LastRemediation)
+			if tc.expectedRemediation == "" {
+				asserts.Equal(status.LastRemediationTime, recorded.LastRemediationTime)
+				return
+			}
+			lastRemediation, err := time.Parse(time.RFC3339, recorded.LastRemediationTime)
+			asserts.Nil(err)
+			asserts.WithinDuration(time.Now(), lastRemediation, time.Minute)
+		})
+	}
+}
+
+func TestRecordNodeGroupStatusDegradedCondition(t *testing.T) {
+	asserts := assert.New(t)
+	eksCC := &fakeEKSClusterConfigClient{}
+	h := &Handler{eksCC: eksCC}
+	nodegroup := func(issues ...*eks.Issue) []*eks.DescribeNodegroupOutput {
+		return []*eks.DescribeNodegroupOutput{
+			{
+				Nodegroup: &eks.Nodegroup{
+					NodegroupName: aws.String("ng1"),
+					Status:        aws.String(eks.NodegroupStatusActive),
+					Health:        &eks.NodegroupHealth{Issues: issues},
+				},
+			},
+		}
+	}
+	config := &eksv1.EKSClusterConfig{
+		Status: eksv1.EKSClusterConfigStatus{
+			NodeGroups: []eksv1.NodeGroupStatus{{Name: "ng1", Status: eks.NodegroupStatusActive}},
+		},
+	}
+
+	// healthy clusters that were never degraded get no degraded condition
+	config, err := h.recordNodeGroupStatus(config, nodegroup())
+	asserts.Nil(err)
+	asserts.Empty(eksCC.statusUpdated)
+	asserts.Empty(degradedCondition.GetStatus(config))
+
+	config, err = h.recordNodeGroupStatus(config, nodegroup(&eks.Issue{
+		Code:    aws.String(eks.NodegroupIssueCodeAsgInstanceLaunchFailures),
+		Message: aws.String("launch failed"),
+	}))
+	asserts.Nil(err)
+	asserts.True(degradedCondition.IsTrue(config))
+	asserts.Equal(nodegroupHealthIssuesReason, degradedCondition.GetReason(config))
+
+	// degraded clusters that recover get the condition cleared
+	config, err = h.recordNodeGroupStatus(config, nodegroup())
+	asserts.Nil(err)
+	asserts.True(degradedCondition.IsFalse(config))
+	asserts.Empty(degradedCondition.GetMessage(config))
+}
+
+func TestRecordNodeGroupStatusDropsUpdatesOfDeletedNodegroups(t *testing.T) {
+	asserts := assert.New(t)
+	eksCC := &fakeEKSClusterConfigClient{}
+	h := &Handler{eksCC: eksCC}
+	config := &eksv1.EKSClusterConfig{
+		Status: eksv1.EKSClusterConfigStatus{
+			NodeGroups: []eksv1.NodeGroupStatus{{Name: "ng1", Status: eks.NodegroupStatusActive}, {Name: "ng2", Status: eks.NodegroupStatusActive}},
+			NodeGroupUpdates: map[string]eksv1.Update{
+				"ng1": {ID: "update-1", Type: eks.UpdateTypeVersionUpdate, Status: eks.UpdateStatusSuccessful, Generation: 1},
+				"ng2": {ID: "update-2", Type: eks.UpdateTypeConfigUpdate, Status: eks.UpdateStatusFailed, Generation: 1},
+			},
+		},
+	}
+
+	// ng2 was deleted outside of the operator
+	config, err := h.recordNodeGroupStatus(config, []*eks.DescribeNodegroupOutput{
+		{
+			Nodegroup: &eks.Nodegroup{
+				NodegroupName: aws.String("ng1"),
+				Status:        aws.String(eks.NodegroupStatusActive),
+			},
+		},
+	})
+	asserts.Nil(err)
+	asserts.Len(eksCC.statusUpdated, 1)
+	asserts.Equal(map[string]eksv1.Update{
+		"ng1": {ID: "update-1", Type: eks.UpdateTypeVersionUpdate, Status: eks.UpdateStatusSuccessful, Generation: 1},
+	}, config.Status.NodeGroupUpdates)
+	asserts.Len(config.Status.NodeGroups, 1)
+	asserts.Equal("update-1", config.Status.NodeGroups[0].LastUpdateID)
+}
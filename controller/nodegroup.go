@@ -1,6 +1,9 @@
 package controller
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -10,6 +13,7 @@ import (
 	awsservices "github.com/rancher/eks-operator/pkg/eks"
 	"github.com/rancher/eks-operator/pkg/eks/services"
 	"github.com/rancher/eks-operator/utils"
+	"github.com/rancher/wrangler/pkg/condition"
 	"github.com/sirupsen/logrus"
 )
 
@@ -19,8 +23,17 @@ func newLaunchTemplateVersionIfNeeded(config *eksv1.EKSClusterConfig, upstreamNg
 		aws.Int64Value(upstreamNg.DiskSize) != aws.Int64Value(ng.DiskSize) ||
 		aws.StringValue(upstreamNg.ImageID) != aws.StringValue(ng.ImageID) ||
 		(!aws.BoolValue(upstreamNg.RequestSpotInstances) && aws.StringValue(upstreamNg.InstanceType) != aws.StringValue(ng.InstanceType)) ||
-		!utils.CompareStringMaps(aws.StringValueMap(upstreamNg.ResourceTags), aws.StringValueMap(ng.ResourceTags)) {
-		lt, err := awsservices.CreateNewLaunchTemplateVersion(ec2Service, config.Status.ManagedLaunchTemplateID, ng)
+		!utils.CompareStringMaps(aws.StringValueMap(upstreamNg.ResourceTags), aws.StringValueMap(ng.ResourceTags)) ||
+		!awsservices.BlockDeviceMappingsEqual(upstreamNg, ng) ||
+		awsservices.MetadataOptionsNeedUpdate(upstreamNg, ng) ||
+		!awsservices.NetworkConfigEqual(upstreamNg, ng, config.Status.ClusterSecurityGroupID) {
+		if err := awsservices.ValidateRemoteAccess(&awsservices.ValidateRemoteAccessOpts{
+			EC2Service: ec2Service,
+			NodeGroup:  ng,
+		}); err != nil {
+			return nil, fmt.Errorf("%w in cluster [%s]", err, config.Name)
+		}
+		lt, err := awsservices.CreateNewLaunchTemplateVersion(ec2Service, config.Status.ManagedLaunchTemplateID, config.Status.ClusterSecurityGroupID, ng)
 		if err != nil {
 			return nil, err
 		}
@@ -51,6 +64,43 @@ func deleteLaunchTemplate(templateID string, ec2Service services.EC2ServiceInter
 	)
 }
 
+// getNodeGroupsToDelete returns the node groups to delete along with the cluster: the node groups of the spec and
+// the upstream node groups missing from it, such as node groups not adopted yet. Upstream node groups with the
+// ignore policy are never deleted and are returned apart, the cluster cannot be deleted while they exist.
+func getNodeGroupsToDelete(config *eksv1.EKSClusterConfig, eksService services.EKSServiceInterface) ([]eksv1.NodeGroup, []string, error) {
+	nodeGroups := config.Spec.NodeGroups
+	ngs := make(map[string]bool, len(nodeGroups))
+	for _, ng := range nodeGroups {
+		ngs[aws.StringValue(ng.NodegroupName)] = true
+	}
+
+	var ignoredNodeGroups []string
+	input := &eks.ListNodegroupsInput{ClusterName: aws.String(config.Spec.DisplayName)}
+	for {
+		output, err := eksService.ListNodegroups(input)
+		if notFound(err) {
+			return nodeGroups, ignoredNodeGroups, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, name := range aws.StringValueSlice(output.Nodegroups) {
+			if ngs[name] {
+				continue
+			}
+			if config.Spec.NodeGroupPolicies[name] == awsservices.NodeGroupPolicyIgnore {
+				ignoredNodeGroups = append(ignoredNodeGroups, name)
+				continue
+			}
+			nodeGroups = append(nodeGroups, eksv1.NodeGroup{NodegroupName: aws.String(name)})
+		}
+		if aws.StringValue(output.NextToken) == "" {
+			return nodeGroups, ignoredNodeGroups, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
 func deleteNodeGroups(config *eksv1.EKSClusterConfig, nodeGroups []eksv1.NodeGroup, eksService services.EKSServiceInterface) (bool, error) {
 	var waitingForNodegroupDeletion bool
 	for _, ng := range nodeGroups {
@@ -141,5 +191,106 @@ func getNodegroupConfigUpdate(clusterName string, ng eksv1.NodeGroup, upstreamNg
 		}
 	}
 
+	if updateConfig := awsservices.GetUpdateConfigUpdate(upstreamNg, ng); updateConfig != nil {
+		nodegroupConfig.UpdateConfig = updateConfig
+		sendUpdateNodegroupConfig = true
+	}
+
 	return nodegroupConfig, sendUpdateNodegroupConfig
 }
+
+// recordNodegroupUpdate sets the update that was sent to EKS for the node group on the status.
+func recordNodegroupUpdate(config *eksv1.EKSClusterConfig, nodegroupName string, update *eks.Update) {
+	if update == nil {
+		return
+	}
+	if config.Status.NodeGroupUpdates == nil {
+		config.Status.NodeGroupUpdates = make(map[string]eksv1.Update)
+	}
+	config.Status.NodeGroupUpdates[nodegroupName] = awsservices.NewUpdate(update, config.Generation)
+	for i := range config.Status.NodeGroups {
+		if config.Status.NodeGroups[i].Name == nodegroupName {
+			config.Status.NodeGroups[i].LastUpdateID = aws.StringValue(update.Id)
+		}
+	}
+}
+
+const (
+	// nodegroupReplacementRequiredCondition is true while upstream node groups use instance types that do not match
+	// the instance types their instance requirements resolve to.
+	nodegroupReplacementRequiredCondition condition.Cond = "NodeGroupReplacementRequired"
+
+	instanceRequirementsChangedReason = "InstanceRequirementsChanged"
+)
+
+// resolveInstanceRequirements resolves the instance requirements of the node groups that changed since they were
+// last resolved and records the matching instance types in the status of the node groups. EKS cannot change the
+// instance types of a node group, an upstream node group must be replaced to use the instance types its instance
+// requirements now resolve to, which is reported by the replacement required condition. The status is only updated
+// if it changed.
+func (h *Handler) resolveInstanceRequirements(config *eksv1.EKSClusterConfig, upstreamNgs map[string]eksv1.NodeGroup, awsSVCs *awsServices) (*eksv1.EKSClusterConfig, error) {
+	updated := config.DeepCopy()
+	for _, ng := range config.Spec.NodeGroups {
+		if ng.InstanceRequirements == nil || awsservices.InstanceRequirementsResolved(config, ng) {
+			continue
+		}
+		instanceTypes, droppedInstanceTypes, err := awsservices.ResolveInstanceRequirements(&awsservices.ResolveInstanceRequirementsOpts{
+			EC2Service: awsSVCs.ec2,
+			NodeGroup:  ng,
+		})
+		if err != nil {
+			return config, err
+		}
+
+		nodegroupName := aws.StringValue(ng.NodegroupName)
+		var status *eksv1.NodeGroupStatus
+		for i := range updated.Status.NodeGroups {
+			if updated.Status.NodeGroups[i].Name == nodegroupName {
+				status = &updated.Status.NodeGroups[i]
+				break
+			}
+		}
+		if status == nil {
+			updated.Status.NodeGroups = append(updated.Status.NodeGroups, eksv1.NodeGroupStatus{Name: nodegroupName})
+			status = &updated.Status.NodeGroups[len(updated.Status.NodeGroups)-1]
+		}
+		status.ResolvedInstanceRequirements = ng.InstanceRequirements.DeepCopy()
+		status.ResolvedInstanceTypes = instanceTypes
+		status.DroppedInstanceTypes = droppedInstanceTypes
+		logrus.Infof("resolved instance requirements of nodegroup [%s] in cluster [%s] to instance types [%s]",
+			nodegroupName, config.Name, strings.Join(instanceTypes, ", "))
+		if len(droppedInstanceTypes) != 0 {
+			logrus.Infof("instance types [%s] also match the instance requirements of nodegroup [%s] in cluster [%s] but exceed maxInstanceTypes",
+				strings.Join(droppedInstanceTypes, ", "), nodegroupName, config.Name)
+		}
+	}
+
+	var replacements []string
+	for _, status := range updated.Status.NodeGroups {
+		if _, ok := upstreamNgs[status.Name]; !ok || len(status.ResolvedInstanceTypes) == 0 || len(status.InstanceTypes) == 0 {
+			continue
+		}
+		if !utils.CompareStringSliceElements(status.InstanceTypes, status.ResolvedInstanceTypes) {
+			replacements = append(replacements, fmt.Sprintf("nodegroup [%s] uses instance types [%s], it must be replaced to use instance types [%s] matching its instance requirements",
+				status.Name, strings.Join(status.InstanceTypes, ", "), strings.Join(status.ResolvedInstanceTypes, ", ")))
+		}
+	}
+	if len(replacements) != 0 {
+		nodegroupReplacementRequiredCondition.True(updated)
+		nodegroupReplacementRequiredCondition.Reason(updated, instanceRequirementsChangedReason)
+		nodegroupReplacementRequiredCondition.Message(updated, strings.Join(replacements, "; "))
+	} else if nodegroupReplacementRequiredCondition.IsTrue(config) {
+		nodegroupReplacementRequiredCondition.False(updated)
+		nodegroupReplacementRequiredCondition.Reason(updated, "")
+		nodegroupReplacementRequiredCondition.Message(updated, "")
+	}
+
+	if reflect.DeepEqual(config.Status, updated.Status) {
+		return config, nil
+	}
+	if len(replacements) != 0 && !nodegroupReplacementRequiredCondition.IsTrue(config) {
+		logrus.Warnf("nodegroups of cluster [%s] must be replaced: %s", config.Name, nodegroupReplacementRequiredCondition.GetMessage(updated))
+	}
+
+	return h.eksCC.UpdateStatus(updated)
+}
@@ -5,8 +5,11 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/golang/mock/gomock"
 	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -124,6 +127,30 @@ func TestGetNodegroupConfigUpdate(t *testing.T) {
 				}},
 			expectedNgNeedsUpdate: true,
 		},
+		{
+			// test case where update config should be updated
+			clusterName: "testcluster8",
+			ng1:         eksv1.NodeGroup{UpdateConfig: &eksv1.NodeGroupUpdateConfig{MaxUnavailablePercentage: aws.Int64(25)}},
+			ng2:         eksv1.NodeGroup{UpdateConfig: &eksv1.NodeGroupUpdateConfig{MaxUnavailable: aws.Int64(1)}},
+			expectedNgUpdateInput: eks.UpdateNodegroupConfigInput{
+				ClusterName:   aws.String("testcluster8"),
+				ScalingConfig: &eks.NodegroupScalingConfig{},
+				UpdateConfig: &eks.NodegroupUpdateConfig{
+					MaxUnavailablePercentage: aws.Int64(25),
+				}},
+			expectedNgNeedsUpdate: true,
+		},
+		{
+			// test case where update config is not managed
+			clusterName: "testcluster9",
+			ng1:         eksv1.NodeGroup{},
+			ng2:         eksv1.NodeGroup{UpdateConfig: &eksv1.NodeGroupUpdateConfig{MaxUnavailable: aws.Int64(1)}},
+			expectedNgUpdateInput: eks.UpdateNodegroupConfigInput{
+				ClusterName:   aws.String("testcluster9"),
+				ScalingConfig: &eks.NodegroupScalingConfig{},
+			},
+			expectedNgNeedsUpdate: false,
+		},
 	}
 	for _, testCase := range testCases {
 		ngUpdateInput, ngNeedsUpdate := getNodegroupConfigUpdate(testCase.clusterName, testCase.ng1, testCase.ng2)
@@ -136,3 +163,167 @@ func TestGetNodegroupConfigUpdate(t *testing.T) {
 		asserts.Equal(testCase.expectedNgNeedsUpdate, ngNeedsUpdate)
 	}
 }
+
+func TestResolveInstanceRequirementsUntilNodegroupCreated(t *testing.T) {
+	asserts := assert.New(t)
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	ec2ServiceMock := mock_services.NewMockEC2ServiceInterface(mockController)
+	awsSVCs := &awsServices{ec2: ec2ServiceMock}
+	eksCC := &fakeEKSClusterConfigClient{}
+	h := &Handler{eksCC: eksCC}
+
+	config := &eksv1.EKSClusterConfig{
+		Spec: eksv1.EKSClusterConfigSpec{
+			DisplayName: "test",
+			NodeGroups: []eksv1.NodeGroup{
+				{
+					NodegroupName: aws.String("ng1"),
+					InstanceRequirements: &eksv1.InstanceRequirements{
+						VCPUCount: &eksv1.InstanceRequirementsRange{Min: aws.Int64(2)},
+						MemoryMiB: &eksv1.InstanceRequirementsRange{Min: aws.Int64(4096)},
+					},
+				},
+			},
+		},
+	}
+
+	// the instance requirements are resolved once, while the nodegroup creation is blocked
+	ec2ServiceMock.EXPECT().GetInstanceTypesFromInstanceRequirements(gomock.Any()).Return(&ec2.GetInstanceTypesFromInstanceRequirementsOutput{
+		InstanceTypes: []*ec2.InstanceTypeInfoFromInstanceRequirements{
+			{InstanceType: aws.String("m5.large")},
+			{InstanceType: aws.String("m6i.large")},
+		},
+	}, nil).Times(1)
+	ec2ServiceMock.EXPECT().DescribeInstanceTypes(gomock.Any()).Return(&ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []*ec2.InstanceTypeInfo{
+			{InstanceType: aws.String("m5.large"), VCpuInfo: &ec2.VCpuInfo{DefaultVCpus: aws.Int64(2)}},
+			{InstanceType: aws.String("m6i.large"), VCpuInfo: &ec2.VCpuInfo{DefaultVCpus: aws.Int64(2)}},
+		},
+	}, nil).Times(1)
+	for i := 0; i < 2; i++ {
+		var err error
+		config, err = h.recordNodeGroupStatus(config, nil)
+		asserts.Nil(err)
+		config, err = h.resolveInstanceRequirements(config, map[string]eksv1.NodeGroup{}, awsSVCs)
+		asserts.Nil(err)
+		asserts.Len(config.Status.NodeGroups, 1)
+		asserts.Equal([]string{"m5.large", "m6i.large"}, config.Status.NodeGroups[0].ResolvedInstanceTypes)
+	}
+	statusUpdates := len(eksCC.statusUpdated)
+
+	// a third reconcile does not write the status again
+	config, err := h.recordNodeGroupStatus(config, nil)
+	asserts.Nil(err)
+	config, err = h.resolveInstanceRequirements(config, map[string]eksv1.NodeGroup{}, awsSVCs)
+	asserts.Nil(err)
+	asserts.Len(eksCC.statusUpdated, statusUpdates)
+
+	// once created, the nodegroup keeps the resolved instance types along with its upstream status
+	config, err = h.recordNodeGroupStatus(config, []*eks.DescribeNodegroupOutput{
+		{
+			Nodegroup: &eks.Nodegroup{
+				NodegroupName: aws.String("ng1"),
+				Status:        aws.String(eks.NodegroupStatusActive),
+				InstanceTypes: aws.StringSlice([]string{"m5.large", "m6i.large"}),
+			},
+		},
+	})
+	asserts.Nil(err)
+	asserts.Len(config.Status.NodeGroups, 1)
+	asserts.Equal(eks.NodegroupStatusActive, config.Status.NodeGroups[0].Status)
+	asserts.Equal([]string{"m5.large", "m6i.large"}, config.Status.NodeGroups[0].ResolvedInstanceTypes)
+	config, err = h.resolveInstanceRequirements(config, map[string]eksv1.NodeGroup{"ng1": {}}, awsSVCs)
+	asserts.Nil(err)
+	asserts.False(nodegroupReplacementRequiredCondition.IsTrue(config))
+}
+
+func TestResolveInstanceRequirementsOfExistingNodegroup(t *testing.T) {
+	asserts := assert.New(t)
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	ec2ServiceMock := mock_services.NewMockEC2ServiceInterface(mockController)
+	awsSVCs := &awsServices{ec2: ec2ServiceMock}
+	h := &Handler{eksCC: &fakeEKSClusterConfigClient{}}
+
+	requirements := &eksv1.InstanceRequirements{
+		VCPUCount: &eksv1.InstanceRequirementsRange{Min: aws.Int64(4)},
+		MemoryMiB: &eksv1.InstanceRequirementsRange{Min: aws.Int64(8192)},
+	}
+	config := &eksv1.EKSClusterConfig{
+		Spec: eksv1.EKSClusterConfigSpec{
+			DisplayName: "test",
+			NodeGroups:  []eksv1.NodeGroup{{NodegroupName: aws.String("ng1"), InstanceRequirements: requirements}},
+		},
+		Status: eksv1.EKSClusterConfigStatus{
+			NodeGroups: []eksv1.NodeGroupStatus{
+				{
+					Name:          "ng1",
+					InstanceTypes: []string{"m5.large"},
+					ResolvedInstanceRequirements: &eksv1.InstanceRequirements{
+						VCPUCount: &eksv1.InstanceRequirementsRange{Min: aws.Int64(2)},
+						MemoryMiB: &eksv1.InstanceRequirementsRange{Min: aws.Int64(4096)},
+					},
+					ResolvedInstanceTypes: []string{"m5.large"},
+				},
+			},
+		},
+	}
+	upstreamNgs := map[string]eksv1.NodeGroup{"ng1": {NodegroupName: aws.String("ng1")}}
+
+	// changed instance requirements resolve to instance types the existing nodegroup cannot switch to
+	ec2ServiceMock.EXPECT().GetInstanceTypesFromInstanceRequirements(gomock.Any()).Return(&ec2.GetInstanceTypesFromInstanceRequirementsOutput{
+		InstanceTypes: []*ec2.InstanceTypeInfoFromInstanceRequirements{{InstanceType: aws.String("m5.xlarge")}},
+	}, nil)
+	ec2ServiceMock.EXPECT().DescribeInstanceTypes(gomock.Any()).Return(&ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []*ec2.InstanceTypeInfo{{InstanceType: aws.String("m5.xlarge"), VCpuInfo: &ec2.VCpuInfo{DefaultVCpus: aws.Int64(4)}}},
+	}, nil)
+	config, err := h.resolveInstanceRequirements(config, upstreamNgs, awsSVCs)
+	asserts.Nil(err)
+	asserts.True(nodegroupReplacementRequiredCondition.IsTrue(config))
+	asserts.Equal(instanceRequirementsChangedReason, nodegroupReplacementRequiredCondition.GetReason(config))
+	asserts.Equal("nodegroup [ng1] uses instance types [m5.large], it must be replaced to use instance types [m5.xlarge] matching its instance requirements",
+		nodegroupReplacementRequiredCondition.GetMessage(config))
+
+	// the condition is cleared once the nodegroup uses the resolved instance types
+	config.Status.NodeGroups[0].InstanceTypes = []string{"m5.xlarge"}
+	config, err = h.resolveInstanceRequirements(config, upstreamNgs, awsSVCs)
+	asserts.Nil(err)
+	asserts.False(nodegroupReplacementRequiredCondition.IsTrue(config))
+	asserts.Empty(nodegroupReplacementRequiredCondition.GetMessage(config))
+}
+
+func TestNewLaunchTemplateVersionIfNeededMetadataOptions(t *testing.T) {
+	asserts := assert.New(t)
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	ec2ServiceMock := mock_services.NewMockEC2ServiceInterface(mockController)
+
+	config := &eksv1.EKSClusterConfig{
+		Status: eksv1.EKSClusterConfigStatus{ManagedLaunchTemplateID: "lt-1"},
+	}
+	// existing nodegroups were created from launch template versions without metadata options
+	upstreamNg := eksv1.NodeGroup{
+		NodegroupName: aws.String("ng1"),
+		InstanceType:  aws.String("m5.large"),
+		DiskSize:      aws.Int64(20),
+	}
+	ng := *upstreamNg.DeepCopy()
+
+	lt, err := newLaunchTemplateVersionIfNeeded(config, upstreamNg, ng, ec2ServiceMock)
+	asserts.Nil(err)
+	asserts.Nil(lt)
+
+	ng.MetadataOptions = &eksv1.MetadataOptions{}
+	ec2ServiceMock.EXPECT().CreateLaunchTemplateVersion(gomock.Any()).DoAndReturn(
+		func(input *ec2.CreateLaunchTemplateVersionInput) (*ec2.CreateLaunchTemplateVersionOutput, error) {
+			asserts.Equal(ec2.LaunchTemplateHttpTokensStateRequired, aws.StringValue(input.LaunchTemplateData.MetadataOptions.HttpTokens))
+			return &ec2.CreateLaunchTemplateVersionOutput{LaunchTemplateVersion: &ec2.LaunchTemplateVersion{
+				LaunchTemplateId: aws.String("lt-1"),
+				VersionNumber:    aws.Int64(2),
+			}}, nil
+		})
+	lt, err = newLaunchTemplateVersionIfNeeded(config, upstreamNg, ng, ec2ServiceMock)
+	asserts.Nil(err)
+	asserts.Equal(int64(2), aws.Int64Value(lt.Version))
+}
@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/eks"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	awsservices "github.com/rancher/eks-operator/pkg/eks"
+	"github.com/rancher/eks-operator/pkg/eks/services"
+	"github.com/sirupsen/logrus"
+)
+
+// recordDiscoveredResources looks up the stacks the operator creates for a cluster and the roles used by an imported
+// cluster and records them on the status, along with the generation of the config. Only stacks named the way the
+// operator names them are found. Roles created by a discovered stack are not recorded, they are deleted with their
+// stack, other roles are only deleted when set in DeleteDiscoveredRoles.
+func (h *Handler) recordDiscoveredResources(config *eksv1.EKSClusterConfig, clusterState *eks.DescribeClusterOutput, awsSVCs *awsServices) (*eksv1.EKSClusterConfig, error) {
+	discovered := &eksv1.DiscoveredResources{
+		Generation: config.Generation,
+	}
+
+	stackNames := []string{
+		getVPCStackName(config.Spec.DisplayName),
+		getServiceRoleName(config.Spec.DisplayName),
+		fmt.Sprintf("%s-node-instance-role", config.Spec.DisplayName),
+		getEBSCSIDriverRoleStackName(config.Spec.DisplayName),
+		getKMSKeyStackName(config.Spec.DisplayName),
+	}
+	stackOutputs := make(map[string]bool)
+	for _, stackName := range stackNames {
+		output, err := awsSVCs.cloudformation.DescribeStacks(&cloudformation.DescribeStacksInput{
+			StackName: aws.String(stackName),
+		})
+		if doesNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return config, fmt.Errorf("error discovering stack [%s] of cluster [%s]: %w", stackName, config.Name, err)
+		}
+		discovered.Stacks = append(discovered.Stacks, stackName)
+		if output == nil {
+			continue
+		}
+		for _, stack := range output.Stacks {
+			for _, stackOutput := range stack.Outputs {
+				stackOutputs[aws.StringValue(stackOutput.OutputValue)] = true
+			}
+		}
+	}
+
+	if serviceRole := aws.StringValue(clusterState.Cluster.RoleArn); !stackOutputs[serviceRole] {
+		discovered.ServiceRole = serviceRole
+	}
+	nodeRoles, err := getNodeRoles(awsSVCs.eks, config.Spec.DisplayName)
+	if err != nil {
+		return config, fmt.Errorf("error discovering node roles of cluster [%s]: %w", config.Name, err)
+	}
+	for nodeRole := range nodeRoles {
+		if nodeRole != "" && !stackOutputs[nodeRole] {
+			discovered.NodeRoles = append(discovered.NodeRoles, nodeRole)
+		}
+	}
+	sort.Strings(discovered.NodeRoles)
+
+	if reflect.DeepEqual(config.Status.DiscoveredResources, discovered) {
+		return config, nil
+	}
+
+	logrus.Infof("discovered stacks [%v], service role [%s] and node roles [%v] of imported cluster [%s]",
+		discovered.Stacks, discovered.ServiceRole, discovered.NodeRoles, config.Name)
+	config = config.DeepCopy()
+	config.Status.DiscoveredResources = discovered
+	return h.eksCC.UpdateStatus(config)
+}
+
+// deleteDiscoveredStacks deletes the stacks discovered for an imported cluster, keeping the VPC stack if the deletion
+// policy retains the network.
+func deleteDiscoveredStacks(config *eksv1.EKSClusterConfig, cfService services.CloudFormationServiceInterface) error {
+	if config.Status.DiscoveredResources == nil {
+		return nil
+	}
+
+	for _, stackName := range config.Status.DiscoveredResources.Stacks {
+		if stackName == getVPCStackName(config.Spec.DisplayName) && aws.StringValue(config.Spec.DeletionPolicy) == awsservices.DeletionPolicyRetainNetwork {
+			logrus.Infof("cluster [%s] has deletion policy [%s], will not delete stack [%s]", config.Name, awsservices.DeletionPolicyRetainNetwork, stackName)
+			continue
+		}
+		logrus.Infof("deleting stack [%s] for config [%s]", stackName, config.Name)
+		if err := deleteStack(cfService, stackName, stackName); err != nil {
+			return fmt.Errorf("error deleting stack [%s]: %v", stackName, err)
+		}
+	}
+
+	return nil
+}
+
+// deleteDiscoveredRoles deletes the discovered roles of an imported cluster set in DeleteDiscoveredRoles. These are
+// roles created outside the operator, for instance by Terraform, and may be shared, so roles still used by another
+// cluster or node group of the region are kept. The roles of the operator are deleted with the discovered stacks.
+func deleteDiscoveredRoles(config *eksv1.EKSClusterConfig, eksService services.EKSServiceInterface, iamService services.IAMServiceInterface) error {
+	roles := awsservices.GetDiscoveredRolesToDelete(config)
+	if len(roles) == 0 {
+		return nil
+	}
+
+	rolesInUse, err := getRolesInUse(eksService, config.Spec.DisplayName)
+	if err != nil {
+		return fmt.Errorf("error checking roles in use before deleting roles of config [%s]: %w", config.Name, err)
+	}
+	for _, role := range roles {
+		if rolesInUse[role] {
+			logrus.Warnf("role [%s] of config [%s] is used by another cluster or node group, will not delete it", role, config.Name)
+			continue
+		}
+		logrus.Infof("deleting role [%s] for config [%s]", role, config.Name)
+		if err := awsservices.DeleteRole(&awsservices.DeleteRoleOpts{
+			IAMService: iamService,
+			Role:       role,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getRolesInUse returns the service roles of the clusters of the region, other than the given cluster, and the node
+// roles of their node groups. Clusters and node groups deleted while they are listed are skipped.
+func getRolesInUse(eksService services.EKSServiceInterface, clusterName string) (map[string]bool, error) {
+	clusterNames, err := listClusters(eksService)
+	if err != nil {
+		return nil, fmt.Errorf("error listing clusters: %w", err)
+	}
+
+	rolesInUse := make(map[string]bool)
+	for _, name := range clusterNames {
+		if name == clusterName {
+			continue
+		}
+		cluster, err := eksService.DescribeCluster(&eks.DescribeClusterInput{
+			Name: aws.String(name),
+		})
+		if notFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error describing cluster [%s]: %w", name, err)
+		}
+		if cluster.Cluster != nil {
+			rolesInUse[aws.StringValue(cluster.Cluster.RoleArn)] = true
+		}
+
+		nodeRoles, err := getNodeRoles(eksService, name)
+		if err != nil {
+			return nil, err
+		}
+		for nodeRole := range nodeRoles {
+			rolesInUse[nodeRole] = true
+		}
+	}
+
+	return rolesInUse, nil
+}
+
+// getNodeRoles returns the node roles of the node groups of a cluster. Node groups deleted while they are listed are
+// skipped and a cluster that does not exist has no node roles.
+func getNodeRoles(eksService services.EKSServiceInterface, clusterName string) (map[string]bool, error) {
+	nodeRoles := make(map[string]bool)
+	input := &eks.ListNodegroupsInput{ClusterName: aws.String(clusterName)}
+	for {
+		ngs, err := eksService.ListNodegroups(input)
+		if notFound(err) {
+			return nodeRoles, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error listing nodegroups of cluster [%s]: %w", clusterName, err)
+		}
+		for _, ngName := range ngs.Nodegroups {
+			ng, err := eksService.DescribeNodegroup(&eks.DescribeNodegroupInput{
+				ClusterName:   aws.String(clusterName),
+				NodegroupName: ngName,
+			})
+			if notFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error describing nodegroup [%s] of cluster [%s]: %w", aws.StringValue(ngName), clusterName, err)
+			}
+			if ng.Nodegroup != nil {
+				nodeRoles[aws.StringValue(ng.Nodegroup.NodeRole)] = true
+			}
+		}
+		if aws.StringValue(ngs.NextToken) == "" {
+			return nodeRoles, nil
+		}
+		input.NextToken = ngs.NextToken
+	}
+}
@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/golang/mock/gomock"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	awsservices "github.com/rancher/eks-operator/pkg/eks"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecordDiscoveredResources(t *testing.T) {
+	type recordDiscoveredResourcesTestCase struct {
+		name string
+		// stackOutputs are the outputs of the existing stacks by stack name
+		stackOutputs       map[string][]string
+		previous           *eksv1.DiscoveredResources
+		expectedDiscovered *eksv1.DiscoveredResources
+	}
+	testCases := []recordDiscoveredResourcesTestCase{
+		{
+			name: "roles of a cluster created outside the operator",
+			expectedDiscovered: &eksv1.DiscoveredResources{
+				ServiceRole: "arn:aws:iam::123456789012:role/service",
+				NodeRoles:   []string{"arn:aws:iam::123456789012:role/nodes"},
+				Generation:  2,
+			},
+		},
+		{
+			name: "roles created by discovered stacks are left to their stacks",
+			stackOutputs: map[string][]string{
+				"test-eks-vpc":            {"subnet-1,subnet-2"},
+				"test-eks-service-role":   {"arn:aws:iam::123456789012:role/service"},
+				"test-node-instance-role": {"arn:aws:iam::123456789012:role/other-nodes"},
+			},
+			expectedDiscovered: &eksv1.DiscoveredResources{
+				Stacks:     []string{"test-eks-vpc", "test-eks-service-role", "test-node-instance-role"},
+				NodeRoles:  []string{"arn:aws:iam::123456789012:role/nodes"},
+				Generation: 2,
+			},
+		},
+		{
+			name: "unchanged resources are not recorded again",
+			previous: &eksv1.DiscoveredResources{
+				ServiceRole: "arn:aws:iam::123456789012:role/service",
+				NodeRoles:   []string{"arn:aws:iam::123456789012:role/nodes"},
+				Generation:  2,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserts := assert.New(t)
+			mockController := gomock.NewController(t)
+			defer mockController.Finish()
+			cfServiceMock := mock_services.NewMockCloudFormationServiceInterface(mockController)
+			cfServiceMock.EXPECT().DescribeStacks(gomock.Any()).DoAndReturn(
+				func(input *cloudformation.DescribeStacksInput) (*cloudformation.DescribeStacksOutput, error) {
+					outputs, ok := tc.stackOutputs[aws.StringValue(input.StackName)]
+					if !ok {
+						return nil, errors.New("stack does not exist")
+					}
+					stack := &cloudformation.Stack{StackName: input.StackName}
+					for _, output := range outputs {
+						stack.Outputs = append(stack.Outputs, &cloudformation.Output{OutputValue: aws.String(output)})
+					}
+					return &cloudformation.DescribeStacksOutput{Stacks: []*cloudformation.Stack{stack}}, nil
+				}).Times(5)
+
+			nodeRoles := map[string]string{
+				"ng1": "arn:aws:iam::123456789012:role/nodes",
+				"ng2": "arn:aws:iam::123456789012:role/nodes",
+				"ng3": "arn:aws:iam::123456789012:role/other-nodes",
+			}
+			if tc.stackOutputs == nil {
+				delete(nodeRoles, "ng3")
+			}
+			eksServiceMock := mock_services.NewMockEKSServiceInterface(mockController)
+			expectNodeRoles(eksServiceMock, "test", nodeRoles)
+
+			eksCC := &fakeEKSClusterConfigClient{}
+			h := &Handler{eksCC: eksCC}
+			config := &eksv1.EKSClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 2},
+				Spec:       eksv1.EKSClusterConfigSpec{DisplayName: "test", Imported: true},
+				Status:     eksv1.EKSClusterConfigStatus{DiscoveredResources: tc.previous},
+			}
+			clusterState := &eks.DescribeClusterOutput{
+				Cluster: &eks.Cluster{RoleArn: aws.String("arn:aws:iam::123456789012:role/service")},
+			}
+
+			config, err := h.recordDiscoveredResources(config, clusterState, &awsServices{cloudformation: cfServiceMock, eks: eksServiceMock})
+			asserts.Nil(err)
+			if tc.expectedDiscovered == nil {
+				asserts.Empty(eksCC.statusUpdated)
+				asserts.Equal(tc.previous, config.Status.DiscoveredResources)
+				return
+			}
+			asserts.Len(eksCC.statusUpdated, 1)
+			asserts.Equal(tc.expectedDiscovered, config.Status.DiscoveredResources)
+		})
+	}
+}
+
+func TestPromoteImportedClusterWithoutStacks(t *testing.T) {
+	asserts := assert.New(t)
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	cfServiceMock := mock_services.NewMockCloudFormationServiceInterface(mockController)
+	cfServiceMock.EXPECT().DescribeStacks(gomock.Any()).Return(nil, errors.New("stack does not exist")).Times(5)
+	eksServiceMock := mock_services.NewMockEKSServiceInterface(mockController)
+	expectNodeRoles(eksServiceMock, "test", map[string]string{"ng1": "arn:aws:iam::123456789012:role/nodes"})
+
+	// a cluster created by Terraform has no stacks of the operator
+	h := &Handler{eksCC: &fakeEKSClusterConfigClient{}}
+	config := &eksv1.EKSClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 2},
+		Spec: eksv1.EKSClusterConfigSpec{
+			DisplayName: "test",
+			Imported:    true,
+			Ownership:   aws.String(awsservices.OwnershipOwned),
+		},
+		Status: eksv1.EKSClusterConfigStatus{
+			Phase:               eksConfigActivePhase,
+			DiscoveredResources: &eksv1.DiscoveredResources{Generation: 1},
+		},
+	}
+	asserts.NotNil(ValidateUpdate(config))
+
+	config, err := h.recordDiscoveredResources(config, &eks.DescribeClusterOutput{
+		Cluster: &eks.Cluster{RoleArn: aws.String("arn:aws:iam::123456789012:role/service")},
+	}, &awsServices{cloudformation: cfServiceMock, eks: eksServiceMock})
+	asserts.Nil(err)
+	asserts.Empty(config.Status.DiscoveredResources.Stacks)
+	asserts.Nil(ValidateUpdate(config))
+	// the discovered roles are only deleted when set in deleteDiscoveredRoles
+	asserts.Empty(awsservices.GetDiscoveredRolesToDelete(config))
+}
+
+// expectNodeRoles expects the node groups of the cluster to be listed and described once, with the node roles by
+// node group name.
+func expectNodeRoles(eksServiceMock *mock_services.MockEKSServiceInterface, clusterName string, nodeRoles map[string]string) {
+	var names []string
+	for name := range nodeRoles {
+		names = append(names, name)
+	}
+	eksServiceMock.EXPECT().ListNodegroups(&eks.ListNodegroupsInput{ClusterName: aws.String(clusterName)}).Return(
+		&eks.ListNodegroupsOutput{Nodegroups: aws.StringSlice(names)}, nil)
+	eksServiceMock.EXPECT().DescribeNodegroup(gomock.Any()).DoAndReturn(
+		func(input *eks.DescribeNodegroupInput) (*eks.DescribeNodegroupOutput, error) {
+			return &eks.DescribeNodegroupOutput{
+				Nodegroup: &eks.Nodegroup{
+					NodegroupName: input.NodegroupName,
+					NodeRole:      aws.String(nodeRoles[aws.StringValue(input.NodegroupName)]),
+				},
+			}, nil
+		}).Times(len(nodeRoles))
+}
+
+func TestDeleteClusterDiscoveredResources(t *testing.T) {
+	asserts := assert.New(t)
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	cfServiceMock := mock_services.NewMockCloudFormationServiceInterface(mockController)
+	eksServiceMock := mock_services.NewMockEKSServiceInterface(mockController)
+	iamServiceMock := mock_services.NewMockIAMServiceInterface(mockController)
+	awsSVCs := &awsServices{cloudformation: cfServiceMock, eks: eksServiceMock, iam: iamServiceMock}
+	config := &eksv1.EKSClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: eksv1.EKSClusterConfigSpec{
+			DisplayName:    "test",
+			Imported:       true,
+			Ownership:      aws.String(awsservices.OwnershipOwned),
+			DeletionPolicy: aws.String(awsservices.DeletionPolicyRetainNetwork),
+			DeleteDiscoveredRoles: []string{
+				"arn:aws:iam::123456789012:role/service",
+				"arn:aws:iam::123456789012:role/nodes",
+				"arn:aws:iam::123456789012:role/shared",
+			},
+		},
+		Status: eksv1.EKSClusterConfigStatus{
+			Phase: eksConfigActivePhase,
+			DiscoveredResources: &eksv1.DiscoveredResources{
+				Stacks:      []string{"test-eks-vpc", "test-eks-service-role"},
+				ServiceRole: "arn:aws:iam::123456789012:role/service",
+				NodeRoles: []string{
+					"arn:aws:iam::123456789012:role/nodes",
+					"arn:aws:iam::123456789012:role/shared",
+					"arn:aws:iam::123456789012:role/terraform",
+				},
+			},
+		},
+	}
+
+	eksServiceMock.EXPECT().ListNodegroups(&eks.ListNodegroupsInput{ClusterName: aws.String("test")}).Return(&eks.ListNodegroupsOutput{}, nil)
+	eksServiceMock.EXPECT().DeleteCluster(&eks.DeleteClusterInput{Name: aws.String("test")}).Return(nil, nil)
+	// the vpc stack is kept by the deletion policy
+	cfServiceMock.EXPECT().DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String("test-eks-service-role")}).Return(nil, nil)
+	cfServiceMock.EXPECT().DeleteStack(&cloudformation.DeleteStackInput{StackName: aws.String("test-eks-service-role")}).Return(nil, nil)
+	// the shared role is used by a node group of another cluster and the terraform role is not set to be deleted
+	eksServiceMock.EXPECT().ListClusters(&eks.ListClustersInput{}).Return(
+		&eks.ListClustersOutput{Clusters: aws.StringSlice([]string{"test", "other"})}, nil)
+	eksServiceMock.EXPECT().DescribeCluster(&eks.DescribeClusterInput{Name: aws.String("other")}).Return(
+		&eks.DescribeClusterOutput{Cluster: &eks.Cluster{RoleArn: aws.String("arn:aws:iam::123456789012:role/other")}}, nil)
+	eksServiceMock.EXPECT().ListNodegroups(&eks.ListNodegroupsInput{ClusterName: aws.String("other")}).Return(
+		&eks.ListNodegroupsOutput{Nodegroups: aws.StringSlice([]string{"ng"})}, nil)
+	eksServiceMock.EXPECT().DescribeNodegroup(&eks.DescribeNodegroupInput{ClusterName: aws.String("other"), NodegroupName: aws.String("ng")}).Return(
+		&eks.DescribeNodegroupOutput{Nodegroup: &eks.Nodegroup{NodeRole: aws.String("arn:aws:iam::123456789012:role/shared")}}, nil)
+	iamServiceMock.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("service")}).
+		Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "role not found", nil))
+	iamServiceMock.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("nodes")}).Return(&iam.GetRoleOutput{}, nil)
+	iamServiceMock.EXPECT().ListAttachedRolePolicies(gomock.Any()).Return(&iam.ListAttachedRolePoliciesOutput{}, nil)
+	iamServiceMock.EXPECT().ListRolePolicies(gomock.Any()).Return(&iam.ListRolePoliciesOutput{}, nil)
+	iamServiceMock.EXPECT().ListInstanceProfilesForRole(gomock.Any()).Return(&iam.ListInstanceProfilesForRoleOutput{}, nil)
+	iamServiceMock.EXPECT().DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("nodes")}).Return(nil, nil)
+
+	h := &Handler{eksCC: &fakeEKSClusterConfigClient{}}
+	_, err := h.deleteCluster(config, awsSVCs)
+	asserts.Nil(err)
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	awsservices "github.com/rancher/eks-operator/pkg/eks"
+	"github.com/rancher/wrangler/pkg/condition"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// missingRolePoliciesCondition is true while the roles supplied for the cluster or the node groups to create do
+	// not have the AWS managed policies EKS requires. Other policies can grant the same permissions, but without them
+	// the cluster cannot be managed and the nodes cannot join it.
+	missingRolePoliciesCondition condition.Cond = "MissingRolePolicies"
+
+	rolesMissingManagedPoliciesReason = "RolesMissingManagedPolicies"
+)
+
+// checkRolePolicies checks the roles supplied for the cluster and the node groups about to be created and records the
+// AWS managed policies missing from them in the missing role policies condition of the config, which is only updated
+// if it changed. It returns an error if a role cannot be assumed by its service. The service role of imported
+// clusters is not checked.
+func (h *Handler) checkRolePolicies(config *eksv1.EKSClusterConfig, nodegroups []eksv1.NodeGroup, awsSVCs *awsServices) (*eksv1.EKSClusterConfig, error) {
+	checked := config.DeepCopy()
+	checked.Spec.NodeGroups = nodegroups
+	if config.Spec.Imported {
+		checked.Spec.ServiceRole = nil
+	}
+	warnings, err := awsservices.ValidateRoles(&awsservices.ValidateRolesOpts{
+		IAMService: awsSVCs.iam,
+		Config:     checked,
+	})
+	if err != nil {
+		return config, err
+	}
+
+	updated := config.DeepCopy()
+	if len(warnings) != 0 {
+		missingRolePoliciesCondition.True(updated)
+		missingRolePoliciesCondition.Reason(updated, rolesMissingManagedPoliciesReason)
+		missingRolePoliciesCondition.Message(updated, strings.Join(warnings, "; "))
+	} else if missingRolePoliciesCondition.IsTrue(config) {
+		missingRolePoliciesCondition.False(updated)
+		missingRolePoliciesCondition.Reason(updated, "")
+		missingRolePoliciesCondition.Message(updated, "")
+	}
+
+	if reflect.DeepEqual(config.Status, updated.Status) {
+		return config, nil
+	}
+	if len(warnings) != 0 {
+		logrus.Warnf("roles of cluster [%s] are missing policies: %s", config.Name, missingRolePoliciesCondition.GetMessage(updated))
+	}
+
+	return h.eksCC.UpdateStatus(updated)
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/golang/mock/gomock"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckRolePolicies(t *testing.T) {
+	asserts := assert.New(t)
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	iamServiceMock := mock_services.NewMockIAMServiceInterface(mockController)
+	awsSVCs := &awsServices{iam: iamServiceMock}
+	eksCC := &fakeEKSClusterConfigClient{}
+	h := &Handler{eksCC: eksCC}
+
+	trustPolicy := aws.String(url.QueryEscape(`{"Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`))
+	attachedPolicies := func(names ...string) *iam.ListAttachedRolePoliciesOutput {
+		output := &iam.ListAttachedRolePoliciesOutput{}
+		for _, name := range names {
+			output.AttachedPolicies = append(output.AttachedPolicies, &iam.AttachedPolicy{
+				PolicyArn: aws.String("arn:aws:iam::aws:policy/" + name),
+			})
+		}
+		return output
+	}
+	iamServiceMock.EXPECT().GetRole(gomock.Any()).Return(&iam.GetRoleOutput{Role: &iam.Role{AssumeRolePolicyDocument: trustPolicy}}, nil).Times(2)
+	gomock.InOrder(
+		iamServiceMock.EXPECT().ListAttachedRolePolicies(gomock.Any()).Return(attachedPolicies("AmazonEKSWorkerNodePolicy"), nil),
+		iamServiceMock.EXPECT().ListAttachedRolePolicies(gomock.Any()).Return(
+			attachedPolicies("AmazonEKSWorkerNodePolicy", "AmazonEKS_CNI_Policy", "AmazonEC2ContainerRegistryReadOnly"), nil),
+	)
+
+	nodegroups := []eksv1.NodeGroup{{NodegroupName: aws.String("ng1"), NodeRole: aws.String("arn:aws:iam::123456789012:role/nodes")}}
+	config := &eksv1.EKSClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       eksv1.EKSClusterConfigSpec{DisplayName: "test", NodeGroups: nodegroups},
+	}
+
+	// missing policies do not fail the check but are reported on the config
+	config, err := h.checkRolePolicies(config, nodegroups, awsSVCs)
+	asserts.Nil(err)
+	asserts.Len(eksCC.statusUpdated, 1)
+	asserts.True(missingRolePoliciesCondition.IsTrue(config))
+	asserts.Equal(rolesMissingManagedPoliciesReason, missingRolePoliciesCondition.GetReason(config))
+	asserts.Equal("nodeGroups[ng1].nodeRole: role [nodes] does not have the AWS managed policies [AmazonEC2ContainerRegistryReadOnly, "+
+		"AmazonEKS_CNI_Policy], its other policies must grant the same permissions", missingRolePoliciesCondition.GetMessage(config))
+
+	// the condition is cleared once the policies are attached
+	config, err = h.checkRolePolicies(config, nodegroups, awsSVCs)
+	asserts.Nil(err)
+	asserts.Len(eksCC.statusUpdated, 2)
+	asserts.True(missingRolePoliciesCondition.IsFalse(config))
+	asserts.Empty(missingRolePoliciesCondition.GetMessage(config))
+
+	// clusters whose roles were never missing policies get no condition
+	config = &eksv1.EKSClusterConfig{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	config, err = h.checkRolePolicies(config, nil, awsSVCs)
+	asserts.Nil(err)
+	asserts.Len(eksCC.statusUpdated, 2)
+	asserts.Empty(missingRolePoliciesCondition.GetStatus(config))
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/eks"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	awsservices "github.com/rancher/eks-operator/pkg/eks"
+	"github.com/rancher/eks-operator/pkg/eks/services"
+	"github.com/rancher/wrangler/pkg/condition"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// updateFailedCondition is true while updates sent to EKS for the current generation of the config failed.
+	updateFailedCondition condition.Cond = "UpdateFailed"
+
+	updatesFailedReason = "UpdatesFailed"
+)
+
+// checkUpdates refreshes the in-progress updates recorded on the status using DescribeUpdate. It returns true if
+// an update has not finished yet. The updates sent for the current generation of the config that failed are recorded
+// in the update failed condition, they are not retried until the config changes. The updates of node groups that no
+// longer exist upstream are dropped. The status is only updated if it changed.
+func (h *Handler) checkUpdates(config *eksv1.EKSClusterConfig, eksService services.EKSServiceInterface) (*eksv1.EKSClusterConfig, bool, error) {
+	updated := config.DeepCopy()
+
+	if clusterUpdate := updated.Status.ClusterUpdate; clusterUpdate != nil && awsservices.IsUpdateInProgress(*clusterUpdate) {
+		update, err := awsservices.RefreshUpdate(&awsservices.RefreshUpdateOpts{
+			EKSService: eksService,
+			Config:     config,
+			Update:     *clusterUpdate,
+		})
+		if err != nil {
+			return config, false, err
+		}
+		updated.Status.ClusterUpdate = &update
+	}
+
+	for nodegroupName, nodegroupUpdate := range updated.Status.NodeGroupUpdates {
+		if !awsservices.IsUpdateInProgress(nodegroupUpdate) {
+			continue
+		}
+		update, err := awsservices.RefreshUpdate(&awsservices.RefreshUpdateOpts{
+			EKSService:    eksService,
+			Config:        config,
+			NodegroupName: nodegroupName,
+			Update:        nodegroupUpdate,
+		})
+		if notFound(err) {
+			// the node group was deleted outside of the operator
+			delete(updated.Status.NodeGroupUpdates, nodegroupName)
+			continue
+		}
+		if err != nil {
+			return config, false, err
+		}
+		updated.Status.NodeGroupUpdates[nodegroupName] = update
+	}
+
+	var inProgress bool
+	var failures []string
+	checkUpdate := func(update eksv1.Update, nodegroupName string) {
+		inProgress = inProgress || awsservices.IsUpdateInProgress(update)
+		if !updateFailed(update, config.Generation) {
+			return
+		}
+		message := awsservices.UpdateFailureMessage(update)
+		if nodegroupName != "" {
+			message = fmt.Sprintf("nodegroup [%s]: %s", nodegroupName, message)
+		}
+		failures = append(failures, message)
+	}
+	if updated.Status.ClusterUpdate != nil {
+		checkUpdate(*updated.Status.ClusterUpdate, "")
+	}
+	for nodegroupName, update := range updated.Status.NodeGroupUpdates {
+		checkUpdate(update, nodegroupName)
+	}
+
+	if len(failures) != 0 {
+		sort.Strings(failures)
+		updateFailedCondition.True(updated)
+		updateFailedCondition.Reason(updated, updatesFailedReason)
+		updateFailedCondition.Message(updated, strings.Join(failures, "; "))
+	} else if updateFailedCondition.IsTrue(config) {
+		updateFailedCondition.False(updated)
+		updateFailedCondition.Reason(updated, "")
+		updateFailedCondition.Message(updated, "")
+	}
+
+	if reflect.DeepEqual(config.Status, updated.Status) {
+		return config, inProgress, nil
+	}
+	if len(failures) != 0 && !updateFailedCondition.IsTrue(config) {
+		logrus.Warnf("updates of cluster [%s] failed and will not be retried until the config changes: %s",
+			config.Name, updateFailedCondition.GetMessage(updated))
+	}
+
+	config, err := h.eksCC.UpdateStatus(updated)
+	return config, inProgress, err
+}
+
+// updateFailed returns true if the update was sent for the given generation of the config and failed or was
+// cancelled.
+func updateFailed(update eksv1.Update, generation int64) bool {
+	return update.Generation == generation && (update.Status == eks.UpdateStatusFailed || update.Status == eks.UpdateStatusCancelled)
+}
+
+// recordClusterUpdate sets the update that was sent to EKS for the cluster on the status and moves the config to the
+// updating phase.
+func (h *Handler) recordClusterUpdate(config *eksv1.EKSClusterConfig, update *eks.Update) (*eksv1.EKSClusterConfig, error) {
+	config = config.DeepCopy()
+	clusterUpdate := awsservices.NewUpdate(update, config.Generation)
+	config.Status.ClusterUpdate = &clusterUpdate
+	config.Status.Phase = eksConfigUpdatingPhase
+	return h.eksCC.UpdateStatus(config)
+}
@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/golang/mock/gomock"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+	"github.com/rancher/wrangler/pkg/genericcondition"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckUpdates(t *testing.T) {
+	type checkUpdatesTestCase struct {
+		name             string
+		clusterUpdate    *eksv1.Update
+		nodegroupUpdates map[string]eksv1.Update
+		// describedUpdates are the updates returned by DescribeUpdate by update ID
+		describedUpdates   map[string]*eks.Update
+		describeErr        error
+		conditions         []genericcondition.GenericCondition
+		expectedInProgress bool
+		expectedErr        string
+		expectStatusUpdate bool
+		// expectedFailures is the message of the update failed condition, empty if it must not be true
+		expectedFailures string
+		// expectedNodegroupUpdates are the nodegroup updates left on the status, if it is updated
+		expectedNodegroupUpdates []string
+	}
+	testCases := []checkUpdatesTestCase{
+		{
+			name: "no updates",
+		},
+		{
+			name:          "update still in progress",
+			clusterUpdate: &eksv1.Update{ID: "update-1", Type: eks.UpdateTypeVersionUpdate, Status: eks.UpdateStatusInProgress, Generation: 2},
+			describedUpdates: map[string]*eks.Update{
+				"update-1": {Id: aws.String("update-1"), Type: aws.String(eks.UpdateTypeVersionUpdate), Status: aws.String(eks.UpdateStatusInProgress)},
+			},
+			expectedInProgress: true,
+		},
+		{
+			name:          "successful update is recorded",
+			clusterUpdate: &eksv1.Update{ID: "update-1", Type: eks.UpdateTypeVersionUpdate, Status: eks.UpdateStatusInProgress, Generation: 2},
+			describedUpdates: map[string]*eks.Update{
+				"update-1": {Id: aws.String("update-1"), Type: aws.String(eks.UpdateTypeVersionUpdate), Status: aws.String(eks.UpdateStatusSuccessful)},
+			},
+			expectStatusUpdate: true,
+		},
+		{
+			name:          "failed update of the current generation is recorded in a condition",
+			clusterUpdate: &eksv1.Update{ID: "update-1", Type: eks.UpdateTypeVersionUpdate, Status: eks.UpdateStatusInProgress, Generation: 2},
+			describedUpdates: map[string]*eks.Update{
+				"update-1": {
+					Id:     aws.String("update-1"),
+					Type:   aws.String(eks.UpdateTypeVersionUpdate),
+					Status: aws.String(eks.UpdateStatusFailed),
+					Errors: []*eks.ErrorDetail{{
+						ErrorCode:    aws.String(eks.ErrorCodeSubnetNotFound),
+						ErrorMessage: aws.String("subnet not found"),
+						ResourceIds:  aws.StringSlice([]string{"subnet-1"}),
+					}},
+				},
+			},
+			expectStatusUpdate: true,
+			expectedFailures:   "VersionUpdate update [update-1] failed: SubnetNotFound: subnet not found [subnet-1]",
+		},
+		{
+			name:          "failed update of a previous generation is not recorded",
+			clusterUpdate: &eksv1.Update{ID: "update-1", Type: eks.UpdateTypeVersionUpdate, Status: eks.UpdateStatusFailed, Generation: 1},
+		},
+		{
+			name: "failed nodegroup update of the current generation is recorded in a condition",
+			nodegroupUpdates: map[string]eksv1.Update{
+				"ng-1": {ID: "update-1", Type: eks.UpdateTypeConfigUpdate, Status: eks.UpdateStatusCancelled, Generation: 2},
+				"ng-2": {ID: "update-2", Type: eks.UpdateTypeVersionUpdate, Status: eks.UpdateStatusInProgress, Generation: 2},
+			},
+			describedUpdates: map[string]*eks.Update{
+				"update-2": {Id: aws.String("update-2"), Type: aws.String(eks.UpdateTypeVersionUpdate), Status: aws.String(eks.UpdateStatusInProgress)},
+			},
+			expectedInProgress:       true,
+			expectStatusUpdate:       true,
+			expectedFailures:         "nodegroup [ng-1]: ConfigUpdate update [update-1] cancelled",
+			expectedNodegroupUpdates: []string{"ng-1", "ng-2"},
+		},
+		{
+			name:          "update failed condition is cleared once the config changes",
+			clusterUpdate: &eksv1.Update{ID: "update-1", Type: eks.UpdateTypeVersionUpdate, Status: eks.UpdateStatusFailed, Generation: 1},
+			conditions: []genericcondition.GenericCondition{{
+				Type:    string(updateFailedCondition),
+				Status:  "True",
+				Reason:  updatesFailedReason,
+				Message: "VersionUpdate update [update-1] failed",
+			}},
+			expectStatusUpdate: true,
+		},
+		{
+			name: "updates of nodegroups deleted outside of the operator are dropped",
+			nodegroupUpdates: map[string]eksv1.Update{
+				"ng-1": {ID: "update-1", Type: eks.UpdateTypeVersionUpdate, Status: eks.UpdateStatusInProgress, Generation: 2},
+			},
+			describeErr:        awserr.New(eks.ErrCodeResourceNotFoundException, "nodegroup not found", nil),
+			expectStatusUpdate: true,
+		},
+		{
+			name:          "error describing an update",
+			clusterUpdate: &eksv1.Update{ID: "update-1", Type: eks.UpdateTypeVersionUpdate, Status: eks.UpdateStatusInProgress, Generation: 2},
+			describeErr:   errors.New("error describing update"),
+			expectedErr:   "error describing update [update-1] of cluster [test]: error describing update",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserts := assert.New(t)
+			mockController := gomock.NewController(t)
+			defer mockController.Finish()
+			eksServiceMock := mock_services.NewMockEKSServiceInterface(mockController)
+
+			if tc.describeErr != nil {
+				eksServiceMock.EXPECT().DescribeUpdate(gomock.Any()).Return(nil, tc.describeErr)
+			}
+			for id, update := range tc.describedUpdates {
+				input := &eks.DescribeUpdateInput{Name: aws.String("test"), UpdateId: aws.String(id)}
+				for nodegroupName, nodegroupUpdate := range tc.nodegroupUpdates {
+					if nodegroupUpdate.ID == id {
+						input.NodegroupName = aws.String(nodegroupName)
+					}
+				}
+				eksServiceMock.EXPECT().DescribeUpdate(input).Return(&eks.DescribeUpdateOutput{Update: update}, nil)
+			}
+
+			eksCC := &fakeEKSClusterConfigClient{}
+			h := &Handler{eksCC: eksCC}
+			config := &eksv1.EKSClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 2},
+				Spec:       eksv1.EKSClusterConfigSpec{DisplayName: "test"},
+				Status: eksv1.EKSClusterConfigStatus{
+					ClusterUpdate:    tc.clusterUpdate,
+					NodeGroupUpdates: tc.nodegroupUpdates,
+					Conditions:       tc.conditions,
+				},
+			}
+
+			config, inProgress, err := h.checkUpdates(config, eksServiceMock)
+			if tc.expectedErr != "" {
+				asserts.EqualError(err, tc.expectedErr)
+			} else {
+				asserts.Nil(err)
+			}
+			asserts.Equal(tc.expectedInProgress, inProgress)
+			if !tc.expectStatusUpdate {
+				asserts.Empty(eksCC.statusUpdated)
+				return
+			}
+			asserts.Len(eksCC.statusUpdated, 1)
+			if tc.expectedFailures != "" {
+				asserts.True(updateFailedCondition.IsTrue(config))
+				asserts.Equal(updatesFailedReason, updateFailedCondition.GetReason(config))
+				asserts.Equal(tc.expectedFailures, updateFailedCondition.GetMessage(config))
+			} else {
+				asserts.False(updateFailedCondition.IsTrue(config))
+			}
+			nodegroupUpdates := make([]string, 0, len(config.Status.NodeGroupUpdates))
+			for nodegroupName := range config.Status.NodeGroupUpdates {
+				nodegroupUpdates = append(nodegroupUpdates, nodegroupName)
+			}
+			asserts.ElementsMatch(tc.expectedNodegroupUpdates, nodegroupUpdates)
+			if described, ok := tc.describedUpdates["update-1"]; ok && tc.clusterUpdate != nil {
+				asserts.Equal(aws.StringValue(described.Status), config.Status.ClusterUpdate.Status)
+				asserts.Equal(int64(2), config.Status.ClusterUpdate.Generation)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	awsservices "github.com/rancher/eks-operator/pkg/eks"
+	"github.com/sirupsen/logrus"
+)
+
+// upgradeKubernetesVersion moves the upstream cluster one step closer to the kubernetes version of the spec and
+// records the upgrade plan on the status. Before each control plane step, node groups that would fall out of the
+// supported version skew are upgraded to the current control plane version, the installed add-ons are checked for
+// compatibility with the next version and the EKS upgrade insights are checked for blockers. It returns true if the
+// status was updated or an update was started.
+func (h *Handler) upgradeKubernetesVersion(config *eksv1.EKSClusterConfig, upstreamSpec *eksv1.EKSClusterConfigSpec, awsSVCs *awsServices) (*eksv1.EKSClusterConfig, bool, error) {
+	var err error
+	currentVersion := aws.StringValue(upstreamSpec.KubernetesVersion)
+	targetVersion := aws.StringValue(config.Spec.KubernetesVersion)
+
+	if currentVersion == targetVersion {
+		plan := config.Status.UpgradePlan
+		if plan == nil || plan.CurrentVersion == targetVersion {
+			return config, false, nil
+		}
+		logrus.Infof("control plane of cluster [%s] finished upgrading to kubernetes version [%s]", config.Name, targetVersion)
+		config = config.DeepCopy()
+		config.Status.UpgradePlan.CurrentVersion = targetVersion
+		config.Status.UpgradePlan.Message = ""
+		config, err = h.eksCC.UpdateStatus(config)
+		return config, true, err
+	}
+
+	steps, err := awsservices.GetUpgradeSteps(currentVersion, targetVersion)
+	if err != nil {
+		return config, false, fmt.Errorf("error updating cluster version: %w", err)
+	}
+	nextVersion := steps[0]
+
+	plan := eksv1.UpgradePlan{
+		FromVersion:    currentVersion,
+		TargetVersion:  targetVersion,
+		Steps:          steps,
+		CurrentVersion: currentVersion,
+	}
+	if existingPlan := config.Status.UpgradePlan; existingPlan != nil && existingPlan.TargetVersion == targetVersion {
+		plan.FromVersion = existingPlan.FromVersion
+		plan.Steps = existingPlan.Steps
+	}
+
+	// bring along node groups that would be more than the supported skew behind the next control plane version,
+	// including node groups whose spec has no version as they keep running their upstream version
+	ngs := make(map[string]eksv1.NodeGroup, len(config.Spec.NodeGroups))
+	for _, ng := range config.Spec.NodeGroups {
+		ngs[aws.StringValue(ng.NodegroupName)] = ng
+	}
+	statusVersions := make(map[string]string, len(config.Status.NodeGroups))
+	for _, ngStatus := range config.Status.NodeGroups {
+		statusVersions[ngStatus.Name] = ngStatus.Version
+	}
+	for _, upstreamNg := range upstreamSpec.NodeGroups {
+		nodegroupName := aws.StringValue(upstreamNg.NodegroupName)
+		ng, ok := ngs[nodegroupName]
+		if !ok {
+			continue
+		}
+		nodegroupVersion := aws.StringValue(upstreamNg.Version)
+		if nodegroupVersion == "" {
+			nodegroupVersion = statusVersions[nodegroupName]
+		}
+		if nodegroupVersion == "" {
+			continue
+		}
+		withinSkew, err := awsservices.IsWithinVersionSkew(nodegroupVersion, nextVersion)
+		if err != nil {
+			return config, false, fmt.Errorf("error checking version of nodegroup [%s]: %w", nodegroupName, err)
+		}
+		if withinSkew {
+			continue
+		}
+
+		logrus.Infof("upgrading nodegroup [%s] of cluster [%s] to [%s] before upgrading the control plane to [%s]",
+			nodegroupName, config.Name, currentVersion, nextVersion)
+		update, err := awsservices.UpdateNodegroupVersion(&awsservices.UpdateNodegroupVersionOpts{
+			EKSService: awsSVCs.eks,
+			EC2Service: awsSVCs.ec2,
+			Config:     config,
+			NodeGroup:  &ng,
+			NGVersionInput: &eks.UpdateNodegroupVersionInput{
+				ClusterName:   aws.String(config.Spec.DisplayName),
+				NodegroupName: aws.String(nodegroupName),
+				Version:       aws.String(currentVersion),
+				Force:         aws.Bool(awsservices.IsForceUpgrade(ng)),
+			},
+		})
+		if err != nil {
+			return config, false, fmt.Errorf("error upgrading nodegroup [%s] to [%s]: %w", nodegroupName, currentVersion, err)
+		}
+
+		plan.Message = fmt.Sprintf("upgrading nodegroup [%s] to [%s] before upgrading the control plane to [%s]", nodegroupName, currentVersion, nextVersion)
+		config = config.DeepCopy()
+		recordNodegroupUpdate(config, nodegroupName, update)
+		config.Status.UpgradePlan = &plan
+		config.Status.Phase = eksConfigUpdatingPhase
+		config, err = h.eksCC.UpdateStatus(config)
+		return config, true, err
+	}
+
+	incompatibleAddons, err := awsservices.GetIncompatibleAddons(&awsservices.GetIncompatibleAddonsOpts{
+		EKSService:        awsSVCs.eks,
+		Config:            config,
+		KubernetesVersion: nextVersion,
+	})
+	if err != nil {
+		return config, false, err
+	}
+	if len(incompatibleAddons) != 0 {
+		plan.Message = fmt.Sprintf("add-ons [%s] are not compatible with kubernetes version [%s] and must be updated first",
+			strings.Join(incompatibleAddons, ", "), nextVersion)
+		return h.blockUpgrade(config, plan, nextVersion)
+	}
+
+	insights, blocked, err := awsservices.CheckUpgradeInsights(&awsservices.CheckUpgradeInsightsOpts{
+		EKSService:        awsSVCs.eks,
+		Config:            config,
+		KubernetesVersion: nextVersion,
+	})
+	if err != nil {
+		return config, false, err
+	}
+	plan.Insights = insights
+	if blocked {
+		plan.Message = fmt.Sprintf("upgrade insights report errors for kubernetes version [%s], resolve them or set annotation [%s=%s] to upgrade anyway",
+			nextVersion, awsservices.UpgradeInsightsAcknowledgedAnnotation, nextVersion)
+		return h.blockUpgrade(config, plan, nextVersion)
+	}
+	for _, insight := range insights {
+		logrus.Warnf("upgrading cluster [%s] to kubernetes version [%s] despite upgrade insight [%s] with status [%s]: %s",
+			config.Name, nextVersion, insight.Name, insight.Status, insight.Reason)
+	}
+
+	update, err := awsservices.UpdateClusterVersion(&awsservices.UpdateClusterVersionOpts{
+		EKSService:          awsSVCs.eks,
+		Config:              config,
+		UpstreamClusterSpec: upstreamSpec,
+	})
+	if err != nil {
+		return config, false, fmt.Errorf("error updating cluster version: %w", err)
+	}
+	if update == nil {
+		return config, false, nil
+	}
+
+	plan.Message = fmt.Sprintf("upgrading the control plane to [%s]", nextVersion)
+	config = config.DeepCopy()
+	config.Status.UpgradePlan = &plan
+	config, err = h.recordClusterUpdate(config, update)
+	return config, true, err
+}
+
+// blockUpgrade records the upgrade plan with the reason the next step cannot be taken and returns it as an error.
+func (h *Handler) blockUpgrade(config *eksv1.EKSClusterConfig, plan eksv1.UpgradePlan, nextVersion string) (*eksv1.EKSClusterConfig, bool, error) {
+	if !reflect.DeepEqual(config.Status.UpgradePlan, &plan) {
+		config = config.DeepCopy()
+		config.Status.UpgradePlan = &plan
+		var err error
+		config, err = h.eksCC.UpdateStatus(config)
+		if err != nil {
+			return config, false, err
+		}
+	}
+
+	return config, false, fmt.Errorf("cannot upgrade cluster [%s] to kubernetes version [%s]: %s", config.Name, nextVersion, plan.Message)
+}
@@ -0,0 +1,260 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/golang/mock/gomock"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	awsservices "github.com/rancher/eks-operator/pkg/eks"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpgradeKubernetesVersion(t *testing.T) {
+	type upgradeKubernetesVersionTestCase struct {
+		name                     string
+		upstreamVersion          string
+		targetVersion            string
+		upstreamNodegroupVersion string
+		// unversionedNodegroup leaves the version of the nodegroup unset in the spec
+		unversionedNodegroup   bool
+		statusNodegroupVersion string
+		upgradePlan            *eksv1.UpgradePlan
+		annotations            map[string]string
+		mockCalls              func(eksServiceMock *mock_services.MockEKSServiceInterface)
+		expectedChanged        bool
+		expectedErr            string
+		expectedPlan           *eksv1.UpgradePlan
+		expectedClusterUpdate  string
+		expectedNgUpdate       string
+	}
+
+	noAddons := func(eksServiceMock *mock_services.MockEKSServiceInterface) {
+		eksServiceMock.EXPECT().ListAddons(&eks.ListAddonsInput{ClusterName: aws.String("test")}).Return(&eks.ListAddonsOutput{}, nil)
+	}
+	insights := func(eksServiceMock *mock_services.MockEKSServiceInterface, status string) {
+		eksServiceMock.EXPECT().ListInsights(gomock.Any()).Return(&eks.ListInsightsOutput{
+			Insights: []*eks.InsightSummary{{
+				Id:            aws.String("insight-1"),
+				Name:          aws.String("Deprecated APIs"),
+				InsightStatus: &eks.InsightStatus{Status: aws.String(status), Reason: aws.String("deprecated APIs in use")},
+			}},
+		}, nil)
+		eksServiceMock.EXPECT().DescribeInsight(&eks.DescribeInsightInput{ClusterName: aws.String("test"), Id: aws.String("insight-1")}).
+			Return(&eks.DescribeInsightOutput{Insight: &eks.Insight{Recommendation: aws.String("update the manifests")}}, nil)
+	}
+	insight := eksv1.UpgradeInsight{
+		ID:             "insight-1",
+		Name:           "Deprecated APIs",
+		Status:         eks.InsightStatusValueError,
+		Reason:         "deprecated APIs in use",
+		Recommendation: "update the manifests",
+	}
+
+	testCases := []upgradeKubernetesVersionTestCase{
+		{
+			name:                     "no upgrade",
+			upstreamVersion:          "1.27",
+			targetVersion:            "1.27",
+			upstreamNodegroupVersion: "1.27",
+		},
+		{
+			name:                     "finished upgrade is recorded",
+			upstreamVersion:          "1.27",
+			targetVersion:            "1.27",
+			upstreamNodegroupVersion: "1.26",
+			upgradePlan:              &eksv1.UpgradePlan{FromVersion: "1.26", TargetVersion: "1.27", Steps: []string{"1.27"}, CurrentVersion: "1.26", Message: "upgrading the control plane to [1.27]"},
+			expectedChanged:          true,
+			expectedPlan:             &eksv1.UpgradePlan{FromVersion: "1.26", TargetVersion: "1.27", Steps: []string{"1.27"}, CurrentVersion: "1.27"},
+		},
+		{
+			name:                     "nodegroups outside the version skew are upgraded first",
+			upstreamVersion:          "1.26",
+			targetVersion:            "1.28",
+			upstreamNodegroupVersion: "1.25",
+			mockCalls: func(eksServiceMock *mock_services.MockEKSServiceInterface) {
+				eksServiceMock.EXPECT().UpdateNodegroupVersion(&eks.UpdateNodegroupVersionInput{
+					ClusterName:   aws.String("test"),
+					NodegroupName: aws.String("ng-1"),
+					Version:       aws.String("1.26"),
+					Force:         aws.Bool(false),
+				}).Return(&eks.UpdateNodegroupVersionOutput{
+					Update: &eks.Update{Id: aws.String("update-ng"), Type: aws.String(eks.UpdateTypeVersionUpdate), Status: aws.String(eks.UpdateStatusInProgress)},
+				}, nil)
+			},
+			expectedChanged: true,
+			expectedPlan: &eksv1.UpgradePlan{
+				FromVersion:    "1.26",
+				TargetVersion:  "1.28",
+				Steps:          []string{"1.27", "1.28"},
+				CurrentVersion: "1.26",
+				Message:        "upgrading nodegroup [ng-1] to [1.26] before upgrading the control plane to [1.27]",
+			},
+			expectedNgUpdate: "update-ng",
+		},
+		{
+			name:                   "nodegroups without a version in the spec are upgraded first using their status version",
+			upstreamVersion:        "1.26",
+			targetVersion:          "1.28",
+			unversionedNodegroup:   true,
+			statusNodegroupVersion: "1.25",
+			mockCalls: func(eksServiceMock *mock_services.MockEKSServiceInterface) {
+				eksServiceMock.EXPECT().UpdateNodegroupVersion(&eks.UpdateNodegroupVersionInput{
+					ClusterName:   aws.String("test"),
+					NodegroupName: aws.String("ng-1"),
+					Version:       aws.String("1.26"),
+					Force:         aws.Bool(false),
+				}).Return(&eks.UpdateNodegroupVersionOutput{
+					Update: &eks.Update{Id: aws.String("update-ng"), Type: aws.String(eks.UpdateTypeVersionUpdate), Status: aws.String(eks.UpdateStatusInProgress)},
+				}, nil)
+			},
+			expectedChanged: true,
+			expectedPlan: &eksv1.UpgradePlan{
+				FromVersion:    "1.26",
+				TargetVersion:  "1.28",
+				Steps:          []string{"1.27", "1.28"},
+				CurrentVersion: "1.26",
+				Message:        "upgrading nodegroup [ng-1] to [1.26] before upgrading the control plane to [1.27]",
+			},
+			expectedNgUpdate: "update-ng",
+		},
+		{
+			name:                     "incompatible add-ons block the upgrade",
+			upstreamVersion:          "1.26",
+			targetVersion:            "1.27",
+			upstreamNodegroupVersion: "1.26",
+			mockCalls: func(eksServiceMock *mock_services.MockEKSServiceInterface) {
+				eksServiceMock.EXPECT().ListAddons(&eks.ListAddonsInput{ClusterName: aws.String("test")}).
+					Return(&eks.ListAddonsOutput{Addons: aws.StringSlice([]string{"vpc-cni"})}, nil)
+				eksServiceMock.EXPECT().DescribeAddon(&eks.DescribeAddonInput{AddonName: aws.String("vpc-cni"), ClusterName: aws.String("test")}).
+					Return(&eks.DescribeAddonOutput{Addon: &eks.Addon{AddonVersion: aws.String("v1.10.0")}}, nil)
+				eksServiceMock.EXPECT().DescribeAddonVersions(&eks.DescribeAddonVersionsInput{AddonName: aws.String("vpc-cni"), KubernetesVersion: aws.String("1.27")}).
+					Return(&eks.DescribeAddonVersionsOutput{
+						Addons: []*eks.AddonInfo{{AddonVersions: []*eks.AddonVersionInfo{{AddonVersion: aws.String("v1.12.0")}}}},
+					}, nil)
+			},
+			expectedErr: "cannot upgrade cluster [test] to kubernetes version [1.27]: add-ons [vpc-cni v1.10.0] are not compatible with kubernetes version [1.27] and must be updated first",
+			expectedPlan: &eksv1.UpgradePlan{
+				FromVersion:    "1.26",
+				TargetVersion:  "1.27",
+				Steps:          []string{"1.27"},
+				CurrentVersion: "1.26",
+				Message:        "add-ons [vpc-cni v1.10.0] are not compatible with kubernetes version [1.27] and must be updated first",
+			},
+		},
+		{
+			name:                     "upgrade insights with errors block the upgrade",
+			upstreamVersion:          "1.26",
+			targetVersion:            "1.27",
+			upstreamNodegroupVersion: "1.26",
+			mockCalls: func(eksServiceMock *mock_services.MockEKSServiceInterface) {
+				noAddons(eksServiceMock)
+				insights(eksServiceMock, eks.InsightStatusValueError)
+			},
+			expectedErr: "cannot upgrade cluster [test] to kubernetes version [1.27]: upgrade insights report errors for kubernetes version [1.27], " +
+				"resolve them or set annotation [" + awsservices.UpgradeInsightsAcknowledgedAnnotation + "=1.27] to upgrade anyway",
+			expectedPlan: &eksv1.UpgradePlan{
+				FromVersion:    "1.26",
+				TargetVersion:  "1.27",
+				Steps:          []string{"1.27"},
+				CurrentVersion: "1.26",
+				Message: "upgrade insights report errors for kubernetes version [1.27], " +
+					"resolve them or set annotation [" + awsservices.UpgradeInsightsAcknowledgedAnnotation + "=1.27] to upgrade anyway",
+				Insights: []eksv1.UpgradeInsight{insight},
+			},
+		},
+		{
+			name:                     "acknowledged upgrade insights do not block the upgrade",
+			upstreamVersion:          "1.26",
+			targetVersion:            "1.27",
+			upstreamNodegroupVersion: "1.26",
+			annotations:              map[string]string{awsservices.UpgradeInsightsAcknowledgedAnnotation: "1.27"},
+			mockCalls: func(eksServiceMock *mock_services.MockEKSServiceInterface) {
+				noAddons(eksServiceMock)
+				insights(eksServiceMock, eks.InsightStatusValueError)
+				eksServiceMock.EXPECT().UpdateClusterVersion(&eks.UpdateClusterVersionInput{Name: aws.String("test"), Version: aws.String("1.27")}).
+					Return(&eks.UpdateClusterVersionOutput{
+						Update: &eks.Update{Id: aws.String("update-1"), Type: aws.String(eks.UpdateTypeVersionUpdate), Status: aws.String(eks.UpdateStatusInProgress)},
+					}, nil)
+			},
+			expectedChanged: true,
+			expectedPlan: &eksv1.UpgradePlan{
+				FromVersion:    "1.26",
+				TargetVersion:  "1.27",
+				Steps:          []string{"1.27"},
+				CurrentVersion: "1.26",
+				Message:        "upgrading the control plane to [1.27]",
+				Insights:       []eksv1.UpgradeInsight{insight},
+			},
+			expectedClusterUpdate: "update-1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			asserts := assert.New(t)
+			mockController := gomock.NewController(t)
+			defer mockController.Finish()
+			eksServiceMock := mock_services.NewMockEKSServiceInterface(mockController)
+			if tc.mockCalls != nil {
+				tc.mockCalls(eksServiceMock)
+			}
+
+			eksCC := &fakeEKSClusterConfigClient{}
+			h := &Handler{eksCC: eksCC}
+			config := &eksv1.EKSClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Annotations: tc.annotations},
+				Spec: eksv1.EKSClusterConfigSpec{
+					DisplayName:       "test",
+					KubernetesVersion: aws.String(tc.targetVersion),
+					NodeGroups: []eksv1.NodeGroup{{
+						NodegroupName: aws.String("ng-1"),
+						Version:       aws.String(tc.targetVersion),
+					}},
+				},
+				Status: eksv1.EKSClusterConfigStatus{
+					UpgradePlan: tc.upgradePlan,
+					NodeGroups:  []eksv1.NodeGroupStatus{{Name: "ng-1", Version: tc.statusNodegroupVersion}},
+				},
+			}
+			if tc.unversionedNodegroup {
+				config.Spec.NodeGroups[0].Version = nil
+			}
+			upstreamSpec := &eksv1.EKSClusterConfigSpec{
+				KubernetesVersion: aws.String(tc.upstreamVersion),
+				NodeGroups: []eksv1.NodeGroup{{
+					NodegroupName: aws.String("ng-1"),
+					Version:       aws.String(tc.upstreamNodegroupVersion),
+				}},
+			}
+
+			config, changed, err := h.upgradeKubernetesVersion(config, upstreamSpec, &awsServices{eks: eksServiceMock})
+			if tc.expectedErr != "" {
+				asserts.EqualError(err, tc.expectedErr)
+			} else {
+				asserts.Nil(err)
+			}
+			asserts.Equal(tc.expectedChanged, changed)
+			asserts.Equal(tc.expectedPlan, config.Status.UpgradePlan)
+			if tc.expectedPlan != tc.upgradePlan {
+				asserts.Len(eksCC.statusUpdated, 1)
+			} else {
+				asserts.Empty(eksCC.statusUpdated)
+			}
+			if tc.expectedClusterUpdate != "" {
+				asserts.Equal(tc.expectedClusterUpdate, config.Status.ClusterUpdate.ID)
+			} else {
+				asserts.Nil(config.Status.ClusterUpdate)
+			}
+			if tc.expectedNgUpdate != "" {
+				asserts.Equal(tc.expectedNgUpdate, config.Status.NodeGroupUpdates["ng-1"].ID)
+				asserts.Equal(eksConfigUpdatingPhase, config.Status.Phase)
+			} else {
+				asserts.Empty(config.Status.NodeGroupUpdates)
+			}
+		})
+	}
+}
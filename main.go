@@ -31,6 +31,13 @@ func main() {
 	// set up signals so we handle the first shutdown signal gracefully
 	ctx := signals.SetupSignalContext()
 
+	if args := flag.Args(); len(args) != 0 {
+		if err := runCommand(ctx, args); err != nil {
+			logrus.Fatal(err)
+		}
+		return
+	}
+
 	// This will load the kubeconfig file in a style the same as kubectl
 	cfg, err := kubeconfig.GetNonInteractiveClientConfig(kubeconfigFile).ClientConfig()
 	if err != nil {
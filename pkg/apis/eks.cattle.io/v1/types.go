@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	"github.com/rancher/wrangler/pkg/genericcondition"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -39,17 +40,32 @@ type EKSClusterConfigSpec struct {
 	Imported               bool              `json:"imported" norman:"noupdate"`
 	KubernetesVersion      *string           `json:"kubernetesVersion" norman:"pointer"`
 	Tags                   map[string]string `json:"tags"`
-	SecretsEncryption      *bool             `json:"secretsEncryption" norman:"noupdate"`
-	KmsKey                 *string           `json:"kmsKey" norman:"noupdate,pointer"`
+	SecretsEncryption      *bool             `json:"secretsEncryption"`
+	KmsKey                 *string           `json:"kmsKey" norman:"pointer"`
 	PublicAccess           *bool             `json:"publicAccess"`
 	PrivateAccess          *bool             `json:"privateAccess"`
 	EBSCSIDriver           *bool             `json:"ebsCSIDriver"`
 	PublicAccessSources    []string          `json:"publicAccessSources"`
 	LoggingTypes           []string          `json:"loggingTypes"`
-	Subnets                []string          `json:"subnets" norman:"noupdate"`
-	SecurityGroups         []string          `json:"securityGroups" norman:"noupdate"`
+	Subnets                []string          `json:"subnets"`
+	SecurityGroups         []string          `json:"securityGroups"`
 	ServiceRole            *string           `json:"serviceRole" norman:"noupdate,pointer"`
 	NodeGroups             []NodeGroup       `json:"nodeGroups"`
+	// KubernetesNetworkConfig can only be set on create
+	KubernetesNetworkConfig *KubernetesNetworkConfig `json:"kubernetesNetworkConfig" norman:"noupdate"`
+	// KubeconfigAuth is exec or token
+	KubeconfigAuth *string `json:"kubeconfigAuth" norman:"pointer"`
+	// NodeGroupPolicies is adopt or ignore by node group name
+	NodeGroupPolicies map[string]string `json:"nodeGroupPolicies"`
+	// DeletionPolicy is Delete, Retain or RetainNetwork
+	DeletionPolicy     *string `json:"deletionPolicy" norman:"pointer"`
+	DeletionProtection *bool   `json:"deletionProtection"`
+	// Ownership is ObserveOnly, Managed or Owned
+	Ownership *string `json:"ownership" norman:"pointer"`
+	// DeleteDiscoveredRoles are the ARNs of the discovered roles deleted with a promoted imported cluster
+	DeleteDiscoveredRoles []string `json:"deleteDiscoveredRoles"`
+	// KmsKeyDeletionWindow is in days, between 7 and 30
+	KmsKeyDeletionWindow *int64 `json:"kmsKeyDeletionWindow"`
 }
 
 type EKSClusterConfigStatus struct {
@@ -61,9 +77,114 @@ type EKSClusterConfigStatus struct {
 	ManagedLaunchTemplateVersions map[string]string `json:"managedLaunchTemplateVersions"`
 	TemplateVersionsToDelete      []string          `json:"templateVersionsToDelete"`
 	// describes how the above network fields were provided. Valid values are provided and generated
-	NetworkFieldsSource string `json:"networkFieldsSource"`
-	FailureMessage      string `json:"failureMessage"`
-	GeneratedNodeRole   string `json:"generatedNodeRole"`
+	NetworkFieldsSource    string                              `json:"networkFieldsSource"`
+	FailureMessage         string                              `json:"failureMessage"`
+	GeneratedNodeRole      string                              `json:"generatedNodeRole"`
+	GeneratedKmsKey        string                              `json:"generatedKmsKey"`
+	ClusterSecurityGroupID string                              `json:"clusterSecurityGroupId"`
+	ClusterUpdate          *Update                             `json:"clusterUpdate"`
+	NodeGroupUpdates       map[string]Update                   `json:"nodeGroupUpdates"`
+	UpgradePlan            *UpgradePlan                        `json:"upgradePlan"`
+	NodeGroups             []NodeGroupStatus                   `json:"nodeGroups"`
+	Conditions             []genericcondition.GenericCondition `json:"conditions"`
+	UpstreamSpec           *UpstreamSpec                       `json:"upstreamSpec"`
+	DiscoveredResources    *DiscoveredResources                `json:"discoveredResources"`
+}
+
+type DiscoveredResources struct {
+	Stacks      []string `json:"stacks"`
+	ServiceRole string   `json:"serviceRole"`
+	NodeRoles   []string `json:"nodeRoles"`
+	Generation  int64    `json:"generation"`
+}
+
+type UpstreamSpec struct {
+	KubernetesVersion       string                   `json:"kubernetesVersion"`
+	PlatformVersion         string                   `json:"platformVersion"`
+	ARN                     string                   `json:"arn"`
+	Endpoint                string                   `json:"endpoint"`
+	OIDCIssuer              string                   `json:"oidcIssuer"`
+	VPCID                   string                   `json:"vpcId"`
+	ClusterSecurityGroupID  string                   `json:"clusterSecurityGroupId"`
+	Subnets                 []string                 `json:"subnets"`
+	SecurityGroups          []string                 `json:"securityGroups"`
+	PublicAccess            bool                     `json:"publicAccess"`
+	PrivateAccess           bool                     `json:"privateAccess"`
+	PublicAccessSources     []string                 `json:"publicAccessSources"`
+	LoggingTypes            []string                 `json:"loggingTypes"`
+	Tags                    map[string]string        `json:"tags"`
+	SecretsEncryption       bool                     `json:"secretsEncryption"`
+	KmsKey                  string                   `json:"kmsKey"`
+	ServiceRole             string                   `json:"serviceRole"`
+	KubernetesNetworkConfig *KubernetesNetworkConfig `json:"kubernetesNetworkConfig"`
+}
+
+type NodeGroupStatus struct {
+	Name                  string                 `json:"name"`
+	ARN                   string                 `json:"arn"`
+	Status                string                 `json:"status"`
+	Version               string                 `json:"version"`
+	ReleaseVersion        string                 `json:"releaseVersion"`
+	LaunchTemplateID      string                 `json:"launchTemplateId"`
+	LaunchTemplateVersion string                 `json:"launchTemplateVersion"`
+	CapacityType          string                 `json:"capacityType"`
+	AutoScalingGroups     []string               `json:"autoScalingGroups"`
+	ScalingConfig         NodeGroupScalingConfig `json:"scalingConfig"`
+	LastUpdateID          string                 `json:"lastUpdateId"`
+	Issues                []NodeGroupIssue       `json:"issues"`
+	FallbackInstanceType  string                 `json:"fallbackInstanceType"`
+	LastRemediation       string                 `json:"lastRemediation"`
+	// LastRemediationTime is in RFC3339 format
+	LastRemediationTime          string                `json:"lastRemediationTime"`
+	InstanceTypes                []string              `json:"instanceTypes"`
+	ResolvedInstanceRequirements *InstanceRequirements `json:"resolvedInstanceRequirements"`
+	ResolvedInstanceTypes        []string              `json:"resolvedInstanceTypes"`
+	// DroppedInstanceTypes match the resolved instance requirements but are left out by their maxInstanceTypes
+	DroppedInstanceTypes []string `json:"droppedInstanceTypes"`
+}
+
+type NodeGroupScalingConfig struct {
+	MinSize     int64 `json:"minSize"`
+	MaxSize     int64 `json:"maxSize"`
+	DesiredSize int64 `json:"desiredSize"`
+}
+
+type NodeGroupIssue struct {
+	Code        string   `json:"code"`
+	Message     string   `json:"message"`
+	ResourceIDs []string `json:"resourceIds"`
+}
+
+type UpgradePlan struct {
+	FromVersion    string           `json:"fromVersion"`
+	TargetVersion  string           `json:"targetVersion"`
+	Steps          []string         `json:"steps"`
+	CurrentVersion string           `json:"currentVersion"`
+	Message        string           `json:"message"`
+	Insights       []UpgradeInsight `json:"insights"`
+}
+
+type UpgradeInsight struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Status         string `json:"status"`
+	Reason         string `json:"reason"`
+	Recommendation string `json:"recommendation"`
+}
+
+type Update struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	// Generation is the generation of the config the update was sent for
+	Generation int64         `json:"generation"`
+	Errors     []UpdateError `json:"errors"`
+}
+
+type UpdateError struct {
+	ErrorCode    string   `json:"errorCode"`
+	ErrorMessage string   `json:"errorMessage"`
+	ResourceIDs  []string `json:"resourceIds"`
 }
 
 type NodeGroup struct {
@@ -86,6 +207,71 @@ type NodeGroup struct {
 	RequestSpotInstances *bool              `json:"requestSpotInstances"`
 	SpotInstanceTypes    []*string          `json:"spotInstanceTypes"`
 	NodeRole             *string            `json:"nodeRole" norman:"pointer"`
+	// AMIType can only be set on create
+	AMIType             *string                `json:"amiType" norman:"pointer"`
+	BlockDeviceMappings []BlockDeviceMapping   `json:"blockDeviceMappings"`
+	MetadataOptions     *MetadataOptions       `json:"metadataOptions"`
+	SecurityGroups      []string               `json:"securityGroups"`
+	NetworkInterfaces   []NetworkInterface     `json:"networkInterfaces"`
+	UpdateConfig        *NodeGroupUpdateConfig `json:"updateConfig"`
+	// UpgradeStrategy is respectPDB or force
+	UpgradeStrategy      *string               `json:"upgradeStrategy" norman:"pointer"`
+	Remediation          *NodeGroupRemediation `json:"remediation"`
+	InstanceRequirements *InstanceRequirements `json:"instanceRequirements"`
+}
+
+type NodeGroupRemediation struct {
+	NewLaunchTemplateVersion *bool    `json:"newLaunchTemplateVersion"`
+	FallbackInstanceTypes    []string `json:"fallbackInstanceTypes"`
+}
+
+type NodeGroupUpdateConfig struct {
+	MaxUnavailable           *int64 `json:"maxUnavailable"`
+	MaxUnavailablePercentage *int64 `json:"maxUnavailablePercentage"`
+}
+
+type NetworkInterface struct {
+	DeviceIndex    *int64   `json:"deviceIndex"`
+	SecurityGroups []string `json:"securityGroups"`
+}
+
+type InstanceRequirements struct {
+	VCPUCount             *InstanceRequirementsRange `json:"vcpuCount"`
+	MemoryMiB             *InstanceRequirementsRange `json:"memoryMiB"`
+	InstanceGenerations   []string                   `json:"instanceGenerations"`
+	ArchitectureTypes     []string                   `json:"architectureTypes"`
+	CPUManufacturers      []string                   `json:"cpuManufacturers"`
+	BurstablePerformance  *string                    `json:"burstablePerformance" norman:"pointer"`
+	ExcludedInstanceTypes []string                   `json:"excludedInstanceTypes"`
+	MaxInstanceTypes      *int64                     `json:"maxInstanceTypes"`
+}
+
+type InstanceRequirementsRange struct {
+	Min *int64 `json:"min"`
+	Max *int64 `json:"max"`
+}
+
+type MetadataOptions struct {
+	HTTPTokens              *string `json:"httpTokens" norman:"pointer"`
+	HTTPPutResponseHopLimit *int64  `json:"httpPutResponseHopLimit"`
+	HTTPEndpoint            *string `json:"httpEndpoint" norman:"pointer"`
+}
+
+type BlockDeviceMapping struct {
+	DeviceName          *string `json:"deviceName" norman:"pointer"`
+	VolumeSize          *int64  `json:"volumeSize"`
+	VolumeType          *string `json:"volumeType" norman:"pointer"`
+	Iops                *int64  `json:"iops"`
+	Throughput          *int64  `json:"throughput"`
+	Encrypted           *bool   `json:"encrypted"`
+	KmsKeyID            *string `json:"kmsKeyId" norman:"pointer"`
+	DeleteOnTermination *bool   `json:"deleteOnTermination"`
+}
+
+type KubernetesNetworkConfig struct {
+	// IPFamily is the IP family used to assign pod and service addresses. Valid values are ipv4 and ipv6
+	IPFamily        *string `json:"ipFamily" norman:"noupdate,pointer"`
+	ServiceIpv4Cidr *string `json:"serviceIpv4Cidr" norman:"noupdate,pointer"`
 }
 
 type LaunchTemplate struct {
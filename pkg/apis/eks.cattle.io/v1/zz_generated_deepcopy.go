@@ -22,9 +22,92 @@ limitations under the License.
 package v1
 
 import (
+	genericcondition "github.com/rancher/wrangler/pkg/genericcondition"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockDeviceMapping) DeepCopyInto(out *BlockDeviceMapping) {
+	*out = *in
+	if in.DeviceName != nil {
+		in, out := &in.DeviceName, &out.DeviceName
+		*out = new(string)
+		**out = **in
+	}
+	if in.VolumeSize != nil {
+		in, out := &in.VolumeSize, &out.VolumeSize
+		*out = new(int64)
+		**out = **in
+	}
+	if in.VolumeType != nil {
+		in, out := &in.VolumeType, &out.VolumeType
+		*out = new(string)
+		**out = **in
+	}
+	if in.Iops != nil {
+		in, out := &in.Iops, &out.Iops
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Throughput != nil {
+		in, out := &in.Throughput, &out.Throughput
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Encrypted != nil {
+		in, out := &in.Encrypted, &out.Encrypted
+		*out = new(bool)
+		**out = **in
+	}
+	if in.KmsKeyID != nil {
+		in, out := &in.KmsKeyID, &out.KmsKeyID
+		*out = new(string)
+		**out = **in
+	}
+	if in.DeleteOnTermination != nil {
+		in, out := &in.DeleteOnTermination, &out.DeleteOnTermination
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockDeviceMapping.
+func (in *BlockDeviceMapping) DeepCopy() *BlockDeviceMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockDeviceMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiscoveredResources) DeepCopyInto(out *DiscoveredResources) {
+	*out = *in
+	if in.Stacks != nil {
+		in, out := &in.Stacks, &out.Stacks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeRoles != nil {
+		in, out := &in.NodeRoles, &out.NodeRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveredResources.
+func (in *DiscoveredResources) DeepCopy() *DiscoveredResources {
+	if in == nil {
+		return nil
+	}
+	out := new(DiscoveredResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EKSClusterConfig) DeepCopyInto(out *EKSClusterConfig) {
 	*out = *in
@@ -158,6 +241,48 @@ func (in *EKSClusterConfigSpec) DeepCopyInto(out *EKSClusterConfigSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.KubernetesNetworkConfig != nil {
+		in, out := &in.KubernetesNetworkConfig, &out.KubernetesNetworkConfig
+		*out = new(KubernetesNetworkConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KubeconfigAuth != nil {
+		in, out := &in.KubeconfigAuth, &out.KubeconfigAuth
+		*out = new(string)
+		**out = **in
+	}
+	if in.NodeGroupPolicies != nil {
+		in, out := &in.NodeGroupPolicies, &out.NodeGroupPolicies
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DeletionPolicy != nil {
+		in, out := &in.DeletionPolicy, &out.DeletionPolicy
+		*out = new(string)
+		**out = **in
+	}
+	if in.DeletionProtection != nil {
+		in, out := &in.DeletionProtection, &out.DeletionProtection
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Ownership != nil {
+		in, out := &in.Ownership, &out.Ownership
+		*out = new(string)
+		**out = **in
+	}
+	if in.DeleteDiscoveredRoles != nil {
+		in, out := &in.DeleteDiscoveredRoles, &out.DeleteDiscoveredRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.KmsKeyDeletionWindow != nil {
+		in, out := &in.KmsKeyDeletionWindow, &out.KmsKeyDeletionWindow
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -196,6 +321,45 @@ func (in *EKSClusterConfigStatus) DeepCopyInto(out *EKSClusterConfigStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ClusterUpdate != nil {
+		in, out := &in.ClusterUpdate, &out.ClusterUpdate
+		*out = new(Update)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeGroupUpdates != nil {
+		in, out := &in.NodeGroupUpdates, &out.NodeGroupUpdates
+		*out = make(map[string]Update, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.UpgradePlan != nil {
+		in, out := &in.UpgradePlan, &out.UpgradePlan
+		*out = new(UpgradePlan)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeGroups != nil {
+		in, out := &in.NodeGroups, &out.NodeGroups
+		*out = make([]NodeGroupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]genericcondition.GenericCondition, len(*in))
+		copy(*out, *in)
+	}
+	if in.UpstreamSpec != nil {
+		in, out := &in.UpstreamSpec, &out.UpstreamSpec
+		*out = new(UpstreamSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiscoveredResources != nil {
+		in, out := &in.DiscoveredResources, &out.DiscoveredResources
+		*out = new(DiscoveredResources)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -209,6 +373,114 @@ func (in *EKSClusterConfigStatus) DeepCopy() *EKSClusterConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceRequirements) DeepCopyInto(out *InstanceRequirements) {
+	*out = *in
+	if in.VCPUCount != nil {
+		in, out := &in.VCPUCount, &out.VCPUCount
+		*out = new(InstanceRequirementsRange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemoryMiB != nil {
+		in, out := &in.MemoryMiB, &out.MemoryMiB
+		*out = new(InstanceRequirementsRange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstanceGenerations != nil {
+		in, out := &in.InstanceGenerations, &out.InstanceGenerations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ArchitectureTypes != nil {
+		in, out := &in.ArchitectureTypes, &out.ArchitectureTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CPUManufacturers != nil {
+		in, out := &in.CPUManufacturers, &out.CPUManufacturers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BurstablePerformance != nil {
+		in, out := &in.BurstablePerformance, &out.BurstablePerformance
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExcludedInstanceTypes != nil {
+		in, out := &in.ExcludedInstanceTypes, &out.ExcludedInstanceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxInstanceTypes != nil {
+		in, out := &in.MaxInstanceTypes, &out.MaxInstanceTypes
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceRequirements.
+func (in *InstanceRequirements) DeepCopy() *InstanceRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceRequirementsRange) DeepCopyInto(out *InstanceRequirementsRange) {
+	*out = *in
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceRequirementsRange.
+func (in *InstanceRequirementsRange) DeepCopy() *InstanceRequirementsRange {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceRequirementsRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernetesNetworkConfig) DeepCopyInto(out *KubernetesNetworkConfig) {
+	*out = *in
+	if in.IPFamily != nil {
+		in, out := &in.IPFamily, &out.IPFamily
+		*out = new(string)
+		**out = **in
+	}
+	if in.ServiceIpv4Cidr != nil {
+		in, out := &in.ServiceIpv4Cidr, &out.ServiceIpv4Cidr
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesNetworkConfig.
+func (in *KubernetesNetworkConfig) DeepCopy() *KubernetesNetworkConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernetesNetworkConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LaunchTemplate) DeepCopyInto(out *LaunchTemplate) {
 	*out = *in
@@ -240,6 +512,63 @@ func (in *LaunchTemplate) DeepCopy() *LaunchTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataOptions) DeepCopyInto(out *MetadataOptions) {
+	*out = *in
+	if in.HTTPTokens != nil {
+		in, out := &in.HTTPTokens, &out.HTTPTokens
+		*out = new(string)
+		**out = **in
+	}
+	if in.HTTPPutResponseHopLimit != nil {
+		in, out := &in.HTTPPutResponseHopLimit, &out.HTTPPutResponseHopLimit
+		*out = new(int64)
+		**out = **in
+	}
+	if in.HTTPEndpoint != nil {
+		in, out := &in.HTTPEndpoint, &out.HTTPEndpoint
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataOptions.
+func (in *MetadataOptions) DeepCopy() *MetadataOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterface) DeepCopyInto(out *NetworkInterface) {
+	*out = *in
+	if in.DeviceIndex != nil {
+		in, out := &in.DeviceIndex, &out.DeviceIndex
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterface.
+func (in *NetworkInterface) DeepCopy() *NetworkInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeGroup) DeepCopyInto(out *NodeGroup) {
 	*out = *in
@@ -374,6 +703,55 @@ func (in *NodeGroup) DeepCopyInto(out *NodeGroup) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.AMIType != nil {
+		in, out := &in.AMIType, &out.AMIType
+		*out = new(string)
+		**out = **in
+	}
+	if in.BlockDeviceMappings != nil {
+		in, out := &in.BlockDeviceMappings, &out.BlockDeviceMappings
+		*out = make([]BlockDeviceMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MetadataOptions != nil {
+		in, out := &in.MetadataOptions, &out.MetadataOptions
+		*out = new(MetadataOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NetworkInterfaces != nil {
+		in, out := &in.NetworkInterfaces, &out.NetworkInterfaces
+		*out = make([]NetworkInterface, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpdateConfig != nil {
+		in, out := &in.UpdateConfig, &out.UpdateConfig
+		*out = new(NodeGroupUpdateConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradeStrategy != nil {
+		in, out := &in.UpgradeStrategy, &out.UpgradeStrategy
+		*out = new(string)
+		**out = **in
+	}
+	if in.Remediation != nil {
+		in, out := &in.Remediation, &out.Remediation
+		*out = new(NodeGroupRemediation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstanceRequirements != nil {
+		in, out := &in.InstanceRequirements, &out.InstanceRequirements
+		*out = new(InstanceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -386,3 +764,275 @@ func (in *NodeGroup) DeepCopy() *NodeGroup {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeGroupIssue) DeepCopyInto(out *NodeGroupIssue) {
+	*out = *in
+	if in.ResourceIDs != nil {
+		in, out := &in.ResourceIDs, &out.ResourceIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeGroupIssue.
+func (in *NodeGroupIssue) DeepCopy() *NodeGroupIssue {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeGroupIssue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeGroupRemediation) DeepCopyInto(out *NodeGroupRemediation) {
+	*out = *in
+	if in.NewLaunchTemplateVersion != nil {
+		in, out := &in.NewLaunchTemplateVersion, &out.NewLaunchTemplateVersion
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FallbackInstanceTypes != nil {
+		in, out := &in.FallbackInstanceTypes, &out.FallbackInstanceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeGroupRemediation.
+func (in *NodeGroupRemediation) DeepCopy() *NodeGroupRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeGroupRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeGroupScalingConfig) DeepCopyInto(out *NodeGroupScalingConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeGroupScalingConfig.
+func (in *NodeGroupScalingConfig) DeepCopy() *NodeGroupScalingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeGroupScalingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeGroupStatus) DeepCopyInto(out *NodeGroupStatus) {
+	*out = *in
+	if in.AutoScalingGroups != nil {
+		in, out := &in.AutoScalingGroups, &out.AutoScalingGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.ScalingConfig = in.ScalingConfig
+	if in.Issues != nil {
+		in, out := &in.Issues, &out.Issues
+		*out = make([]NodeGroupIssue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InstanceTypes != nil {
+		in, out := &in.InstanceTypes, &out.InstanceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResolvedInstanceRequirements != nil {
+		in, out := &in.ResolvedInstanceRequirements, &out.ResolvedInstanceRequirements
+		*out = new(InstanceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResolvedInstanceTypes != nil {
+		in, out := &in.ResolvedInstanceTypes, &out.ResolvedInstanceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DroppedInstanceTypes != nil {
+		in, out := &in.DroppedInstanceTypes, &out.DroppedInstanceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeGroupStatus.
+func (in *NodeGroupStatus) DeepCopy() *NodeGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeGroupUpdateConfig) DeepCopyInto(out *NodeGroupUpdateConfig) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxUnavailablePercentage != nil {
+		in, out := &in.MaxUnavailablePercentage, &out.MaxUnavailablePercentage
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeGroupUpdateConfig.
+func (in *NodeGroupUpdateConfig) DeepCopy() *NodeGroupUpdateConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeGroupUpdateConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Update) DeepCopyInto(out *Update) {
+	*out = *in
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]UpdateError, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Update.
+func (in *Update) DeepCopy() *Update {
+	if in == nil {
+		return nil
+	}
+	out := new(Update)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateError) DeepCopyInto(out *UpdateError) {
+	*out = *in
+	if in.ResourceIDs != nil {
+		in, out := &in.ResourceIDs, &out.ResourceIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateError.
+func (in *UpdateError) DeepCopy() *UpdateError {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeInsight) DeepCopyInto(out *UpgradeInsight) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeInsight.
+func (in *UpgradeInsight) DeepCopy() *UpgradeInsight {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeInsight)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradePlan) DeepCopyInto(out *UpgradePlan) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Insights != nil {
+		in, out := &in.Insights, &out.Insights
+		*out = make([]UpgradeInsight, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePlan.
+func (in *UpgradePlan) DeepCopy() *UpgradePlan {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamSpec) DeepCopyInto(out *UpstreamSpec) {
+	*out = *in
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PublicAccessSources != nil {
+		in, out := &in.PublicAccessSources, &out.PublicAccessSources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LoggingTypes != nil {
+		in, out := &in.LoggingTypes, &out.LoggingTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.KubernetesNetworkConfig != nil {
+		in, out := &in.KubernetesNetworkConfig, &out.KubernetesNetworkConfig
+		*out = new(KubernetesNetworkConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamSpec.
+func (in *UpstreamSpec) DeepCopy() *UpstreamSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,222 @@
+package eks
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services"
+)
+
+const (
+	bottlerocketStorageDeviceName = "/dev/xvdb"
+	windowsStorageDeviceName      = "/dev/sda1"
+)
+
+// gravitonInstanceFamily matches AWS Graviton (arm64) instance families such as t4g, m6gd, c7gn or is4gen.
+var gravitonInstanceFamily = regexp.MustCompile(`^(a1|[a-z]+[0-9]+g[a-z]*)$`)
+
+// GetAMIType returns the AMI type to send to EKS for the given node group. No AMI type is returned when a custom
+// image is used because EKS derives it from the launch template.
+func GetAMIType(group eksv1.NodeGroup) *string {
+	if aws.StringValue(group.ImageID) != "" {
+		return nil
+	}
+	if amiType := aws.StringValue(group.AMIType); amiType != "" {
+		return aws.String(amiType)
+	}
+	if group.LaunchTemplate != nil {
+		return aws.String(eks.AMITypesCustom)
+	}
+	if aws.BoolValue(group.Gpu) {
+		return aws.String(eks.AMITypesAl2X8664Gpu)
+	}
+	return aws.String(eks.AMITypesAl2X8664)
+}
+
+// ValidateAMIType checks that the AMI type of the node group is supported, that the CUSTOM AMI type comes with an
+// image or a launch template and that the requested instance types match the architecture of the AMI type.
+func ValidateAMIType(group eksv1.NodeGroup) error {
+	amiType := aws.StringValue(group.AMIType)
+	if amiType == "" {
+		return nil
+	}
+
+	if !isSupportedAMIType(amiType) {
+		return fmt.Errorf("nodegroup [%s]: unsupported amiType [%s], valid values are [%s]",
+			aws.StringValue(group.NodegroupName), amiType, strings.Join(eks.AMITypes_Values(), ", "))
+	}
+
+	if aws.StringValue(group.ImageID) != "" && amiType != eks.AMITypesCustom {
+		return fmt.Errorf("nodegroup [%s]: amiType [%s] cannot be used with a custom imageId", aws.StringValue(group.NodegroupName), amiType)
+	}
+
+	if amiType == eks.AMITypesCustom && aws.StringValue(group.ImageID) == "" && group.LaunchTemplate == nil {
+		return fmt.Errorf("nodegroup [%s]: amiType [%s] requires an imageId or a launchTemplate with an image", aws.StringValue(group.NodegroupName), amiType)
+	}
+
+	architecture := getAMITypeArchitecture(amiType)
+	if architecture == "" {
+		return nil
+	}
+
+	instanceTypes := aws.StringValueSlice(group.SpotInstanceTypes)
+	if instanceType := aws.StringValue(group.InstanceType); instanceType != "" {
+		instanceTypes = append(instanceTypes, instanceType)
+	}
+	for _, instanceType := range instanceTypes {
+		if instanceArchitecture := getInstanceTypeArchitecture(instanceType); instanceArchitecture != architecture {
+			return fmt.Errorf("nodegroup [%s]: instance type [%s] has architecture [%s] which does not match amiType [%s]",
+				aws.StringValue(group.NodegroupName), instanceType, instanceArchitecture, amiType)
+		}
+	}
+
+	return nil
+}
+
+type ValidateLaunchTemplateAMITypeOpts struct {
+	EC2Service services.EC2ServiceInterface
+	NodeGroup  eksv1.NodeGroup
+}
+
+// ValidateLaunchTemplateAMIType checks that a node group with a user provided launch template that sets an image
+// has no AMI type other than CUSTOM, EKS rejects node groups that set both. Launch templates without a version use
+// their default version.
+func ValidateLaunchTemplateAMIType(opts *ValidateLaunchTemplateAMITypeOpts) error {
+	group := opts.NodeGroup
+	amiType := aws.StringValue(group.AMIType)
+	if group.LaunchTemplate == nil || amiType == "" || amiType == eks.AMITypesCustom {
+		return nil
+	}
+
+	name := aws.StringValue(group.NodegroupName)
+	version := "$Default"
+	if aws.Int64Value(group.LaunchTemplate.Version) != 0 {
+		version = strconv.FormatInt(*group.LaunchTemplate.Version, 10)
+	}
+	output, err := GetLaunchTemplateVersions(&GetLaunchTemplateVersionsOpts{
+		EC2Service:       opts.EC2Service,
+		LaunchTemplateID: group.LaunchTemplate.ID,
+		Versions:         []*string{aws.String(version)},
+	})
+	if err != nil {
+		return fmt.Errorf("nodegroup [%s]: error describing launch template: %w", name, err)
+	}
+	for _, launchTemplateVersion := range output.LaunchTemplateVersions {
+		if launchTemplateVersion.LaunchTemplateData == nil {
+			continue
+		}
+		if imageID := aws.StringValue(launchTemplateVersion.LaunchTemplateData.ImageId); imageID != "" {
+			return fmt.Errorf("nodegroup [%s]: amiType [%s] cannot be used with launch template [%s] which sets image [%s], use amiType [%s]",
+				name, amiType, aws.StringValue(group.LaunchTemplate.ID), imageID, eks.AMITypesCustom)
+		}
+	}
+
+	return nil
+}
+
+// ValidateAMITypeUpdate checks that the AMI type of an existing node group is unchanged, EKS only sets the AMI type
+// of a node group on create.
+func ValidateAMITypeUpdate(upstreamGroup, group eksv1.NodeGroup) error {
+	amiType := aws.StringValue(group.AMIType)
+	if amiType == "" {
+		return nil
+	}
+
+	upstreamAMIType := aws.StringValue(upstreamGroup.AMIType)
+	if upstreamAMIType == "" {
+		upstreamAMIType = eks.AMITypesCustom
+	}
+	if amiType != upstreamAMIType {
+		return fmt.Errorf("nodegroup [%s]: amiType cannot be changed from [%s] to [%s], it can only be set on create",
+			aws.StringValue(group.NodegroupName), upstreamAMIType, amiType)
+	}
+
+	return nil
+}
+
+// IsGPUAMIType returns true if the AMI type ships with GPU drivers.
+func IsGPUAMIType(amiType string) bool {
+	return amiType == eks.AMITypesAl2X8664Gpu || strings.HasSuffix(amiType, "_NVIDIA")
+}
+
+func isSupportedAMIType(amiType string) bool {
+	for _, supported := range eks.AMITypes_Values() {
+		if amiType == supported {
+			return true
+		}
+	}
+	return false
+}
+
+func isBottlerocketAMIType(amiType string) bool {
+	return strings.HasPrefix(amiType, "BOTTLEROCKET_")
+}
+
+func isWindowsAMIType(amiType string) bool {
+	return strings.HasPrefix(amiType, "WINDOWS_")
+}
+
+// getAMITypeArchitecture returns the EC2 architecture of the given AMI type, or an empty string
+// if the architecture is not known, as is the case for custom AMIs.
+func getAMITypeArchitecture(amiType string) string {
+	switch {
+	case amiType == eks.AMITypesCustom || amiType == "":
+		return ""
+	case strings.Contains(amiType, "ARM_64"):
+		return ec2.ArchitectureTypeArm64
+	default:
+		return ec2.ArchitectureTypeX8664
+	}
+}
+
+func getInstanceTypeArchitecture(instanceType string) string {
+	family := strings.SplitN(instanceType, ".", 2)[0]
+	if gravitonInstanceFamily.MatchString(family) {
+		return ec2.ArchitectureTypeArm64
+	}
+	return ec2.ArchitectureTypeX8664
+}
+
+// getDefaultStorageDeviceName returns the device that holds container images and ephemeral storage for
+// the given AMI type, which is where the node group disk size is applied.
+func getDefaultStorageDeviceName(amiType string) string {
+	switch {
+	case isBottlerocketAMIType(amiType):
+		return bottlerocketStorageDeviceName
+	case isWindowsAMIType(amiType):
+		return windowsStorageDeviceName
+	default:
+		return defaultStorageDeviceName
+	}
+}
+
+// validateUserData checks that the userdata is in the format expected by the bootstrap mechanism of the AMI type.
+// AL2 and AL2023 expect MIME multipart documents, Bottlerocket expects TOML settings and Windows expects PowerShell.
+func validateUserData(group eksv1.NodeGroup, userdata string) error {
+	amiType := aws.StringValue(GetAMIType(group))
+	name := aws.StringValue(group.NodegroupName)
+	isMultipart := strings.Contains(userdata, "Content-Type: multipart/mixed")
+
+	switch {
+	case isBottlerocketAMIType(amiType):
+		if isMultipart || !strings.Contains(userdata, "[settings") {
+			return fmt.Errorf("userdata for nodegroup [%s] with amiType [%s] must be bottlerocket TOML settings", name, amiType)
+		}
+	case isWindowsAMIType(amiType):
+		if !strings.Contains(userdata, "<powershell>") {
+			return fmt.Errorf("userdata for nodegroup [%s] with amiType [%s] must be a powershell script", name, amiType)
+		}
+	default:
+		if !isMultipart {
+			return fmt.Errorf("userdata for nodegroup [%s] is not of mime time multipart/mixed", name)
+		}
+	}
+
+	return nil
+}
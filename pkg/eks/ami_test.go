@@ -0,0 +1,221 @@
+package eks
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+)
+
+var _ = Describe("GetAMIType", func() {
+	var group eksv1.NodeGroup
+
+	BeforeEach(func() {
+		group = eksv1.NodeGroup{
+			NodegroupName: aws.String("test"),
+			Gpu:           aws.Bool(false),
+		}
+	})
+
+	It("should default to AL2 x86_64", func() {
+		Expect(GetAMIType(group)).To(Equal(aws.String(eks.AMITypesAl2X8664)))
+	})
+
+	It("should use AL2 GPU when gpu is set", func() {
+		group.Gpu = aws.Bool(true)
+		Expect(GetAMIType(group)).To(Equal(aws.String(eks.AMITypesAl2X8664Gpu)))
+	})
+
+	It("should prefer the amiType field over gpu", func() {
+		group.Gpu = aws.Bool(true)
+		group.AMIType = aws.String(eks.AMITypesBottlerocketArm64)
+		Expect(GetAMIType(group)).To(Equal(aws.String(eks.AMITypesBottlerocketArm64)))
+	})
+
+	It("should use custom for user provided launch templates", func() {
+		group.LaunchTemplate = &eksv1.LaunchTemplate{ID: aws.String("test")}
+		Expect(GetAMIType(group)).To(Equal(aws.String(eks.AMITypesCustom)))
+	})
+
+	It("should not set an AMI type when an image ID is set", func() {
+		group.ImageID = aws.String("ami-test")
+		group.AMIType = aws.String(eks.AMITypesAl2023X8664Standard)
+		Expect(GetAMIType(group)).To(BeNil())
+	})
+})
+
+var _ = Describe("ValidateAMIType", func() {
+	var group eksv1.NodeGroup
+
+	BeforeEach(func() {
+		group = eksv1.NodeGroup{
+			NodegroupName: aws.String("test"),
+		}
+	})
+
+	It("should accept node groups without an AMI type", func() {
+		group.InstanceType = aws.String("m6g.large")
+		Expect(ValidateAMIType(group)).To(Succeed())
+	})
+
+	It("should accept graviton instances with ARM AMI types", func() {
+		group.AMIType = aws.String(eks.AMITypesAl2023Arm64Standard)
+		group.InstanceType = aws.String("m6gd.xlarge")
+		Expect(ValidateAMIType(group)).To(Succeed())
+
+		group.AMIType = aws.String(eks.AMITypesBottlerocketArm64)
+		group.InstanceType = aws.String("t4g.medium")
+		Expect(ValidateAMIType(group)).To(Succeed())
+	})
+
+	It("should accept x86_64 instances with x86_64 AMI types", func() {
+		group.AMIType = aws.String(eks.AMITypesAl2023X8664Standard)
+		group.InstanceType = aws.String("g5.xlarge")
+		Expect(ValidateAMIType(group)).To(Succeed())
+	})
+
+	It("should reject x86_64 instances with ARM AMI types", func() {
+		group.AMIType = aws.String(eks.AMITypesAl2Arm64)
+		group.InstanceType = aws.String("m5.large")
+		Expect(ValidateAMIType(group)).ToNot(Succeed())
+	})
+
+	It("should reject graviton spot instances with x86_64 AMI types", func() {
+		group.AMIType = aws.String(eks.AMITypesBottlerocketX8664)
+		group.SpotInstanceTypes = aws.StringSlice([]string{"c5.large", "c7g.large"})
+		Expect(ValidateAMIType(group)).ToNot(Succeed())
+	})
+
+	It("should reject unsupported AMI types", func() {
+		group.AMIType = aws.String("UBUNTU")
+		Expect(ValidateAMIType(group)).ToNot(Succeed())
+	})
+
+	It("should reject AMI types together with a custom image", func() {
+		group.AMIType = aws.String(eks.AMITypesAl2X8664)
+		group.ImageID = aws.String("ami-test")
+		Expect(ValidateAMIType(group)).ToNot(Succeed())
+	})
+
+	It("should only accept the custom AMI type with an image or a launch template", func() {
+		group.AMIType = aws.String(eks.AMITypesCustom)
+		Expect(ValidateAMIType(group)).To(MatchError(ContainSubstring("amiType [CUSTOM] requires an imageId or a launchTemplate")))
+
+		group.ImageID = aws.String("ami-test")
+		Expect(ValidateAMIType(group)).To(Succeed())
+
+		group.ImageID = nil
+		group.LaunchTemplate = &eksv1.LaunchTemplate{ID: aws.String("lt-1")}
+		Expect(ValidateAMIType(group)).To(Succeed())
+	})
+})
+
+var _ = Describe("ValidateLaunchTemplateAMIType", func() {
+	var (
+		mockController *gomock.Controller
+		ec2ServiceMock *mock_services.MockEC2ServiceInterface
+		group          eksv1.NodeGroup
+	)
+
+	launchTemplateVersions := func(imageID *string) *ec2.DescribeLaunchTemplateVersionsOutput {
+		return &ec2.DescribeLaunchTemplateVersionsOutput{LaunchTemplateVersions: []*ec2.LaunchTemplateVersion{{
+			LaunchTemplateData: &ec2.ResponseLaunchTemplateData{ImageId: imageID},
+		}}}
+	}
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		ec2ServiceMock = mock_services.NewMockEC2ServiceInterface(mockController)
+		group = eksv1.NodeGroup{
+			NodegroupName:  aws.String("test"),
+			AMIType:        aws.String(eks.AMITypesAl2023X8664Standard),
+			LaunchTemplate: &eksv1.LaunchTemplate{ID: aws.String("lt-1"), Version: aws.Int64(3)},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should accept launch templates without an image", func() {
+		ec2ServiceMock.EXPECT().DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+			LaunchTemplateId: aws.String("lt-1"),
+			Versions:         aws.StringSlice([]string{"3"}),
+		}).Return(launchTemplateVersions(nil), nil)
+		Expect(ValidateLaunchTemplateAMIType(&ValidateLaunchTemplateAMITypeOpts{EC2Service: ec2ServiceMock, NodeGroup: group})).To(Succeed())
+	})
+
+	It("should reject AMI types other than custom with a launch template image", func() {
+		group.LaunchTemplate.Version = nil
+		ec2ServiceMock.EXPECT().DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+			LaunchTemplateId: aws.String("lt-1"),
+			Versions:         aws.StringSlice([]string{"$Default"}),
+		}).Return(launchTemplateVersions(aws.String("ami-test")), nil)
+		Expect(ValidateLaunchTemplateAMIType(&ValidateLaunchTemplateAMITypeOpts{EC2Service: ec2ServiceMock, NodeGroup: group})).
+			To(MatchError(ContainSubstring("amiType [AL2023_x86_64_STANDARD] cannot be used with launch template [lt-1] which sets image [ami-test]")))
+	})
+
+	It("should not check the custom AMI type", func() {
+		group.AMIType = aws.String(eks.AMITypesCustom)
+		Expect(ValidateLaunchTemplateAMIType(&ValidateLaunchTemplateAMITypeOpts{EC2Service: ec2ServiceMock, NodeGroup: group})).To(Succeed())
+	})
+})
+
+var _ = Describe("ValidateAMITypeUpdate", func() {
+	It("should only accept the amiType of the upstream node group", func() {
+		upstreamGroup := eksv1.NodeGroup{AMIType: aws.String(eks.AMITypesAl2X8664)}
+		group := eksv1.NodeGroup{NodegroupName: aws.String("test")}
+		Expect(ValidateAMITypeUpdate(upstreamGroup, group)).To(Succeed())
+
+		group.AMIType = aws.String(eks.AMITypesAl2X8664)
+		Expect(ValidateAMITypeUpdate(upstreamGroup, group)).To(Succeed())
+
+		group.AMIType = aws.String(eks.AMITypesAl2023X8664Standard)
+		Expect(ValidateAMITypeUpdate(upstreamGroup, group)).To(MatchError(ContainSubstring("amiType cannot be changed from [AL2_x86_64] to [AL2023_x86_64_STANDARD]")))
+	})
+
+	It("should treat upstream node groups without an amiType as custom", func() {
+		group := eksv1.NodeGroup{NodegroupName: aws.String("test"), AMIType: aws.String(eks.AMITypesCustom)}
+		Expect(ValidateAMITypeUpdate(eksv1.NodeGroup{}, group)).To(Succeed())
+	})
+})
+
+var _ = Describe("validateUserData", func() {
+	const (
+		multipartUserdata    = "MIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=\"==MYBOUNDARY==\"\r\n\r\n--==MYBOUNDARY==--\r\n"
+		bottlerocketUserdata = "[settings.kubernetes]\nmax-pods = 110\n"
+		windowsUserdata      = "<powershell>\nWrite-Host hello\n</powershell>"
+	)
+	var group eksv1.NodeGroup
+
+	BeforeEach(func() {
+		group = eksv1.NodeGroup{
+			NodegroupName: aws.String("test"),
+		}
+	})
+
+	It("should require multipart userdata for AL2 and AL2023", func() {
+		Expect(validateUserData(group, multipartUserdata)).To(Succeed())
+		Expect(validateUserData(group, bottlerocketUserdata)).ToNot(Succeed())
+
+		group.AMIType = aws.String(eks.AMITypesAl2023X8664Standard)
+		Expect(validateUserData(group, multipartUserdata)).To(Succeed())
+		Expect(validateUserData(group, windowsUserdata)).ToNot(Succeed())
+	})
+
+	It("should require TOML settings for bottlerocket", func() {
+		group.AMIType = aws.String(eks.AMITypesBottlerocketX8664Nvidia)
+		Expect(validateUserData(group, bottlerocketUserdata)).To(Succeed())
+		Expect(validateUserData(group, multipartUserdata)).ToNot(Succeed())
+	})
+
+	It("should require powershell for windows", func() {
+		group.AMIType = aws.String(eks.AMITypesWindowsCore2022X8664)
+		Expect(validateUserData(group, windowsUserdata)).To(Succeed())
+		Expect(validateUserData(group, bottlerocketUserdata)).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,298 @@
+package eks
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services"
+	"github.com/sirupsen/logrus"
+)
+
+const ec2ServiceQuotaCode = "ec2"
+
+// vCPU quota codes of the running instances of each instance class, on-demand and spot.
+var (
+	onDemandVCPUQuotaCodes = map[string]string{
+		"standard": "L-1216C47A",
+		"f":        "L-74FC7D96",
+		"g":        "L-DB2E81BA",
+		"inf":      "L-1945791B",
+		"p":        "L-417A185B",
+		"x":        "L-7295265B",
+		"dl":       "L-6E869C2A",
+		"trn":      "L-2C3B7624",
+	}
+	spotVCPUQuotaCodes = map[string]string{
+		"standard": "L-34B43A08",
+		"f":        "L-88CF9481",
+		"g":        "L-3819A6DF",
+		"inf":      "L-B5D1601B",
+		"p":        "L-7212CCBC",
+		"x":        "L-E3A00192",
+		"dl":       "L-85EED4F7",
+		"trn":      "L-6B0D517C",
+	}
+)
+
+type CheckNodeGroupCapacityOpts struct {
+	EC2Service           services.EC2ServiceInterface
+	ServiceQuotasService services.ServiceQuotasServiceInterface
+	Config               *eksv1.EKSClusterConfig
+	NodeGroup            eksv1.NodeGroup
+}
+
+// CheckNodeGroupCapacity returns the problems that would keep EC2 from launching the instances of a node group about to
+// be created: availability zones of its subnets without any of its instance types, and a desired size whose vCPUs
+// exceed the vCPU quota of its instance class on top of the vCPUs its running instances already use. Node groups with
+// their own launch template are not checked.
+func CheckNodeGroupCapacity(opts *CheckNodeGroupCapacityOpts) ([]string, error) {
+	ng := opts.NodeGroup
+	nodegroupName := aws.StringValue(ng.NodegroupName)
+	if ng.LaunchTemplate != nil {
+		return nil, nil
+	}
+	spot := aws.BoolValue(ng.RequestSpotInstances)
+	instanceTypes := GetNodeGroupInstanceTypes(opts.Config, ng)
+	if len(instanceTypes) == 0 {
+		return nil, nil
+	}
+
+	subnetIDs := ng.Subnets
+	if len(subnetIDs) == 0 {
+		subnetIDs = opts.Config.Status.Subnets
+	}
+	subnets, err := describeSubnets(opts.EC2Service, subnetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error describing subnets of nodegroup [%s]: %w", nodegroupName, err)
+	}
+	zones := make(map[string]bool)
+	for _, subnet := range subnets {
+		zones[aws.StringValue(subnet.AvailabilityZone)] = true
+	}
+
+	offerings, err := getInstanceTypeOfferings(opts.EC2Service, instanceTypes, sortedKeys(zones))
+	if err != nil {
+		return nil, fmt.Errorf("error describing instance type offerings for nodegroup [%s]: %w", nodegroupName, err)
+	}
+
+	// node groups with several instance types, spot ones or ones whose instance requirements resolve to several
+	// instance types, launch any of them, so each zone only needs one of them and instance types that are not
+	// offered are only a warning
+	var problems []string
+	if len(instanceTypes) > 1 {
+		for _, instanceType := range instanceTypes {
+			if len(offerings[instanceType]) == 0 {
+				logrus.Warnf("instance type [%s] of nodegroup [%s] of cluster [%s] is not offered in availability zones [%s]",
+					instanceType, nodegroupName, opts.Config.Name, strings.Join(sortedKeys(zones), ", "))
+			}
+		}
+		for _, zone := range sortedKeys(zones) {
+			offered := false
+			for _, instanceType := range instanceTypes {
+				offered = offered || offerings[instanceType][zone]
+			}
+			if !offered {
+				problems = append(problems, fmt.Sprintf("nodegroup [%s]: none of the instance types [%s] are offered in availability zone [%s]",
+					nodegroupName, strings.Join(instanceTypes, ", "), zone))
+			}
+		}
+	} else {
+		var missingZones []string
+		for _, zone := range sortedKeys(zones) {
+			if !offerings[instanceTypes[0]][zone] {
+				missingZones = append(missingZones, zone)
+			}
+		}
+		if len(missingZones) != 0 {
+			problems = append(problems, fmt.Sprintf("nodegroup [%s]: instance type [%s] is not offered in availability zones [%s]",
+				nodegroupName, instanceTypes[0], strings.Join(missingZones, ", ")))
+		}
+	}
+
+	quotaProblems, err := checkVCPUQuotas(opts, instanceTypes, spot)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(problems, quotaProblems...), nil
+}
+
+// getInstanceTypeOfferings returns the availability zones each instance type is offered in.
+func getInstanceTypeOfferings(ec2Service services.EC2ServiceInterface, instanceTypes, zones []string) (map[string]map[string]bool, error) {
+	offerings := make(map[string]map[string]bool)
+	input := &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: aws.String(ec2.LocationTypeAvailabilityZone),
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-type"),
+				Values: aws.StringSlice(instanceTypes),
+			},
+			{
+				Name:   aws.String("location"),
+				Values: aws.StringSlice(zones),
+			},
+		},
+	}
+	for {
+		output, err := ec2Service.DescribeInstanceTypeOfferings(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, offering := range output.InstanceTypeOfferings {
+			instanceType := aws.StringValue(offering.InstanceType)
+			if offerings[instanceType] == nil {
+				offerings[instanceType] = make(map[string]bool)
+			}
+			offerings[instanceType][aws.StringValue(offering.Location)] = true
+		}
+		if aws.StringValue(output.NextToken) == "" {
+			return offerings, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// checkVCPUQuotas returns a problem for each instance class whose vCPU quota is lower than the vCPUs already in use
+// plus the vCPUs of the desired size of the node group. Spot node groups are assumed to launch their smallest
+// instance type. Quotas that cannot be read are not checked.
+func checkVCPUQuotas(opts *CheckNodeGroupCapacityOpts, instanceTypes []string, spot bool) ([]string, error) {
+	nodegroupName := aws.StringValue(opts.NodeGroup.NodegroupName)
+	desiredSize := aws.Int64Value(opts.NodeGroup.DesiredSize)
+	if desiredSize == 0 {
+		return nil, nil
+	}
+
+	output, err := opts.EC2Service.DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+		InstanceTypes: aws.StringSlice(instanceTypes),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing instance types of nodegroup [%s]: %w", nodegroupName, err)
+	}
+	quotaCodes := onDemandVCPUQuotaCodes
+	if spot {
+		quotaCodes = spotVCPUQuotaCodes
+	}
+	classVCPUs := make(map[string]int64)
+	for _, instanceType := range output.InstanceTypes {
+		if instanceType.VCpuInfo == nil {
+			continue
+		}
+		class := getInstanceClass(aws.StringValue(instanceType.InstanceType))
+		if _, ok := quotaCodes[class]; !ok {
+			continue
+		}
+		vcpus := aws.Int64Value(instanceType.VCpuInfo.DefaultVCpus)
+		if current, ok := classVCPUs[class]; !ok || vcpus < current {
+			classVCPUs[class] = vcpus
+		}
+	}
+
+	usedVCPUs, err := getUsedVCPUs(opts.EC2Service, spot)
+	if err != nil {
+		var awsErr awserr.Error
+		if !errors.As(err, &awsErr) || awsErr.Code() != "UnauthorizedOperation" {
+			return nil, fmt.Errorf("error describing running instances for nodegroup [%s]: %w", nodegroupName, err)
+		}
+		logrus.Warnf("cannot count vcpus in use for nodegroup [%s] of cluster [%s], only the quota limit is checked: %v", nodegroupName, opts.Config.Name, err)
+	}
+
+	var classes []string
+	for class := range classVCPUs {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	var problems []string
+	for _, class := range classes {
+		quota, err := opts.ServiceQuotasService.GetServiceQuota(&servicequotas.GetServiceQuotaInput{
+			ServiceCode: aws.String(ec2ServiceQuotaCode),
+			QuotaCode:   aws.String(quotaCodes[class]),
+		})
+		if err != nil {
+			var awsErr awserr.Error
+			if errors.As(err, &awsErr) && (awsErr.Code() == servicequotas.ErrCodeAccessDeniedException || awsErr.Code() == servicequotas.ErrCodeNoSuchResourceException) {
+				logrus.Warnf("cannot check vcpu quota [%s] for nodegroup [%s] of cluster [%s]: %v", quotaCodes[class], nodegroupName, opts.Config.Name, err)
+				continue
+			}
+			return nil, fmt.Errorf("error getting vcpu quota [%s] for nodegroup [%s]: %w", quotaCodes[class], nodegroupName, err)
+		}
+		if quota.Quota == nil {
+			continue
+		}
+
+		limit := int64(aws.Float64Value(quota.Quota.Value))
+		if required := desiredSize * classVCPUs[class]; required+usedVCPUs[class] > limit {
+			left := limit - usedVCPUs[class]
+			if left < 0 {
+				left = 0
+			}
+			problems = append(problems, fmt.Sprintf("nodegroup [%s]: desired size [%d] needs [%d] vcpus, more than the [%d] vcpus left of the quota [%s] of [%d] vcpus",
+				nodegroupName, desiredSize, required, left, aws.StringValue(quota.Quota.QuotaName), limit))
+		}
+	}
+
+	return problems, nil
+}
+
+// getUsedVCPUs returns the vCPUs of the pending and running instances of each instance class, counting either the
+// spot or the on-demand instances.
+func getUsedVCPUs(ec2Service services.EC2ServiceInterface, spot bool) (map[string]int64, error) {
+	usedVCPUs := make(map[string]int64)
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning}),
+			},
+		},
+	}
+	for {
+		output, err := ec2Service.DescribeInstances(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, reservation := range output.Reservations {
+			for _, instance := range reservation.Instances {
+				if (aws.StringValue(instance.InstanceLifecycle) == ec2.InstanceLifecycleTypeSpot) != spot || instance.CpuOptions == nil {
+					continue
+				}
+				class := getInstanceClass(aws.StringValue(instance.InstanceType))
+				usedVCPUs[class] += aws.Int64Value(instance.CpuOptions.CoreCount) * aws.Int64Value(instance.CpuOptions.ThreadsPerCore)
+			}
+		}
+		if aws.StringValue(output.NextToken) == "" {
+			return usedVCPUs, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// getInstanceClass returns the class of an instance type, as used by the vCPU quotas: standard for the A, C, D,
+// H, I, M, R, T and Z families, the prefix of the family otherwise. Classes without a quota code are not checked.
+func getInstanceClass(instanceType string) string {
+	family := strings.SplitN(instanceType, ".", 2)[0]
+	for _, prefix := range []string{"inf", "trn", "dl", "vt"} {
+		if strings.HasPrefix(family, prefix) {
+			if prefix == "vt" {
+				return "g"
+			}
+			return prefix
+		}
+	}
+	if family == "" {
+		return ""
+	}
+	switch first := family[:1]; first {
+	case "a", "c", "d", "h", "i", "m", "r", "t", "z":
+		return "standard"
+	default:
+		return first
+	}
+}
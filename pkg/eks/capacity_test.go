@@ -0,0 +1,203 @@
+package eks
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+)
+
+var _ = Describe("CheckNodeGroupCapacity", func() {
+	var (
+		mockController             *gomock.Controller
+		ec2ServiceMock             *mock_services.MockEC2ServiceInterface
+		serviceQuotasServiceMock   *mock_services.MockServiceQuotasServiceInterface
+		checkNodeGroupCapacityOpts *CheckNodeGroupCapacityOpts
+	)
+
+	offerings := func(offered map[string][]string) *ec2.DescribeInstanceTypeOfferingsOutput {
+		output := &ec2.DescribeInstanceTypeOfferingsOutput{}
+		for instanceType, zones := range offered {
+			for _, zone := range zones {
+				output.InstanceTypeOfferings = append(output.InstanceTypeOfferings, &ec2.InstanceTypeOffering{
+					InstanceType: aws.String(instanceType),
+					Location:     aws.String(zone),
+				})
+			}
+		}
+		return output
+	}
+	instanceTypes := func(vcpus map[string]int64) *ec2.DescribeInstanceTypesOutput {
+		output := &ec2.DescribeInstanceTypesOutput{}
+		for instanceType, count := range vcpus {
+			output.InstanceTypes = append(output.InstanceTypes, &ec2.InstanceTypeInfo{
+				InstanceType: aws.String(instanceType),
+				VCpuInfo:     &ec2.VCpuInfo{DefaultVCpus: aws.Int64(count)},
+			})
+		}
+		return output
+	}
+	runningInstances := func(instances ...*ec2.Instance) *ec2.DescribeInstancesOutput {
+		return &ec2.DescribeInstancesOutput{Reservations: []*ec2.Reservation{{Instances: instances}}}
+	}
+	instance := func(instanceType string, vcpus int64, lifecycle *string) *ec2.Instance {
+		return &ec2.Instance{
+			InstanceType:      aws.String(instanceType),
+			InstanceLifecycle: lifecycle,
+			CpuOptions:        &ec2.CpuOptions{CoreCount: aws.Int64(vcpus / 2), ThreadsPerCore: aws.Int64(2)},
+		}
+	}
+	quota := func(value float64) *servicequotas.GetServiceQuotaOutput {
+		return &servicequotas.GetServiceQuotaOutput{Quota: &servicequotas.ServiceQuota{
+			QuotaName: aws.String("Running On-Demand Standard instances"),
+			Value:     aws.Float64(value),
+		}}
+	}
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		ec2ServiceMock = mock_services.NewMockEC2ServiceInterface(mockController)
+		serviceQuotasServiceMock = mock_services.NewMockServiceQuotasServiceInterface(mockController)
+		checkNodeGroupCapacityOpts = &CheckNodeGroupCapacityOpts{
+			EC2Service:           ec2ServiceMock,
+			ServiceQuotasService: serviceQuotasServiceMock,
+			Config: &eksv1.EKSClusterConfig{
+				Status: eksv1.EKSClusterConfigStatus{
+					Subnets: []string{"subnet-1", "subnet-2"},
+				},
+			},
+			NodeGroup: eksv1.NodeGroup{
+				NodegroupName: aws.String("ng"),
+				InstanceType:  aws.String("m5.large"),
+				DesiredSize:   aws.Int64(2),
+			},
+		}
+		ec2ServiceMock.EXPECT().DescribeSubnets(&ec2.DescribeSubnetsInput{
+			Filters: []*ec2.Filter{{Name: aws.String("subnet-id"), Values: aws.StringSlice([]string{"subnet-1", "subnet-2"})}},
+		}).Return(
+			&ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{
+				{SubnetId: aws.String("subnet-1"), AvailabilityZone: aws.String("us-east-1a")},
+				{SubnetId: aws.String("subnet-2"), AvailabilityZone: aws.String("us-east-1b")},
+			}}, nil).AnyTimes()
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should accept an instance type offered in all zones within the vcpu quota", func() {
+		ec2ServiceMock.EXPECT().DescribeInstanceTypeOfferings(gomock.Any()).Return(
+			offerings(map[string][]string{"m5.large": {"us-east-1a", "us-east-1b"}}), nil)
+		ec2ServiceMock.EXPECT().DescribeInstanceTypes(gomock.Any()).Return(instanceTypes(map[string]int64{"m5.large": 2}), nil)
+		ec2ServiceMock.EXPECT().DescribeInstances(&ec2.DescribeInstancesInput{
+			Filters: []*ec2.Filter{{Name: aws.String("instance-state-name"), Values: aws.StringSlice([]string{"pending", "running"})}},
+		}).Return(runningInstances(instance("c5.large", 2, nil), instance("m5.xlarge", 4, aws.String("spot"))), nil)
+		serviceQuotasServiceMock.EXPECT().GetServiceQuota(&servicequotas.GetServiceQuotaInput{
+			ServiceCode: aws.String("ec2"),
+			QuotaCode:   aws.String("L-1216C47A"),
+		}).Return(quota(6), nil)
+
+		Expect(CheckNodeGroupCapacity(checkNodeGroupCapacityOpts)).To(BeEmpty())
+	})
+
+	It("should report zones without the instance type and a desired size over the vcpu quota left", func() {
+		ec2ServiceMock.EXPECT().DescribeInstanceTypeOfferings(gomock.Any()).Return(
+			offerings(map[string][]string{"m5.large": {"us-east-1a"}}), nil)
+		ec2ServiceMock.EXPECT().DescribeInstanceTypes(gomock.Any()).Return(instanceTypes(map[string]int64{"m5.large": 2}), nil)
+		ec2ServiceMock.EXPECT().DescribeInstances(gomock.Any()).Return(runningInstances(instance("m5.xlarge", 4, nil)), nil)
+		serviceQuotasServiceMock.EXPECT().GetServiceQuota(gomock.Any()).Return(quota(6), nil)
+
+		problems, err := CheckNodeGroupCapacity(checkNodeGroupCapacityOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(ConsistOf(
+			"nodegroup [ng]: instance type [m5.large] is not offered in availability zones [us-east-1b]",
+			"nodegroup [ng]: desired size [2] needs [4] vcpus, more than the [2] vcpus left of the quota [Running On-Demand Standard instances] of [6] vcpus",
+		))
+	})
+
+	It("should only require one spot instance type per zone", func() {
+		checkNodeGroupCapacityOpts.NodeGroup.InstanceType = nil
+		checkNodeGroupCapacityOpts.NodeGroup.RequestSpotInstances = aws.Bool(true)
+		checkNodeGroupCapacityOpts.NodeGroup.SpotInstanceTypes = aws.StringSlice([]string{"m5.large", "c5.large", "g4dn.xlarge"})
+		ec2ServiceMock.EXPECT().DescribeInstanceTypeOfferings(gomock.Any()).Return(
+			offerings(map[string][]string{"m5.large": {"us-east-1a"}, "c5.large": {"us-east-1b"}}), nil)
+		ec2ServiceMock.EXPECT().DescribeInstanceTypes(gomock.Any()).Return(
+			instanceTypes(map[string]int64{"m5.large": 2, "c5.large": 2, "g4dn.xlarge": 4}), nil)
+		ec2ServiceMock.EXPECT().DescribeInstances(gomock.Any()).Return(
+			nil, awserr.New("UnauthorizedOperation", "denied", nil))
+		serviceQuotasServiceMock.EXPECT().GetServiceQuota(&servicequotas.GetServiceQuotaInput{
+			ServiceCode: aws.String("ec2"),
+			QuotaCode:   aws.String("L-3819A6DF"),
+		}).Return(nil, awserr.New(servicequotas.ErrCodeAccessDeniedException, "denied", nil))
+		serviceQuotasServiceMock.EXPECT().GetServiceQuota(&servicequotas.GetServiceQuotaInput{
+			ServiceCode: aws.String("ec2"),
+			QuotaCode:   aws.String("L-34B43A08"),
+		}).Return(quota(16), nil)
+
+		problems, err := CheckNodeGroupCapacity(checkNodeGroupCapacityOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(BeEmpty())
+	})
+
+	It("should report zones without any of the spot instance types", func() {
+		checkNodeGroupCapacityOpts.NodeGroup.InstanceType = nil
+		checkNodeGroupCapacityOpts.NodeGroup.RequestSpotInstances = aws.Bool(true)
+		checkNodeGroupCapacityOpts.NodeGroup.SpotInstanceTypes = aws.StringSlice([]string{"m5.large", "c5.large"})
+		ec2ServiceMock.EXPECT().DescribeInstanceTypeOfferings(gomock.Any()).Return(
+			offerings(map[string][]string{"m5.large": {"us-east-1a"}}), nil)
+		ec2ServiceMock.EXPECT().DescribeInstanceTypes(gomock.Any()).Return(
+			instanceTypes(map[string]int64{"m5.large": 2, "c5.large": 2}), nil)
+		ec2ServiceMock.EXPECT().DescribeInstances(gomock.Any()).Return(runningInstances(), nil)
+		serviceQuotasServiceMock.EXPECT().GetServiceQuota(gomock.Any()).Return(quota(16), nil)
+
+		problems, err := CheckNodeGroupCapacity(checkNodeGroupCapacityOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(ConsistOf(
+			"nodegroup [ng]: none of the instance types [m5.large, c5.large] are offered in availability zone [us-east-1b]",
+		))
+	})
+
+	It("should only require one of the resolved on-demand instance types per zone", func() {
+		checkNodeGroupCapacityOpts.NodeGroup.InstanceType = nil
+		checkNodeGroupCapacityOpts.NodeGroup.InstanceRequirements = &eksv1.InstanceRequirements{
+			VCPUCount: &eksv1.InstanceRequirementsRange{Min: aws.Int64(2)},
+		}
+		checkNodeGroupCapacityOpts.Config.Status.NodeGroups = []eksv1.NodeGroupStatus{
+			{Name: "ng", ResolvedInstanceTypes: []string{"m5.large", "m6i.large"}},
+		}
+		ec2ServiceMock.EXPECT().DescribeInstanceTypeOfferings(gomock.Any()).Return(
+			offerings(map[string][]string{"m5.large": {"us-east-1a"}, "m6i.large": {"us-east-1a", "us-east-1b"}}), nil)
+		ec2ServiceMock.EXPECT().DescribeInstanceTypes(gomock.Any()).Return(
+			instanceTypes(map[string]int64{"m5.large": 2, "m6i.large": 2}), nil)
+		ec2ServiceMock.EXPECT().DescribeInstances(gomock.Any()).Return(runningInstances(), nil)
+		serviceQuotasServiceMock.EXPECT().GetServiceQuota(gomock.Any()).Return(quota(16), nil)
+
+		problems, err := CheckNodeGroupCapacity(checkNodeGroupCapacityOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(problems).To(BeEmpty())
+	})
+
+	It("should not check node groups with a launch template", func() {
+		checkNodeGroupCapacityOpts.NodeGroup.LaunchTemplate = &eksv1.LaunchTemplate{ID: aws.String("lt-1")}
+
+		Expect(CheckNodeGroupCapacity(checkNodeGroupCapacityOpts)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("getInstanceClass", func() {
+	It("should return the vcpu quota class of instance types", func() {
+		Expect(getInstanceClass("m5.large")).To(Equal("standard"))
+		Expect(getInstanceClass("t3a.micro")).To(Equal("standard"))
+		Expect(getInstanceClass("g4dn.xlarge")).To(Equal("g"))
+		Expect(getInstanceClass("vt1.3xlarge")).To(Equal("g"))
+		Expect(getInstanceClass("inf2.xlarge")).To(Equal("inf"))
+		Expect(getInstanceClass("dl1.24xlarge")).To(Equal("dl"))
+		Expect(getInstanceClass("trn1.2xlarge")).To(Equal("trn"))
+		Expect(getInstanceClass("p4d.24xlarge")).To(Equal("p"))
+	})
+})
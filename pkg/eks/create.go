@@ -72,11 +72,18 @@ func newClusterInput(config *eksv1.EKSClusterConfig, roleARN string) *eks.Create
 		Version: config.Spec.KubernetesVersion,
 	}
 
+	if networkConfig := config.Spec.KubernetesNetworkConfig; networkConfig != nil {
+		createClusterInput.KubernetesNetworkConfig = &eks.KubernetesNetworkConfigRequest{
+			IpFamily:        networkConfig.IPFamily,
+			ServiceIpv4Cidr: networkConfig.ServiceIpv4Cidr,
+		}
+	}
+
 	if aws.BoolValue(config.Spec.SecretsEncryption) {
 		createClusterInput.EncryptionConfig = []*eks.EncryptionConfig{
 			{
 				Provider: &eks.Provider{
-					KeyArn: config.Spec.KmsKey,
+					KeyArn: aws.String(GetKMSKey(config)),
 				},
 				Resources: aws.StringSlice([]string{"secrets"}),
 			},
@@ -225,28 +232,14 @@ type CreateNodeGroupOptions struct {
 
 func CreateNodeGroup(opts *CreateNodeGroupOptions) (string, string, error) {
 	var err error
-	capacityType := eks.CapacityTypesOnDemand
-	if aws.BoolValue(opts.NodeGroup.RequestSpotInstances) {
-		capacityType = eks.CapacityTypesSpot
-	}
-	nodeGroupCreateInput := &eks.CreateNodegroupInput{
-		ClusterName:   aws.String(opts.Config.Spec.DisplayName),
-		NodegroupName: opts.NodeGroup.NodegroupName,
-		Labels:        opts.NodeGroup.Labels,
-		ScalingConfig: &eks.NodegroupScalingConfig{
-			DesiredSize: opts.NodeGroup.DesiredSize,
-			MaxSize:     opts.NodeGroup.MaxSize,
-			MinSize:     opts.NodeGroup.MinSize,
-		},
-		CapacityType: aws.String(capacityType),
-	}
+	nodeGroupCreateInput := newNodegroupInput(opts.Config, opts.NodeGroup)
 
 	lt := opts.NodeGroup.LaunchTemplate
 
 	if lt == nil {
 		// In this case, the user has not specified their own launch template.
 		// If the cluster doesn't have a launch template associated with it, then we create one.
-		lt, err = CreateNewLaunchTemplateVersion(opts.EC2Service, opts.Config.Status.ManagedLaunchTemplateID, opts.NodeGroup)
+		lt, err = CreateNewLaunchTemplateVersion(opts.EC2Service, opts.Config.Status.ManagedLaunchTemplateID, opts.Config.Status.ClusterSecurityGroupID, opts.NodeGroup)
 		if err != nil {
 			return "", "", err
 		}
@@ -262,26 +255,6 @@ func CreateNodeGroup(opts *CreateNodeGroupOptions) (string, string, error) {
 		Version: launchTemplateVersion,
 	}
 
-	if aws.BoolValue(opts.NodeGroup.RequestSpotInstances) {
-		nodeGroupCreateInput.InstanceTypes = opts.NodeGroup.SpotInstanceTypes
-	}
-
-	if aws.StringValue(opts.NodeGroup.ImageID) == "" {
-		if opts.NodeGroup.LaunchTemplate != nil {
-			nodeGroupCreateInput.AmiType = aws.String(eks.AMITypesCustom)
-		} else if gpu := opts.NodeGroup.Gpu; aws.BoolValue(gpu) {
-			nodeGroupCreateInput.AmiType = aws.String(eks.AMITypesAl2X8664Gpu)
-		} else {
-			nodeGroupCreateInput.AmiType = aws.String(eks.AMITypesAl2X8664)
-		}
-	}
-
-	if len(opts.NodeGroup.Subnets) != 0 {
-		nodeGroupCreateInput.Subnets = aws.StringSlice(opts.NodeGroup.Subnets)
-	} else {
-		nodeGroupCreateInput.Subnets = aws.StringSlice(opts.Config.Status.Subnets)
-	}
-
 	generatedNodeRole := opts.Config.Status.GeneratedNodeRole
 
 	if aws.StringValue(opts.NodeGroup.NodeRole) == "" {
@@ -293,7 +266,12 @@ func CreateNodeGroup(opts *CreateNodeGroupOptions) (string, string, error) {
 				DisplayName:           opts.Config.Spec.DisplayName,
 				TemplateBody:          finalTemplate,
 				Capabilities:          []string{cloudformation.CapabilityCapabilityIam},
-				Parameters:            []*cloudformation.Parameter{},
+				Parameters: []*cloudformation.Parameter{
+					{
+						ParameterKey:   aws.String("EnableIPv6"),
+						ParameterValue: aws.String(strconv.FormatBool(IsIPv6Cluster(opts.Config))),
+					},
+				},
 			})
 			if err != nil {
 				// If there was an error creating the node role stack, return an empty launch template
@@ -303,8 +281,6 @@ func CreateNodeGroup(opts *CreateNodeGroupOptions) (string, string, error) {
 			generatedNodeRole = getParameterValueFromOutput("NodeInstanceRole", output.Stacks[0].Outputs)
 		}
 		nodeGroupCreateInput.NodeRole = aws.String(generatedNodeRole)
-	} else {
-		nodeGroupCreateInput.NodeRole = opts.NodeGroup.NodeRole
 	}
 
 	_, err = opts.EKSService.CreateNodegroup(nodeGroupCreateInput)
@@ -319,8 +295,46 @@ func CreateNodeGroup(opts *CreateNodeGroupOptions) (string, string, error) {
 	return aws.StringValue(launchTemplateVersion), generatedNodeRole, err
 }
 
-func CreateNewLaunchTemplateVersion(ec2Service services.EC2ServiceInterface, launchTemplateID string, group eksv1.NodeGroup) (*eksv1.LaunchTemplate, error) {
-	launchTemplate, err := buildLaunchTemplateData(ec2Service, group)
+// newNodegroupInput returns the input to create the node group, without the launch template and, if the node
+// group does not set one, the node role.
+func newNodegroupInput(config *eksv1.EKSClusterConfig, group eksv1.NodeGroup) *eks.CreateNodegroupInput {
+	capacityType := eks.CapacityTypesOnDemand
+	if aws.BoolValue(group.RequestSpotInstances) {
+		capacityType = eks.CapacityTypesSpot
+	}
+	nodeGroupCreateInput := &eks.CreateNodegroupInput{
+		ClusterName:   aws.String(config.Spec.DisplayName),
+		NodegroupName: group.NodegroupName,
+		Labels:        group.Labels,
+		ScalingConfig: &eks.NodegroupScalingConfig{
+			DesiredSize: group.DesiredSize,
+			MaxSize:     group.MaxSize,
+			MinSize:     group.MinSize,
+		},
+		CapacityType: aws.String(capacityType),
+		UpdateConfig: buildUpdateConfig(group),
+		AmiType:      GetAMIType(group),
+	}
+
+	if aws.BoolValue(group.RequestSpotInstances) || group.InstanceRequirements != nil {
+		nodeGroupCreateInput.InstanceTypes = aws.StringSlice(GetNodeGroupInstanceTypes(config, group))
+	}
+
+	if len(group.Subnets) != 0 {
+		nodeGroupCreateInput.Subnets = aws.StringSlice(group.Subnets)
+	} else {
+		nodeGroupCreateInput.Subnets = aws.StringSlice(config.Status.Subnets)
+	}
+
+	if aws.StringValue(group.NodeRole) != "" {
+		nodeGroupCreateInput.NodeRole = group.NodeRole
+	}
+
+	return nodeGroupCreateInput
+}
+
+func CreateNewLaunchTemplateVersion(ec2Service services.EC2ServiceInterface, launchTemplateID, clusterSecurityGroupID string, group eksv1.NodeGroup) (*eksv1.LaunchTemplate, error) {
+	launchTemplate, err := buildLaunchTemplateData(ec2Service, clusterSecurityGroupID, group)
 	if err != nil {
 		return nil, err
 	}
@@ -342,7 +356,7 @@ func CreateNewLaunchTemplateVersion(ec2Service services.EC2ServiceInterface, lau
 	}, nil
 }
 
-func buildLaunchTemplateData(ec2Service services.EC2ServiceInterface, group eksv1.NodeGroup) (*ec2.RequestLaunchTemplateData, error) {
+func buildLaunchTemplateData(ec2Service services.EC2ServiceInterface, clusterSecurityGroupID string, group eksv1.NodeGroup) (*ec2.RequestLaunchTemplateData, error) {
 	var imageID *string
 	if aws.StringValue(group.ImageID) != "" {
 		imageID = group.ImageID
@@ -350,34 +364,39 @@ func buildLaunchTemplateData(ec2Service services.EC2ServiceInterface, group eksv
 
 	userdata := group.UserData
 	if aws.StringValue(userdata) != "" {
-		if !strings.Contains(*userdata, "Content-Type: multipart/mixed") {
-			return nil, fmt.Errorf("userdata for nodegroup [%s] is not of mime time multipart/mixed", aws.StringValue(group.NodegroupName))
+		if err := validateUserData(group, *userdata); err != nil {
+			return nil, err
 		}
 		*userdata = base64.StdEncoding.EncodeToString([]byte(*userdata))
 	}
 
-	deviceName := aws.String(defaultStorageDeviceName)
-	if aws.StringValue(group.ImageID) != "" {
+	deviceName := aws.String(getDefaultStorageDeviceName(aws.StringValue(GetAMIType(group))))
+	// without an EC2 service, as when rendering offline, the default device name is assumed
+	if aws.StringValue(group.ImageID) != "" && ec2Service != nil {
 		if rootDeviceName, err := getImageRootDeviceName(ec2Service, group.ImageID); err != nil {
 			return nil, err
 		} else if rootDeviceName != nil {
 			deviceName = rootDeviceName
 		}
+		if err := validateImageRootDeviceMappings(group, aws.StringValue(deviceName)); err != nil {
+			return nil, err
+		}
+	}
+
+	securityGroups, networkInterfaces, err := buildNetworkConfig(group, clusterSecurityGroupID)
+	if err != nil {
+		return nil, err
 	}
 
 	launchTemplateData := &ec2.RequestLaunchTemplateData{
-		ImageId:  imageID,
-		KeyName:  group.Ec2SshKey,
-		UserData: userdata,
-		BlockDeviceMappings: []*ec2.LaunchTemplateBlockDeviceMappingRequest{
-			{
-				DeviceName: deviceName,
-				Ebs: &ec2.LaunchTemplateEbsBlockDeviceRequest{
-					VolumeSize: group.DiskSize,
-				},
-			},
-		},
-		TagSpecifications: utils.CreateTagSpecs(group.ResourceTags),
+		ImageId:             imageID,
+		KeyName:             group.Ec2SshKey,
+		UserData:            userdata,
+		BlockDeviceMappings: buildBlockDeviceMappings(group, deviceName),
+		MetadataOptions:     buildMetadataOptions(group),
+		SecurityGroupIds:    securityGroups,
+		NetworkInterfaces:   networkInterfaces,
+		TagSpecifications:   utils.CreateTagSpecs(group.ResourceTags),
 	}
 	if !aws.BoolValue(group.RequestSpotInstances) {
 		launchTemplateData.InstanceType = group.InstanceType
@@ -401,6 +420,12 @@ func getImageRootDeviceName(ec2Service services.EC2ServiceInterface, imageID *st
 	return describeOutput.Images[0].RootDeviceName, nil
 }
 
+// IsIPv6Cluster returns true if the cluster assigns IPv6 addresses to pods and services.
+func IsIPv6Cluster(config *eksv1.EKSClusterConfig) bool {
+	return config.Spec.KubernetesNetworkConfig != nil &&
+		aws.StringValue(config.Spec.KubernetesNetworkConfig.IPFamily) == eks.IpFamilyIpv6
+}
+
 func getTags(tags map[string]string) map[string]*string {
 	if len(tags) == 0 {
 		return nil
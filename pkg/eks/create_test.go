@@ -133,6 +133,25 @@ var _ = Describe("newClusterInput", func() {
 
 		Expect(clusterInput.EncryptionConfig).To(BeNil())
 	})
+
+	It("should successfully create a cluster input with kubernetes network config", func() {
+		config.Spec.KubernetesNetworkConfig = &eksv1.KubernetesNetworkConfig{
+			IPFamily: aws.String(eks.IpFamilyIpv6),
+		}
+		clusterInput := newClusterInput(config, roleARN)
+		Expect(clusterInput).ToNot(BeNil())
+
+		Expect(clusterInput.KubernetesNetworkConfig).ToNot(BeNil())
+		Expect(clusterInput.KubernetesNetworkConfig.IpFamily).To(Equal(aws.String(eks.IpFamilyIpv6)))
+		Expect(clusterInput.KubernetesNetworkConfig.ServiceIpv4Cidr).To(BeNil())
+	})
+
+	It("should successfully create a cluster input with no kubernetes network config set", func() {
+		clusterInput := newClusterInput(config, roleARN)
+		Expect(clusterInput).ToNot(BeNil())
+
+		Expect(clusterInput.KubernetesNetworkConfig).To(BeNil())
+	})
 })
 
 var _ = Describe("CreateStack", func() {
@@ -534,7 +553,7 @@ var _ = Describe("buildLaunchTemplateData", func() {
 			},
 			nil)
 
-		launchTemplateData, err := buildLaunchTemplateData(ec2ServiceMock, *group)
+		launchTemplateData, err := buildLaunchTemplateData(ec2ServiceMock, "", *group)
 		Expect(err).ToNot(HaveOccurred())
 
 		Expect(launchTemplateData).ToNot(BeNil())
@@ -546,17 +565,49 @@ var _ = Describe("buildLaunchTemplateData", func() {
 		Expect(launchTemplateData.BlockDeviceMappings[0].Ebs.VolumeSize).To(Equal(group.DiskSize))
 		Expect(launchTemplateData.TagSpecifications).To(Equal(utils.CreateTagSpecs(group.ResourceTags)))
 		Expect(launchTemplateData.InstanceType).To(Equal(group.InstanceType))
+		Expect(launchTemplateData.MetadataOptions.HttpTokens).To(Equal(aws.String(ec2.LaunchTemplateHttpTokensStateRequired)))
+	})
+
+	It("should map the storage device settings to the root device of the image", func() {
+		ec2ServiceMock.EXPECT().DescribeImages(gomock.Any()).Return(&ec2.DescribeImagesOutput{
+			Images: []*ec2.Image{{RootDeviceName: aws.String("/dev/sda1")}},
+		}, nil).Times(2)
+		group.UserData = nil
+		group.BlockDeviceMappings = []eksv1.BlockDeviceMapping{{DeviceName: aws.String("/dev/sda1"), Encrypted: aws.Bool(true)}}
+
+		launchTemplateData, err := buildLaunchTemplateData(ec2ServiceMock, "", *group)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(launchTemplateData.BlockDeviceMappings).To(HaveLen(1))
+		Expect(launchTemplateData.BlockDeviceMappings[0].DeviceName).To(Equal(aws.String("/dev/sda1")))
+		Expect(launchTemplateData.BlockDeviceMappings[0].Ebs.Encrypted).To(Equal(aws.Bool(true)))
+		Expect(launchTemplateData.BlockDeviceMappings[0].Ebs.VolumeSize).To(Equal(group.DiskSize))
+
+		group.BlockDeviceMappings[0].DeviceName = aws.String("/dev/sdb")
+		_, err = buildLaunchTemplateData(ec2ServiceMock, "", *group)
+		Expect(err).To(MatchError(ContainSubstring("device [/dev/sdb] is not the root device [/dev/sda1] of image [test-ami]")))
 	})
 
 	It("should fail to build a launch template data if userdata is invalid", func() {
 		group.UserData = aws.String("invalid-user-data")
-		_, err := buildLaunchTemplateData(ec2ServiceMock, *group)
+		_, err := buildLaunchTemplateData(ec2ServiceMock, "", *group)
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("should build a launch template data for bottlerocket", func() {
+		group.ImageID = nil
+		group.AMIType = aws.String(eks.AMITypesBottlerocketArm64)
+		group.UserData = aws.String("[settings.kubernetes]")
+		launchTemplateData, err := buildLaunchTemplateData(ec2ServiceMock, "", *group)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(launchTemplateData.BlockDeviceMappings).To(HaveLen(1))
+		Expect(launchTemplateData.BlockDeviceMappings[0].DeviceName).To(Equal(aws.String(bottlerocketStorageDeviceName)))
+		Expect(launchTemplateData.UserData).To(Equal(aws.String("W3NldHRpbmdzLmt1YmVybmV0ZXNd")))
+	})
+
 	It("should fail to build a launch template data if error is return by ec2", func() {
 		ec2ServiceMock.EXPECT().DescribeImages(gomock.Any()).Return(nil, errors.New("error"))
-		_, err := buildLaunchTemplateData(ec2ServiceMock, *group)
+		_, err := buildLaunchTemplateData(ec2ServiceMock, "", *group)
 		Expect(err).To(HaveOccurred())
 	})
 })
@@ -585,7 +636,7 @@ var _ = Describe("createNewLaunchTemplateVersion", func() {
 	})
 
 	It("should create a new launch template", func() {
-		input, err := buildLaunchTemplateData(ec2ServiceMock, *group)
+		input, err := buildLaunchTemplateData(ec2ServiceMock, "", *group)
 		Expect(err).ToNot(HaveOccurred())
 
 		output := &ec2.CreateLaunchTemplateVersionOutput{
@@ -601,7 +652,7 @@ var _ = Describe("createNewLaunchTemplateVersion", func() {
 			LaunchTemplateId:   aws.String(templateID),
 		}).Return(output, nil)
 
-		launchTemplate, err := CreateNewLaunchTemplateVersion(ec2ServiceMock, templateID, *group)
+		launchTemplate, err := CreateNewLaunchTemplateVersion(ec2ServiceMock, templateID, "", *group)
 		Expect(err).ToNot(HaveOccurred())
 
 		Expect(launchTemplate.Name).To(Equal(output.LaunchTemplateVersion.LaunchTemplateName))
@@ -611,7 +662,7 @@ var _ = Describe("createNewLaunchTemplateVersion", func() {
 
 	It("should fail to create a new launch template if error is returned by ec2", func() {
 		ec2ServiceMock.EXPECT().CreateLaunchTemplateVersion(gomock.Any()).Return(nil, errors.New("error"))
-		_, err := CreateNewLaunchTemplateVersion(ec2ServiceMock, templateID, *group)
+		_, err := CreateNewLaunchTemplateVersion(ec2ServiceMock, templateID, "", *group)
 		Expect(err).To(HaveOccurred())
 	})
 })
@@ -675,6 +726,11 @@ var _ = Describe("CreateNodeGroup", func() {
 						},
 					},
 				},
+				MetadataOptions: &ec2.LaunchTemplateInstanceMetadataOptionsRequest{
+					HttpTokens:              aws.String(ec2.LaunchTemplateHttpTokensStateRequired),
+					HttpPutResponseHopLimit: aws.Int64(2),
+					HttpEndpoint:            aws.String(ec2.LaunchTemplateInstanceMetadataEndpointStateEnabled),
+				},
 				TagSpecifications: utils.CreateTagSpecs(createNodeGroupOpts.NodeGroup.ResourceTags),
 			},
 			LaunchTemplateId: aws.String(createNodeGroupOpts.Config.Status.ManagedLaunchTemplateID),
@@ -1,15 +1,38 @@
 package eks
 
 import (
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
 	"github.com/rancher/eks-operator/pkg/eks/services"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// DeletionPolicyDelete deletes the cluster and the resources created for it when the config is removed.
+	DeletionPolicyDelete = "Delete"
+	// DeletionPolicyRetain leaves the cluster and all its resources in AWS when the config is removed.
+	DeletionPolicyRetain = "Retain"
+	// DeletionPolicyRetainNetwork deletes the cluster but keeps the VPC stack created for it when the config is
+	// removed.
+	DeletionPolicyRetainNetwork = "RetainNetwork"
+)
+
+// ValidateDeletionPolicy checks the deletion policy of the config.
+func ValidateDeletionPolicy(spec eksv1.EKSClusterConfigSpec) error {
+	switch deletionPolicy := aws.StringValue(spec.DeletionPolicy); deletionPolicy {
+	case "", DeletionPolicyDelete, DeletionPolicyRetain, DeletionPolicyRetainNetwork:
+		return nil
+	default:
+		return fmt.Errorf("invalid deletionPolicy [%s], valid values are [%s, %s, %s]", deletionPolicy,
+			DeletionPolicyDelete, DeletionPolicyRetain, DeletionPolicyRetainNetwork)
+	}
+}
+
 func DeleteLaunchTemplateVersions(ec2Service services.EC2ServiceInterface, templateID string, templateVersions []*string) {
 	launchTemplateDeleteVersionInput := &ec2.DeleteLaunchTemplateVersionsInput{
 		LaunchTemplateId: aws.String(templateID),
@@ -5,6 +5,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
 	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
 )
 
@@ -35,3 +37,16 @@ var _ = Describe("deleteLaunchTemplateVersions", func() {
 		DeleteLaunchTemplateVersions(ec2ServiceMock, templateID, templateVersions)
 	})
 })
+
+var _ = Describe("ValidateDeletionPolicy", func() {
+	It("should accept the supported deletion policies", func() {
+		Expect(ValidateDeletionPolicy(eksv1.EKSClusterConfigSpec{})).To(Succeed())
+		for _, deletionPolicy := range []string{DeletionPolicyDelete, DeletionPolicyRetain, DeletionPolicyRetainNetwork} {
+			Expect(ValidateDeletionPolicy(eksv1.EKSClusterConfigSpec{DeletionPolicy: aws.String(deletionPolicy)})).To(Succeed())
+		}
+	})
+
+	It("should reject unknown deletion policies", func() {
+		Expect(ValidateDeletionPolicy(eksv1.EKSClusterConfigSpec{DeletionPolicy: aws.String("Orphan")})).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,273 @@
+package eks
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services"
+)
+
+const (
+	// DefaultMaxInstanceTypes is the number of matching instance types used by a node group with instance
+	// requirements that do not set MaxInstanceTypes, EKS limits the number of instance types of a node group.
+	DefaultMaxInstanceTypes = 20
+
+	// describeInstanceTypesLimit is the maximum number of instance types described by a single request.
+	describeInstanceTypesLimit = 100
+)
+
+// ValidateInstanceRequirements checks that the instance requirements of the node group can be resolved and are
+// not combined with instance types, which they replace.
+func ValidateInstanceRequirements(group eksv1.NodeGroup) error {
+	requirements := group.InstanceRequirements
+	if requirements == nil {
+		return nil
+	}
+
+	name := aws.StringValue(group.NodegroupName)
+	if aws.StringValue(group.InstanceType) != "" || len(group.SpotInstanceTypes) != 0 {
+		return fmt.Errorf("nodegroup [%s]: instanceRequirements cannot be used with instanceType or spotInstanceTypes", name)
+	}
+	if group.LaunchTemplate != nil {
+		return fmt.Errorf("nodegroup [%s]: instanceRequirements can only be used with the launch template managed by the operator", name)
+	}
+	if group.Remediation != nil && len(group.Remediation.FallbackInstanceTypes) != 0 {
+		return fmt.Errorf("nodegroup [%s]: fallbackInstanceTypes cannot be used with instanceRequirements", name)
+	}
+	if err := validateInstanceRequirementsRange(requirements.VCPUCount, "vcpuCount"); err != nil {
+		return fmt.Errorf("nodegroup [%s]: instanceRequirements: %w", name, err)
+	}
+	if err := validateInstanceRequirementsRange(requirements.MemoryMiB, "memoryMiB"); err != nil {
+		return fmt.Errorf("nodegroup [%s]: instanceRequirements: %w", name, err)
+	}
+	if err := validateValues(requirements.InstanceGenerations, ec2.InstanceGeneration_Values(), "instanceGenerations"); err != nil {
+		return fmt.Errorf("nodegroup [%s]: instanceRequirements: %w", name, err)
+	}
+	if err := validateValues(requirements.ArchitectureTypes, []string{ec2.ArchitectureTypeArm64, ec2.ArchitectureTypeX8664}, "architectureTypes"); err != nil {
+		return fmt.Errorf("nodegroup [%s]: instanceRequirements: %w", name, err)
+	}
+	if err := validateValues(requirements.CPUManufacturers, ec2.CpuManufacturer_Values(), "cpuManufacturers"); err != nil {
+		return fmt.Errorf("nodegroup [%s]: instanceRequirements: %w", name, err)
+	}
+	if burstable := aws.StringValue(requirements.BurstablePerformance); burstable != "" {
+		if err := validateValues([]string{burstable}, ec2.BurstablePerformance_Values(), "burstablePerformance"); err != nil {
+			return fmt.Errorf("nodegroup [%s]: instanceRequirements: %w", name, err)
+		}
+	}
+	if requirements.MaxInstanceTypes != nil && aws.Int64Value(requirements.MaxInstanceTypes) < 1 {
+		return fmt.Errorf("nodegroup [%s]: instanceRequirements: maxInstanceTypes must be at least 1", name)
+	}
+
+	architecture := getAMITypeArchitecture(aws.StringValue(GetAMIType(group)))
+	for _, architectureType := range requirements.ArchitectureTypes {
+		if architecture != "" && architectureType != architecture {
+			return fmt.Errorf("nodegroup [%s]: instanceRequirements: architecture type [%s] does not match amiType [%s]",
+				name, architectureType, aws.StringValue(GetAMIType(group)))
+		}
+	}
+
+	return nil
+}
+
+func validateInstanceRequirementsRange(requirementsRange *eksv1.InstanceRequirementsRange, field string) error {
+	if requirementsRange == nil || requirementsRange.Min == nil {
+		return fmt.Errorf("%s.min must be specified", field)
+	}
+	if aws.Int64Value(requirementsRange.Min) < 0 {
+		return fmt.Errorf("%s.min cannot be negative", field)
+	}
+	if requirementsRange.Max != nil && aws.Int64Value(requirementsRange.Max) < aws.Int64Value(requirementsRange.Min) {
+		return fmt.Errorf("%s.max cannot be lower than %s.min", field, field)
+	}
+
+	return nil
+}
+
+func validateValues(values, validValues []string, field string) error {
+	for _, value := range values {
+		valid := false
+		for _, validValue := range validValues {
+			valid = valid || value == validValue
+		}
+		if !valid {
+			return fmt.Errorf("invalid %s value [%s], valid values are [%s]", field, value, strings.Join(validValues, ", "))
+		}
+	}
+
+	return nil
+}
+
+type ResolveInstanceRequirementsOpts struct {
+	EC2Service services.EC2ServiceInterface
+	NodeGroup  eksv1.NodeGroup
+}
+
+// ResolveInstanceRequirements returns the instance types matching the instance requirements of the node group,
+// limited to MaxInstanceTypes, and the matching instance types left out by the limit. EKS launches on-demand
+// instances in the order of the instance types of the node group, so they are sorted by vCPUs and memory, the
+// instance types closest to the minimum requirements first.
+func ResolveInstanceRequirements(opts *ResolveInstanceRequirementsOpts) ([]string, []string, error) {
+	group := opts.NodeGroup
+	requirements := group.InstanceRequirements
+	nodegroupName := aws.StringValue(group.NodegroupName)
+
+	architectureTypes := requirements.ArchitectureTypes
+	if len(architectureTypes) == 0 {
+		architectureTypes = []string{ec2.ArchitectureTypeX8664}
+		if architecture := getAMITypeArchitecture(aws.StringValue(GetAMIType(group))); architecture != "" {
+			architectureTypes = []string{architecture}
+		}
+	}
+
+	request := &ec2.InstanceRequirementsRequest{
+		VCpuCount: &ec2.VCpuCountRangeRequest{
+			Min: requirements.VCPUCount.Min,
+			Max: requirements.VCPUCount.Max,
+		},
+		MemoryMiB: &ec2.MemoryMiBRequest{
+			Min: requirements.MemoryMiB.Min,
+			Max: requirements.MemoryMiB.Max,
+		},
+		BurstablePerformance: requirements.BurstablePerformance,
+	}
+	if len(requirements.InstanceGenerations) != 0 {
+		request.InstanceGenerations = aws.StringSlice(requirements.InstanceGenerations)
+	}
+	if len(requirements.CPUManufacturers) != 0 {
+		request.CpuManufacturers = aws.StringSlice(requirements.CPUManufacturers)
+	}
+	if len(requirements.ExcludedInstanceTypes) != 0 {
+		request.ExcludedInstanceTypes = aws.StringSlice(requirements.ExcludedInstanceTypes)
+	}
+
+	input := &ec2.GetInstanceTypesFromInstanceRequirementsInput{
+		ArchitectureTypes:    aws.StringSlice(architectureTypes),
+		VirtualizationTypes:  aws.StringSlice([]string{ec2.VirtualizationTypeHvm}),
+		InstanceRequirements: request,
+	}
+	var instanceTypes []string
+	for {
+		output, err := opts.EC2Service.GetInstanceTypesFromInstanceRequirements(input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error resolving instance requirements of nodegroup [%s]: %w", nodegroupName, err)
+		}
+		for _, instanceType := range output.InstanceTypes {
+			instanceTypes = append(instanceTypes, aws.StringValue(instanceType.InstanceType))
+		}
+		if aws.StringValue(output.NextToken) == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	if len(instanceTypes) == 0 {
+		return nil, nil, fmt.Errorf("no instance type matches the instance requirements of nodegroup [%s]", nodegroupName)
+	}
+
+	sizes, err := getInstanceTypeSizes(opts.EC2Service, instanceTypes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error describing instance types matching the instance requirements of nodegroup [%s]: %w", nodegroupName, err)
+	}
+	sort.Slice(instanceTypes, func(i, j int) bool {
+		a, b := sizes[instanceTypes[i]], sizes[instanceTypes[j]]
+		if a.vcpus != b.vcpus {
+			return a.vcpus < b.vcpus
+		}
+		if a.memoryMiB != b.memoryMiB {
+			return a.memoryMiB < b.memoryMiB
+		}
+		return instanceTypes[i] < instanceTypes[j]
+	})
+
+	maxInstanceTypes := int64(DefaultMaxInstanceTypes)
+	if requirements.MaxInstanceTypes != nil {
+		maxInstanceTypes = aws.Int64Value(requirements.MaxInstanceTypes)
+	}
+	if int64(len(instanceTypes)) > maxInstanceTypes {
+		return instanceTypes[:maxInstanceTypes], instanceTypes[maxInstanceTypes:], nil
+	}
+
+	return instanceTypes, nil, nil
+}
+
+type instanceTypeSize struct {
+	vcpus     int64
+	memoryMiB int64
+}
+
+// getInstanceTypeSizes returns the default vCPUs and the memory of the instance types by name.
+func getInstanceTypeSizes(ec2Service services.EC2ServiceInterface, instanceTypes []string) (map[string]instanceTypeSize, error) {
+	sizes := make(map[string]instanceTypeSize, len(instanceTypes))
+	for start := 0; start < len(instanceTypes); start += describeInstanceTypesLimit {
+		end := start + describeInstanceTypesLimit
+		if end > len(instanceTypes) {
+			end = len(instanceTypes)
+		}
+		input := &ec2.DescribeInstanceTypesInput{
+			InstanceTypes: aws.StringSlice(instanceTypes[start:end]),
+		}
+		for {
+			output, err := ec2Service.DescribeInstanceTypes(input)
+			if err != nil {
+				return nil, err
+			}
+			for _, instanceType := range output.InstanceTypes {
+				var size instanceTypeSize
+				if instanceType.VCpuInfo != nil {
+					size.vcpus = aws.Int64Value(instanceType.VCpuInfo.DefaultVCpus)
+				}
+				if instanceType.MemoryInfo != nil {
+					size.memoryMiB = aws.Int64Value(instanceType.MemoryInfo.SizeInMiB)
+				}
+				sizes[aws.StringValue(instanceType.InstanceType)] = size
+			}
+			if aws.StringValue(output.NextToken) == "" {
+				break
+			}
+			input.NextToken = output.NextToken
+		}
+	}
+
+	return sizes, nil
+}
+
+// InstanceRequirementsResolved returns true if the instance requirements of the node group were resolved to the
+// instance types recorded in its status.
+func InstanceRequirementsResolved(config *eksv1.EKSClusterConfig, group eksv1.NodeGroup) bool {
+	status := getNodeGroupStatus(config, aws.StringValue(group.NodegroupName))
+	return status != nil && len(status.ResolvedInstanceTypes) != 0 &&
+		reflect.DeepEqual(status.ResolvedInstanceRequirements, group.InstanceRequirements)
+}
+
+// GetNodeGroupInstanceTypes returns the instance types the node group is created with: the instance types
+// resolved from its instance requirements, its spot instance types or its instance type.
+func GetNodeGroupInstanceTypes(config *eksv1.EKSClusterConfig, group eksv1.NodeGroup) []string {
+	if group.InstanceRequirements != nil {
+		if status := getNodeGroupStatus(config, aws.StringValue(group.NodegroupName)); status != nil {
+			return status.ResolvedInstanceTypes
+		}
+		return nil
+	}
+	if aws.BoolValue(group.RequestSpotInstances) {
+		return aws.StringValueSlice(group.SpotInstanceTypes)
+	}
+	if instanceType := aws.StringValue(group.InstanceType); instanceType != "" {
+		return []string{instanceType}
+	}
+
+	return nil
+}
+
+func getNodeGroupStatus(config *eksv1.EKSClusterConfig, nodegroupName string) *eksv1.NodeGroupStatus {
+	for i := range config.Status.NodeGroups {
+		if config.Status.NodeGroups[i].Name == nodegroupName {
+			return &config.Status.NodeGroups[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package eks
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+)
+
+var _ = Describe("ValidateInstanceRequirements", func() {
+	var group eksv1.NodeGroup
+
+	BeforeEach(func() {
+		group = eksv1.NodeGroup{
+			NodegroupName: aws.String("ng"),
+			InstanceRequirements: &eksv1.InstanceRequirements{
+				VCPUCount: &eksv1.InstanceRequirementsRange{Min: aws.Int64(2), Max: aws.Int64(4)},
+				MemoryMiB: &eksv1.InstanceRequirementsRange{Min: aws.Int64(4096)},
+			},
+		}
+	})
+
+	It("should accept instance requirements with vcpu and memory ranges", func() {
+		group.InstanceRequirements.InstanceGenerations = []string{ec2.InstanceGenerationCurrent}
+		group.InstanceRequirements.ArchitectureTypes = []string{ec2.ArchitectureTypeX8664}
+		Expect(ValidateInstanceRequirements(group)).To(Succeed())
+	})
+
+	It("should reject instance requirements with instance types", func() {
+		group.RequestSpotInstances = aws.Bool(true)
+		group.SpotInstanceTypes = aws.StringSlice([]string{"m5.large"})
+		Expect(ValidateInstanceRequirements(group)).To(MatchError(ContainSubstring("cannot be used with instanceType or spotInstanceTypes")))
+	})
+
+	It("should reject missing and inverted ranges", func() {
+		group.InstanceRequirements.MemoryMiB = nil
+		Expect(ValidateInstanceRequirements(group)).To(MatchError(ContainSubstring("memoryMiB.min must be specified")))
+
+		group.InstanceRequirements.MemoryMiB = &eksv1.InstanceRequirementsRange{Min: aws.Int64(4096)}
+		group.InstanceRequirements.VCPUCount.Max = aws.Int64(1)
+		Expect(ValidateInstanceRequirements(group)).To(MatchError(ContainSubstring("vcpuCount.max cannot be lower than vcpuCount.min")))
+	})
+
+	It("should reject architecture types not matching the ami type", func() {
+		group.AMIType = aws.String(eks.AMITypesAl2Arm64)
+		group.InstanceRequirements.ArchitectureTypes = []string{ec2.ArchitectureTypeX8664}
+		Expect(ValidateInstanceRequirements(group)).To(MatchError(ContainSubstring("architecture type [x86_64] does not match amiType [AL2_ARM_64]")))
+	})
+})
+
+var _ = Describe("ResolveInstanceRequirements", func() {
+	var (
+		mockController                  *gomock.Controller
+		ec2ServiceMock                  *mock_services.MockEC2ServiceInterface
+		resolveInstanceRequirementsOpts *ResolveInstanceRequirementsOpts
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		ec2ServiceMock = mock_services.NewMockEC2ServiceInterface(mockController)
+		resolveInstanceRequirementsOpts = &ResolveInstanceRequirementsOpts{
+			EC2Service: ec2ServiceMock,
+			NodeGroup: eksv1.NodeGroup{
+				NodegroupName: aws.String("ng"),
+				AMIType:       aws.String(eks.AMITypesAl2Arm64),
+				InstanceRequirements: &eksv1.InstanceRequirements{
+					VCPUCount:           &eksv1.InstanceRequirementsRange{Min: aws.Int64(2), Max: aws.Int64(4)},
+					MemoryMiB:           &eksv1.InstanceRequirementsRange{Min: aws.Int64(4096)},
+					InstanceGenerations: []string{ec2.InstanceGenerationCurrent},
+					MaxInstanceTypes:    aws.Int64(2),
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should return the smallest matching instance types first and the ones beyond the maximum", func() {
+		ec2ServiceMock.EXPECT().GetInstanceTypesFromInstanceRequirements(&ec2.GetInstanceTypesFromInstanceRequirementsInput{
+			ArchitectureTypes:   aws.StringSlice([]string{ec2.ArchitectureTypeArm64}),
+			VirtualizationTypes: aws.StringSlice([]string{ec2.VirtualizationTypeHvm}),
+			InstanceRequirements: &ec2.InstanceRequirementsRequest{
+				VCpuCount:           &ec2.VCpuCountRangeRequest{Min: aws.Int64(2), Max: aws.Int64(4)},
+				MemoryMiB:           &ec2.MemoryMiBRequest{Min: aws.Int64(4096)},
+				InstanceGenerations: aws.StringSlice([]string{ec2.InstanceGenerationCurrent}),
+			},
+		}).Return(&ec2.GetInstanceTypesFromInstanceRequirementsOutput{
+			InstanceTypes: []*ec2.InstanceTypeInfoFromInstanceRequirements{
+				{InstanceType: aws.String("m6g.large")},
+				{InstanceType: aws.String("c6g.xlarge")},
+			},
+			NextToken: aws.String("token"),
+		}, nil)
+		ec2ServiceMock.EXPECT().GetInstanceTypesFromInstanceRequirements(gomock.Any()).Return(&ec2.GetInstanceTypesFromInstanceRequirementsOutput{
+			InstanceTypes: []*ec2.InstanceTypeInfoFromInstanceRequirements{
+				{InstanceType: aws.String("c6g.large")},
+			},
+		}, nil)
+
+		ec2ServiceMock.EXPECT().DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+			InstanceTypes: aws.StringSlice([]string{"m6g.large", "c6g.xlarge", "c6g.large"}),
+		}).Return(&ec2.DescribeInstanceTypesOutput{
+			InstanceTypes: []*ec2.InstanceTypeInfo{
+				instanceTypeInfo("m6g.large", 2, 8192),
+				instanceTypeInfo("c6g.xlarge", 4, 8192),
+				instanceTypeInfo("c6g.large", 2, 4096),
+			},
+		}, nil)
+
+		instanceTypes, droppedInstanceTypes, err := ResolveInstanceRequirements(resolveInstanceRequirementsOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instanceTypes).To(Equal([]string{"c6g.large", "m6g.large"}))
+		Expect(droppedInstanceTypes).To(Equal([]string{"c6g.xlarge"}))
+	})
+
+	It("should fail when no instance type matches", func() {
+		ec2ServiceMock.EXPECT().GetInstanceTypesFromInstanceRequirements(gomock.Any()).Return(&ec2.GetInstanceTypesFromInstanceRequirementsOutput{}, nil)
+
+		_, _, err := ResolveInstanceRequirements(resolveInstanceRequirementsOpts)
+		Expect(err).To(MatchError(ContainSubstring("no instance type matches the instance requirements of nodegroup [ng]")))
+	})
+})
+
+var _ = Describe("GetNodeGroupInstanceTypes", func() {
+	It("should return the resolved instance types of node groups with instance requirements", func() {
+		requirements := &eksv1.InstanceRequirements{
+			VCPUCount: &eksv1.InstanceRequirementsRange{Min: aws.Int64(2)},
+			MemoryMiB: &eksv1.InstanceRequirementsRange{Min: aws.Int64(4096)},
+		}
+		config := &eksv1.EKSClusterConfig{
+			Status: eksv1.EKSClusterConfigStatus{
+				NodeGroups: []eksv1.NodeGroupStatus{
+					{
+						Name:                         "ng",
+						ResolvedInstanceRequirements: requirements.DeepCopy(),
+						ResolvedInstanceTypes:        []string{"m5.large", "m6i.large"},
+					},
+				},
+			},
+		}
+		group := eksv1.NodeGroup{NodegroupName: aws.String("ng"), InstanceRequirements: requirements}
+
+		Expect(InstanceRequirementsResolved(config, group)).To(BeTrue())
+		Expect(GetNodeGroupInstanceTypes(config, group)).To(Equal([]string{"m5.large", "m6i.large"}))
+		Expect(newNodegroupInput(config, group).InstanceTypes).To(Equal(aws.StringSlice([]string{"m5.large", "m6i.large"})))
+
+		group.InstanceRequirements = &eksv1.InstanceRequirements{
+			VCPUCount: &eksv1.InstanceRequirementsRange{Min: aws.Int64(4)},
+			MemoryMiB: &eksv1.InstanceRequirementsRange{Min: aws.Int64(4096)},
+		}
+		Expect(InstanceRequirementsResolved(config, group)).To(BeFalse())
+	})
+
+	It("should return the instance type or spot instance types of other node groups", func() {
+		config := &eksv1.EKSClusterConfig{}
+		Expect(GetNodeGroupInstanceTypes(config, eksv1.NodeGroup{InstanceType: aws.String("m5.large")})).To(Equal([]string{"m5.large"}))
+		Expect(GetNodeGroupInstanceTypes(config, eksv1.NodeGroup{
+			InstanceType:         aws.String("m5.large"),
+			RequestSpotInstances: aws.Bool(true),
+			SpotInstanceTypes:    aws.StringSlice([]string{"c5.large", "m5.large"}),
+		})).To(Equal([]string{"c5.large", "m5.large"}))
+	})
+})
+
+func instanceTypeInfo(instanceType string, vcpus, memoryMiB int64) *ec2.InstanceTypeInfo {
+	return &ec2.InstanceTypeInfo{
+		InstanceType: aws.String(instanceType),
+		VCpuInfo:     &ec2.VCpuInfo{DefaultVCpus: aws.Int64(vcpus)},
+		MemoryInfo:   &ec2.MemoryInfo{SizeInMiB: aws.Int64(memoryMiB)},
+	}
+}
@@ -0,0 +1,121 @@
+package eks
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services"
+	"github.com/rancher/eks-operator/templates"
+)
+
+const (
+	// DefaultKMSKeyDeletionWindow is the number of days a generated KMS key is kept after the removal of its cluster,
+	// unless the config sets another window.
+	DefaultKMSKeyDeletionWindow int64 = 30
+
+	minKMSKeyDeletionWindow int64 = 7
+	maxKMSKeyDeletionWindow int64 = 30
+)
+
+// GetKMSKeyDeletionWindow returns the number of days a generated KMS key is kept after the removal of its cluster.
+func GetKMSKeyDeletionWindow(spec eksv1.EKSClusterConfigSpec) int64 {
+	if spec.KmsKeyDeletionWindow == nil {
+		return DefaultKMSKeyDeletionWindow
+	}
+
+	return *spec.KmsKeyDeletionWindow
+}
+
+// ValidateKMSKeyDeletionWindow returns an error if the deletion window of the generated KMS key is not one
+// accepted by KMS.
+func ValidateKMSKeyDeletionWindow(spec eksv1.EKSClusterConfigSpec) error {
+	window := GetKMSKeyDeletionWindow(spec)
+	if window < minKMSKeyDeletionWindow || window > maxKMSKeyDeletionWindow {
+		return fmt.Errorf("kmsKeyDeletionWindow [%d] must be between [%d] and [%d] days", window, minKMSKeyDeletionWindow, maxKMSKeyDeletionWindow)
+	}
+
+	return nil
+}
+
+// NeedsGeneratedKMSKey returns true if secrets encryption is enabled without a KMS key and the key has not been
+// generated yet. Keys are only generated for owned clusters created by the operator, as they are deleted with them.
+func NeedsGeneratedKMSKey(config *eksv1.EKSClusterConfig) bool {
+	return aws.BoolValue(config.Spec.SecretsEncryption) && aws.StringValue(config.Spec.KmsKey) == "" &&
+		config.Status.GeneratedKmsKey == "" && canGenerateKMSKey(config)
+}
+
+// ValidateKMSKey returns an error if secrets encryption is enabled without a KMS key for a cluster the operator
+// does not generate keys for, that is an imported cluster or a cluster that is not owned. Clusters whose secrets
+// are already encrypted are not checked.
+func ValidateKMSKey(config *eksv1.EKSClusterConfig) error {
+	if !aws.BoolValue(config.Spec.SecretsEncryption) || aws.StringValue(config.Spec.KmsKey) != "" ||
+		config.Status.GeneratedKmsKey != "" || canGenerateKMSKey(config) {
+		return nil
+	}
+	if config.Status.UpstreamSpec != nil && config.Status.UpstreamSpec.SecretsEncryption {
+		return nil
+	}
+
+	return fmt.Errorf("kmsKey must be set to enable secretsEncryption of imported clusters and clusters that are not [%s]", OwnershipOwned)
+}
+
+func canGenerateKMSKey(config *eksv1.EKSClusterConfig) bool {
+	return !config.Spec.Imported && GetOwnership(config.Spec) == OwnershipOwned
+}
+
+// GetKMSKey returns the KMS key used to encrypt the secrets of the cluster, the one generated by the operator
+// if the config does not set one.
+func GetKMSKey(config *eksv1.EKSClusterConfig) string {
+	if kmsKey := aws.StringValue(config.Spec.KmsKey); kmsKey != "" {
+		return kmsKey
+	}
+
+	return config.Status.GeneratedKmsKey
+}
+
+type CreateKMSKeyOpts struct {
+	CloudFormationService services.CloudFormationServiceInterface
+	Config                *eksv1.EKSClusterConfig
+	StackName             string
+	// RoleARN is the role of the cluster, it is granted the use of the key
+	RoleARN string
+}
+
+// CreateKMSKey creates the stack of a customer managed KMS key, and its alias, to encrypt the secrets of the
+// cluster and returns the ARN of the key. The key is scheduled for deletion, after the deletion window of the
+// config at the time the key is created, when the stack is deleted.
+func CreateKMSKey(opts *CreateKMSKeyOpts) (string, error) {
+	output, err := CreateStack(&CreateStackOptions{
+		CloudFormationService: opts.CloudFormationService,
+		StackName:             opts.StackName,
+		DisplayName:           opts.Config.Spec.DisplayName,
+		TemplateBody:          templates.KMSKeyTemplate,
+		Parameters: []*cloudformation.Parameter{
+			{
+				ParameterKey:   aws.String("ClusterRoleArn"),
+				ParameterValue: aws.String(opts.RoleARN),
+			},
+			{
+				ParameterKey:   aws.String("AliasName"),
+				ParameterValue: aws.String(fmt.Sprintf("alias/%s-eks-secrets", opts.Config.Spec.DisplayName)),
+			},
+			{
+				ParameterKey:   aws.String("PendingWindowInDays"),
+				ParameterValue: aws.String(strconv.FormatInt(GetKMSKeyDeletionWindow(opts.Config.Spec), 10)),
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error creating kms key stack for cluster [%s]: %w", opts.Config.Name, err)
+	}
+
+	keyARN := getParameterValueFromOutput("KeyArn", output.Stacks[0].Outputs)
+	if keyARN == "" {
+		return "", fmt.Errorf("no KeyArn was returned for cluster [%s]", opts.Config.Name)
+	}
+
+	return keyARN, nil
+}
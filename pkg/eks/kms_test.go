@@ -0,0 +1,163 @@
+package eks
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+)
+
+var _ = Describe("ValidateKMSKeyDeletionWindow", func() {
+	It("should accept the default window", func() {
+		Expect(ValidateKMSKeyDeletionWindow(eksv1.EKSClusterConfigSpec{})).To(Succeed())
+		Expect(ValidateKMSKeyDeletionWindow(eksv1.EKSClusterConfigSpec{KmsKeyDeletionWindow: aws.Int64(7)})).To(Succeed())
+	})
+
+	It("should reject windows KMS does not accept", func() {
+		Expect(ValidateKMSKeyDeletionWindow(eksv1.EKSClusterConfigSpec{KmsKeyDeletionWindow: aws.Int64(6)})).ToNot(Succeed())
+		Expect(ValidateKMSKeyDeletionWindow(eksv1.EKSClusterConfigSpec{KmsKeyDeletionWindow: aws.Int64(31)})).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("GetKMSKey", func() {
+	It("should prefer the key of the spec to the generated one", func() {
+		config := &eksv1.EKSClusterConfig{
+			Spec: eksv1.EKSClusterConfigSpec{
+				SecretsEncryption: aws.Bool(true),
+			},
+		}
+		Expect(NeedsGeneratedKMSKey(config)).To(BeTrue())
+
+		config.Status.GeneratedKmsKey = "generated"
+		Expect(NeedsGeneratedKMSKey(config)).To(BeFalse())
+		Expect(GetKMSKey(config)).To(Equal("generated"))
+
+		config.Spec.KmsKey = aws.String("test")
+		Expect(GetKMSKey(config)).To(Equal("test"))
+	})
+})
+
+var _ = Describe("NeedsGeneratedKMSKey", func() {
+	It("should only generate keys for owned clusters created by the operator", func() {
+		config := &eksv1.EKSClusterConfig{
+			Spec: eksv1.EKSClusterConfigSpec{
+				SecretsEncryption: aws.Bool(true),
+				Ownership:         aws.String(OwnershipManaged),
+			},
+		}
+		Expect(NeedsGeneratedKMSKey(config)).To(BeFalse())
+
+		config.Spec.Imported = true
+		config.Spec.Ownership = aws.String(OwnershipOwned)
+		Expect(NeedsGeneratedKMSKey(config)).To(BeFalse())
+	})
+})
+
+var _ = Describe("ValidateKMSKey", func() {
+	var config *eksv1.EKSClusterConfig
+
+	BeforeEach(func() {
+		config = &eksv1.EKSClusterConfig{
+			Spec: eksv1.EKSClusterConfigSpec{
+				Imported:          true,
+				SecretsEncryption: aws.Bool(true),
+			},
+		}
+	})
+
+	It("should require a kms key to enable secrets encryption of imported clusters", func() {
+		Expect(ValidateKMSKey(config)).To(MatchError(ContainSubstring("kmsKey must be set")))
+
+		config.Spec.KmsKey = aws.String("test")
+		Expect(ValidateKMSKey(config)).To(Succeed())
+	})
+
+	It("should require a kms key for clusters that are not owned", func() {
+		config.Spec.Imported = false
+		config.Spec.Ownership = aws.String(OwnershipManaged)
+		Expect(ValidateKMSKey(config)).ToNot(Succeed())
+
+		config.Spec.Ownership = aws.String(OwnershipOwned)
+		Expect(ValidateKMSKey(config)).To(Succeed())
+	})
+
+	It("should not check clusters whose secrets are already encrypted", func() {
+		config.Status.UpstreamSpec = &eksv1.UpstreamSpec{SecretsEncryption: true}
+		Expect(ValidateKMSKey(config)).To(Succeed())
+
+		config.Status.UpstreamSpec = nil
+		config.Spec.SecretsEncryption = aws.Bool(false)
+		Expect(ValidateKMSKey(config)).To(Succeed())
+	})
+})
+
+var _ = Describe("CreateKMSKey", func() {
+	var (
+		mockController            *gomock.Controller
+		cloudFormationServiceMock *mock_services.MockCloudFormationServiceInterface
+		createKMSKeyOpts          *CreateKMSKeyOpts
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		cloudFormationServiceMock = mock_services.NewMockCloudFormationServiceInterface(mockController)
+		createKMSKeyOpts = &CreateKMSKeyOpts{
+			CloudFormationService: cloudFormationServiceMock,
+			Config: &eksv1.EKSClusterConfig{
+				Spec: eksv1.EKSClusterConfigSpec{
+					DisplayName:          "test",
+					KmsKeyDeletionWindow: aws.Int64(7),
+				},
+			},
+			StackName: "test-eks-kms-key",
+			RoleARN:   "arn:aws:iam::123456789012:role/test",
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should create the key stack and return the key ARN", func() {
+		cloudFormationServiceMock.EXPECT().CreateStack(gomock.Any()).DoAndReturn(
+			func(input *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
+				Expect(aws.StringValue(input.StackName)).To(Equal("test-eks-kms-key"))
+				Expect(input.Parameters).To(ContainElements(
+					&cloudformation.Parameter{ParameterKey: aws.String("ClusterRoleArn"), ParameterValue: aws.String("arn:aws:iam::123456789012:role/test")},
+					&cloudformation.Parameter{ParameterKey: aws.String("AliasName"), ParameterValue: aws.String("alias/test-eks-secrets")},
+					&cloudformation.Parameter{ParameterKey: aws.String("PendingWindowInDays"), ParameterValue: aws.String("7")},
+				))
+				return &cloudformation.CreateStackOutput{}, nil
+			})
+		cloudFormationServiceMock.EXPECT().DescribeStacks(gomock.Any()).Return(
+			&cloudformation.DescribeStacksOutput{
+				Stacks: []*cloudformation.Stack{
+					{
+						StackStatus: aws.String(createCompleteStatus),
+						Outputs: []*cloudformation.Output{
+							{
+								OutputKey:   aws.String("KeyArn"),
+								OutputValue: aws.String("arn:aws:kms:us-east-1:123456789012:key/test"),
+							},
+						},
+					},
+				},
+			}, nil)
+
+		keyARN, err := CreateKMSKey(createKMSKeyOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keyARN).To(Equal("arn:aws:kms:us-east-1:123456789012:key/test"))
+	})
+
+	It("should return error if the key stack failed to create", func() {
+		cloudFormationServiceMock.EXPECT().CreateStack(gomock.Any()).Return(nil, errors.New("error"))
+
+		_, err := CreateKMSKey(createKMSKeyOpts)
+		Expect(err).To(HaveOccurred())
+	})
+})
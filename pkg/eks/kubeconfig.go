@@ -0,0 +1,104 @@
+package eks
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// KubeconfigAuthExec authenticates with an exec plugin running aws eks get-token, this is the default.
+	KubeconfigAuthExec = "exec"
+	// KubeconfigAuthToken authenticates with a token generated by the operator, which is refreshed before it expires.
+	KubeconfigAuthToken = "token"
+
+	// TokenExpiration is how long EKS accepts a token after it was generated.
+	TokenExpiration = 15 * time.Minute
+
+	tokenPrefix          = "k8s-aws-v1."
+	clusterIDHeader      = "x-k8s-aws-id"
+	tokenPresignDuration = 60 * time.Second
+	execAPIVersion       = "client.authentication.k8s.io/v1beta1"
+)
+
+// ValidateKubeconfigAuth checks the kubeconfig auth style of the config.
+func ValidateKubeconfigAuth(spec eksv1.EKSClusterConfigSpec) error {
+	switch kubeconfigAuth := aws.StringValue(spec.KubeconfigAuth); kubeconfigAuth {
+	case "", KubeconfigAuthExec, KubeconfigAuthToken:
+		return nil
+	default:
+		return fmt.Errorf("invalid kubeconfigAuth [%s], valid values are [%s, %s]", kubeconfigAuth, KubeconfigAuthExec, KubeconfigAuthToken)
+	}
+}
+
+type GenerateTokenOpts struct {
+	STSService  services.STSServiceInterface
+	ClusterName string
+}
+
+// GenerateToken returns a bearer token for the cluster made from a presigned STS GetCallerIdentity request, the way
+// aws eks get-token does, and the time it expires.
+func GenerateToken(opts *GenerateTokenOpts) (string, time.Time, error) {
+	req, _ := opts.STSService.GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+	req.HTTPRequest.Header.Add(clusterIDHeader, opts.ClusterName)
+
+	signedAt := time.Now()
+	presignedURL, err := req.Presign(tokenPresignDuration)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error presigning token request for cluster [%s]: %w", opts.ClusterName, err)
+	}
+
+	token := tokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(presignedURL))
+	return token, signedAt.Add(TokenExpiration), nil
+}
+
+type GenerateKubeconfigOpts struct {
+	ClusterName string
+	Region      string
+	Endpoint    string
+	// CA is the base64 encoded certificate authority of the cluster
+	CA string
+	// Token authenticates the kubeconfig if set, otherwise it uses an exec plugin
+	Token string
+}
+
+// GenerateKubeconfig returns a kubeconfig for the cluster.
+func GenerateKubeconfig(opts *GenerateKubeconfigOpts) ([]byte, error) {
+	ca, err := base64.StdEncoding.DecodeString(opts.CA)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding certificate authority of cluster [%s]: %w", opts.ClusterName, err)
+	}
+
+	authInfo := &clientcmdapi.AuthInfo{}
+	if opts.Token != "" {
+		authInfo.Token = opts.Token
+	} else {
+		authInfo.Exec = &clientcmdapi.ExecConfig{
+			APIVersion:      execAPIVersion,
+			Command:         "aws",
+			Args:            []string{"--region", opts.Region, "eks", "get-token", "--cluster-name", opts.ClusterName, "--output", "json"},
+			InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+		}
+	}
+
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters[opts.ClusterName] = &clientcmdapi.Cluster{
+		Server:                   opts.Endpoint,
+		CertificateAuthorityData: ca,
+	}
+	kubeconfig.AuthInfos[opts.ClusterName] = authInfo
+	kubeconfig.Contexts[opts.ClusterName] = &clientcmdapi.Context{
+		Cluster:  opts.ClusterName,
+		AuthInfo: opts.ClusterName,
+	}
+	kubeconfig.CurrentContext = opts.ClusterName
+
+	return clientcmd.Write(*kubeconfig)
+}
@@ -0,0 +1,114 @@
+package eks
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var _ = Describe("ValidateKubeconfigAuth", func() {
+	It("should accept the supported auth styles", func() {
+		Expect(ValidateKubeconfigAuth(eksv1.EKSClusterConfigSpec{})).To(Succeed())
+		Expect(ValidateKubeconfigAuth(eksv1.EKSClusterConfigSpec{KubeconfigAuth: aws.String(KubeconfigAuthExec)})).To(Succeed())
+		Expect(ValidateKubeconfigAuth(eksv1.EKSClusterConfigSpec{KubeconfigAuth: aws.String(KubeconfigAuthToken)})).To(Succeed())
+	})
+
+	It("should reject unknown auth styles", func() {
+		Expect(ValidateKubeconfigAuth(eksv1.EKSClusterConfigSpec{KubeconfigAuth: aws.String("password")})).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("GenerateToken", func() {
+	var (
+		mockController *gomock.Controller
+		stsServiceMock *mock_services.MockSTSServiceInterface
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		stsServiceMock = mock_services.NewMockSTSServiceInterface(mockController)
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should generate a token from a presigned request", func() {
+		sess := session.Must(session.NewSession(&aws.Config{
+			Region:      aws.String("us-west-2"),
+			Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+		}))
+		stsServiceMock.EXPECT().GetCallerIdentityRequest(gomock.Any()).Return(sts.New(sess).GetCallerIdentityRequest(&sts.GetCallerIdentityInput{}))
+
+		token, expiration, err := GenerateToken(&GenerateTokenOpts{
+			STSService:  stsServiceMock,
+			ClusterName: "test-cluster",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expiration).To(BeTemporally("~", time.Now().Add(TokenExpiration), time.Minute))
+		Expect(token).To(HavePrefix("k8s-aws-v1."))
+
+		presignedURL, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, "k8s-aws-v1."))
+		Expect(err).NotTo(HaveOccurred())
+		parsedURL, err := url.Parse(string(presignedURL))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsedURL.Query().Get("Action")).To(Equal("GetCallerIdentity"))
+		Expect(parsedURL.Query().Get("X-Amz-SignedHeaders")).To(ContainSubstring("x-k8s-aws-id"))
+	})
+})
+
+var _ = Describe("GenerateKubeconfig", func() {
+	var generateKubeconfigOpts *GenerateKubeconfigOpts
+
+	BeforeEach(func() {
+		generateKubeconfigOpts = &GenerateKubeconfigOpts{
+			ClusterName: "test-cluster",
+			Region:      "us-west-2",
+			Endpoint:    "https://test.eks.amazonaws.com",
+			CA:          base64.StdEncoding.EncodeToString([]byte("test-ca")),
+		}
+	})
+
+	It("should generate a kubeconfig using the exec plugin", func() {
+		data, err := GenerateKubeconfig(generateKubeconfigOpts)
+		Expect(err).NotTo(HaveOccurred())
+
+		kubeconfig, err := clientcmd.Load(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kubeconfig.CurrentContext).To(Equal("test-cluster"))
+		Expect(kubeconfig.Clusters["test-cluster"].Server).To(Equal("https://test.eks.amazonaws.com"))
+		Expect(kubeconfig.Clusters["test-cluster"].CertificateAuthorityData).To(Equal([]byte("test-ca")))
+		Expect(kubeconfig.AuthInfos["test-cluster"].Token).To(BeEmpty())
+		Expect(kubeconfig.AuthInfos["test-cluster"].Exec.Command).To(Equal("aws"))
+		Expect(kubeconfig.AuthInfos["test-cluster"].Exec.Args).To(ContainElements("get-token", "test-cluster", "us-west-2"))
+	})
+
+	It("should generate a kubeconfig using a token", func() {
+		generateKubeconfigOpts.Token = "k8s-aws-v1.token"
+		data, err := GenerateKubeconfig(generateKubeconfigOpts)
+		Expect(err).NotTo(HaveOccurred())
+
+		kubeconfig, err := clientcmd.Load(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kubeconfig.AuthInfos["test-cluster"].Token).To(Equal("k8s-aws-v1.token"))
+		Expect(kubeconfig.AuthInfos["test-cluster"].Exec).To(BeNil())
+	})
+
+	It("should return error if the certificate authority is not base64 encoded", func() {
+		generateKubeconfigOpts.CA = "not base64"
+		_, err := GenerateKubeconfig(generateKubeconfigOpts)
+		Expect(err).To(HaveOccurred())
+	})
+})
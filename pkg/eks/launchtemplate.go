@@ -0,0 +1,294 @@
+package eks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services"
+	"github.com/rancher/eks-operator/utils"
+)
+
+const (
+	// defaultHTTPPutResponseHopLimit allows pods that do not use the host network to reach the instance metadata
+	// service, which adds a hop compared to processes running on the node.
+	defaultHTTPPutResponseHopLimit = 2
+	maxHTTPPutResponseHopLimit     = 64
+
+	sshPort = 22
+)
+
+// ValidateMetadataOptions checks the instance metadata options of a node group that uses the rancher-managed
+// launch template.
+func ValidateMetadataOptions(group eksv1.NodeGroup) error {
+	options := group.MetadataOptions
+	if options == nil {
+		return nil
+	}
+
+	name := aws.StringValue(group.NodegroupName)
+	if group.LaunchTemplate != nil {
+		return fmt.Errorf("nodegroup [%s]: metadataOptions cannot be used with a user provided launch template", name)
+	}
+
+	switch httpTokens := aws.StringValue(options.HTTPTokens); httpTokens {
+	case "", ec2.LaunchTemplateHttpTokensStateRequired, ec2.LaunchTemplateHttpTokensStateOptional:
+	default:
+		return fmt.Errorf("nodegroup [%s]: invalid httpTokens [%s], valid values are [%s, %s]", name, httpTokens,
+			ec2.LaunchTemplateHttpTokensStateRequired, ec2.LaunchTemplateHttpTokensStateOptional)
+	}
+
+	switch httpEndpoint := aws.StringValue(options.HTTPEndpoint); httpEndpoint {
+	case "", ec2.LaunchTemplateInstanceMetadataEndpointStateEnabled, ec2.LaunchTemplateInstanceMetadataEndpointStateDisabled:
+	default:
+		return fmt.Errorf("nodegroup [%s]: invalid httpEndpoint [%s], valid values are [%s, %s]", name, httpEndpoint,
+			ec2.LaunchTemplateInstanceMetadataEndpointStateEnabled, ec2.LaunchTemplateInstanceMetadataEndpointStateDisabled)
+	}
+
+	if hopLimit := options.HTTPPutResponseHopLimit; hopLimit != nil && (*hopLimit < 1 || *hopLimit > maxHTTPPutResponseHopLimit) {
+		return fmt.Errorf("nodegroup [%s]: httpPutResponseHopLimit must be between 1 and %d", name, maxHTTPPutResponseHopLimit)
+	}
+
+	return nil
+}
+
+// getMetadataOptions returns the metadata options of the node group with the unset fields defaulted. By default,
+// the metadata service is enabled and requires session tokens (IMDSv2).
+func getMetadataOptions(group eksv1.NodeGroup) eksv1.MetadataOptions {
+	options := eksv1.MetadataOptions{
+		HTTPTokens:              aws.String(ec2.LaunchTemplateHttpTokensStateRequired),
+		HTTPPutResponseHopLimit: aws.Int64(defaultHTTPPutResponseHopLimit),
+		HTTPEndpoint:            aws.String(ec2.LaunchTemplateInstanceMetadataEndpointStateEnabled),
+	}
+	if group.MetadataOptions == nil {
+		return options
+	}
+
+	if group.MetadataOptions.HTTPTokens != nil {
+		options.HTTPTokens = group.MetadataOptions.HTTPTokens
+	}
+	if group.MetadataOptions.HTTPPutResponseHopLimit != nil {
+		options.HTTPPutResponseHopLimit = group.MetadataOptions.HTTPPutResponseHopLimit
+	}
+	if group.MetadataOptions.HTTPEndpoint != nil {
+		options.HTTPEndpoint = group.MetadataOptions.HTTPEndpoint
+	}
+
+	return options
+}
+
+func buildMetadataOptions(group eksv1.NodeGroup) *ec2.LaunchTemplateInstanceMetadataOptionsRequest {
+	options := getMetadataOptions(group)
+	return &ec2.LaunchTemplateInstanceMetadataOptionsRequest{
+		HttpTokens:              options.HTTPTokens,
+		HttpPutResponseHopLimit: options.HTTPPutResponseHopLimit,
+		HttpEndpoint:            options.HTTPEndpoint,
+	}
+}
+
+// GetMetadataOptions converts the metadata options of a rancher-managed launch template version back to the node
+// group representation.
+func GetMetadataOptions(launchTemplateOptions *ec2.LaunchTemplateInstanceMetadataOptions) *eksv1.MetadataOptions {
+	if launchTemplateOptions == nil {
+		return nil
+	}
+
+	return &eksv1.MetadataOptions{
+		HTTPTokens:              launchTemplateOptions.HttpTokens,
+		HTTPPutResponseHopLimit: launchTemplateOptions.HttpPutResponseHopLimit,
+		HTTPEndpoint:            launchTemplateOptions.HttpEndpoint,
+	}
+}
+
+// MetadataOptionsNeedUpdate returns true if the node group sets metadata options and, once defaulted, they differ from
+// the upstream node group. A launch template without metadata options uses the EC2 defaults, which do not require
+// IMDSv2. Node groups that do not set metadata options are not rolled for them, the default only applies to new
+// launch template versions.
+func MetadataOptionsNeedUpdate(upstreamGroup, group eksv1.NodeGroup) bool {
+	if group.MetadataOptions == nil {
+		return false
+	}
+	options := getMetadataOptions(group)
+	upstreamOptions := eksv1.MetadataOptions{
+		HTTPTokens:              aws.String(ec2.LaunchTemplateHttpTokensStateOptional),
+		HTTPPutResponseHopLimit: aws.Int64(1),
+		HTTPEndpoint:            aws.String(ec2.LaunchTemplateInstanceMetadataEndpointStateEnabled),
+	}
+	if upstreamGroup.MetadataOptions != nil {
+		upstreamOptions = getMetadataOptions(upstreamGroup)
+	}
+
+	return aws.StringValue(options.HTTPTokens) != aws.StringValue(upstreamOptions.HTTPTokens) ||
+		aws.Int64Value(options.HTTPPutResponseHopLimit) != aws.Int64Value(upstreamOptions.HTTPPutResponseHopLimit) ||
+		aws.StringValue(options.HTTPEndpoint) != aws.StringValue(upstreamOptions.HTTPEndpoint)
+}
+
+// ValidateNetworkInterfaces checks the security groups and secondary network interfaces of a node group that uses
+// the rancher-managed launch template.
+func ValidateNetworkInterfaces(group eksv1.NodeGroup) error {
+	if len(group.SecurityGroups) == 0 && len(group.NetworkInterfaces) == 0 {
+		return nil
+	}
+
+	name := aws.StringValue(group.NodegroupName)
+	if group.LaunchTemplate != nil {
+		return fmt.Errorf("nodegroup [%s]: securityGroups and networkInterfaces cannot be used with a user provided launch template", name)
+	}
+
+	deviceIndexes := make(map[int64]bool, len(group.NetworkInterfaces))
+	for _, networkInterface := range group.NetworkInterfaces {
+		deviceIndex := aws.Int64Value(networkInterface.DeviceIndex)
+		if deviceIndex < 1 {
+			return fmt.Errorf("nodegroup [%s]: deviceIndex of network interfaces must be at least 1, "+
+				"the primary network interface uses the node group subnets and securityGroups", name)
+		}
+		if deviceIndexes[deviceIndex] {
+			return fmt.Errorf("nodegroup [%s]: deviceIndex [%d] is used by more than one network interface", name, deviceIndex)
+		}
+		deviceIndexes[deviceIndex] = true
+	}
+
+	return nil
+}
+
+type ValidateRemoteAccessOpts struct {
+	EC2Service services.EC2ServiceInterface
+	NodeGroup  eksv1.NodeGroup
+}
+
+// ValidateRemoteAccess checks that one of the security groups of a node group with an SSH key allows inbound SSH.
+// EKS only opens remote access to node groups without a launch template, so nodes of the rancher-managed launch
+// template are only reachable through their security groups. Node groups without security groups are not checked.
+func ValidateRemoteAccess(opts *ValidateRemoteAccessOpts) error {
+	group := opts.NodeGroup
+	if aws.StringValue(group.Ec2SshKey) == "" || len(group.SecurityGroups) == 0 {
+		return nil
+	}
+
+	name := aws.StringValue(group.NodegroupName)
+	output, err := opts.EC2Service.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+		GroupIds: aws.StringSlice(group.SecurityGroups),
+	})
+	if err != nil {
+		return fmt.Errorf("nodegroup [%s]: error describing security groups: %w", name, err)
+	}
+	for _, securityGroup := range output.SecurityGroups {
+		for _, permission := range securityGroup.IpPermissions {
+			if allowsSSH(permission) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("nodegroup [%s]: none of the securityGroups [%s] allow inbound SSH on port [%d], ec2SshKey cannot be used to reach the nodes",
+		name, strings.Join(group.SecurityGroups, ", "), sshPort)
+}
+
+func allowsSSH(permission *ec2.IpPermission) bool {
+	switch aws.StringValue(permission.IpProtocol) {
+	case "-1":
+		return true
+	case "tcp", "6":
+		return aws.Int64Value(permission.FromPort) <= sshPort && aws.Int64Value(permission.ToPort) >= sshPort
+	default:
+		return false
+	}
+}
+
+// buildNetworkConfig returns the security groups or network interfaces of the launch template for the node group.
+// EKS only attaches the cluster security group to nodes whose launch template sets no security groups, so it is
+// added in front of the node group security groups. When secondary network interfaces are requested, the security
+// groups are set on the primary network interface because EC2 does not accept both.
+func buildNetworkConfig(group eksv1.NodeGroup, clusterSecurityGroupID string) ([]*string, []*ec2.LaunchTemplateInstanceNetworkInterfaceSpecificationRequest, error) {
+	if len(group.SecurityGroups) == 0 && len(group.NetworkInterfaces) == 0 {
+		return nil, nil, nil
+	}
+	if clusterSecurityGroupID == "" {
+		return nil, nil, fmt.Errorf("nodegroup [%s]: cluster security group is not known, cannot set node security groups", aws.StringValue(group.NodegroupName))
+	}
+
+	securityGroups := []*string{aws.String(clusterSecurityGroupID)}
+	for _, securityGroup := range group.SecurityGroups {
+		if securityGroup != clusterSecurityGroupID {
+			securityGroups = append(securityGroups, aws.String(securityGroup))
+		}
+	}
+	if len(group.NetworkInterfaces) == 0 {
+		return securityGroups, nil, nil
+	}
+
+	networkInterfaces := []*ec2.LaunchTemplateInstanceNetworkInterfaceSpecificationRequest{
+		{
+			DeviceIndex:         aws.Int64(0),
+			Groups:              securityGroups,
+			DeleteOnTermination: aws.Bool(true),
+		},
+	}
+	for _, networkInterface := range group.NetworkInterfaces {
+		networkInterfaces = append(networkInterfaces, &ec2.LaunchTemplateInstanceNetworkInterfaceSpecificationRequest{
+			DeviceIndex:         networkInterface.DeviceIndex,
+			Groups:              aws.StringSlice(networkInterface.SecurityGroups),
+			DeleteOnTermination: aws.Bool(true),
+		})
+	}
+
+	return nil, networkInterfaces, nil
+}
+
+// GetNetworkConfig converts the security groups and network interfaces of a rancher-managed launch template version
+// back to the node group representation. The cluster security group is not included in the security groups.
+func GetNetworkConfig(launchTemplateData *ec2.ResponseLaunchTemplateData, clusterSecurityGroupID string) ([]string, []eksv1.NetworkInterface) {
+	securityGroups := launchTemplateData.SecurityGroupIds
+	var networkInterfaces []eksv1.NetworkInterface
+	for _, networkInterface := range launchTemplateData.NetworkInterfaces {
+		if aws.Int64Value(networkInterface.DeviceIndex) == 0 {
+			securityGroups = networkInterface.Groups
+			continue
+		}
+		networkInterfaces = append(networkInterfaces, eksv1.NetworkInterface{
+			DeviceIndex:    networkInterface.DeviceIndex,
+			SecurityGroups: aws.StringValueSlice(networkInterface.Groups),
+		})
+	}
+
+	return withoutSecurityGroup(aws.StringValueSlice(securityGroups), clusterSecurityGroupID), networkInterfaces
+}
+
+// NetworkConfigEqual compares the security groups and secondary network interfaces of two node groups, ignoring
+// the cluster security group.
+func NetworkConfigEqual(group, otherGroup eksv1.NodeGroup, clusterSecurityGroupID string) bool {
+	if !utils.CompareStringSliceElements(withoutSecurityGroup(group.SecurityGroups, clusterSecurityGroupID),
+		withoutSecurityGroup(otherGroup.SecurityGroups, clusterSecurityGroupID)) {
+		return false
+	}
+	if len(group.NetworkInterfaces) != len(otherGroup.NetworkInterfaces) {
+		return false
+	}
+
+	otherNetworkInterfaces := make(map[int64]eksv1.NetworkInterface, len(otherGroup.NetworkInterfaces))
+	for _, networkInterface := range otherGroup.NetworkInterfaces {
+		otherNetworkInterfaces[aws.Int64Value(networkInterface.DeviceIndex)] = networkInterface
+	}
+	for _, networkInterface := range group.NetworkInterfaces {
+		otherNetworkInterface, ok := otherNetworkInterfaces[aws.Int64Value(networkInterface.DeviceIndex)]
+		if !ok ||
+			!utils.CompareStringSliceElements(networkInterface.SecurityGroups, otherNetworkInterface.SecurityGroups) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func withoutSecurityGroup(securityGroups []string, securityGroupID string) []string {
+	var result []string
+	for _, securityGroup := range securityGroups {
+		if securityGroup != securityGroupID {
+			result = append(result, securityGroup)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,326 @@
+package eks
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+)
+
+var _ = Describe("ValidateMetadataOptions", func() {
+	var group eksv1.NodeGroup
+
+	BeforeEach(func() {
+		group = eksv1.NodeGroup{
+			NodegroupName: aws.String("test"),
+			MetadataOptions: &eksv1.MetadataOptions{
+				HTTPTokens:              aws.String(ec2.LaunchTemplateHttpTokensStateOptional),
+				HTTPPutResponseHopLimit: aws.Int64(1),
+				HTTPEndpoint:            aws.String(ec2.LaunchTemplateInstanceMetadataEndpointStateEnabled),
+			},
+		}
+	})
+
+	It("should accept valid metadata options", func() {
+		Expect(ValidateMetadataOptions(group)).To(Succeed())
+	})
+
+	It("should reject invalid http tokens", func() {
+		group.MetadataOptions.HTTPTokens = aws.String("maybe")
+		Expect(ValidateMetadataOptions(group)).ToNot(Succeed())
+	})
+
+	It("should reject invalid http endpoint", func() {
+		group.MetadataOptions.HTTPEndpoint = aws.String("on")
+		Expect(ValidateMetadataOptions(group)).ToNot(Succeed())
+	})
+
+	It("should reject out of range hop limits", func() {
+		group.MetadataOptions.HTTPPutResponseHopLimit = aws.Int64(0)
+		Expect(ValidateMetadataOptions(group)).ToNot(Succeed())
+	})
+
+	It("should reject metadata options with a user provided launch template", func() {
+		group.LaunchTemplate = &eksv1.LaunchTemplate{ID: aws.String("test")}
+		Expect(ValidateMetadataOptions(group)).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("buildMetadataOptions", func() {
+	It("should require IMDSv2 by default", func() {
+		Expect(buildMetadataOptions(eksv1.NodeGroup{})).To(Equal(&ec2.LaunchTemplateInstanceMetadataOptionsRequest{
+			HttpTokens:              aws.String(ec2.LaunchTemplateHttpTokensStateRequired),
+			HttpPutResponseHopLimit: aws.Int64(defaultHTTPPutResponseHopLimit),
+			HttpEndpoint:            aws.String(ec2.LaunchTemplateInstanceMetadataEndpointStateEnabled),
+		}))
+	})
+
+	It("should only override the fields that are set", func() {
+		group := eksv1.NodeGroup{
+			MetadataOptions: &eksv1.MetadataOptions{
+				HTTPPutResponseHopLimit: aws.Int64(1),
+			},
+		}
+		Expect(buildMetadataOptions(group)).To(Equal(&ec2.LaunchTemplateInstanceMetadataOptionsRequest{
+			HttpTokens:              aws.String(ec2.LaunchTemplateHttpTokensStateRequired),
+			HttpPutResponseHopLimit: aws.Int64(1),
+			HttpEndpoint:            aws.String(ec2.LaunchTemplateInstanceMetadataEndpointStateEnabled),
+		}))
+	})
+})
+
+var _ = Describe("MetadataOptionsNeedUpdate", func() {
+	It("should not update existing node groups without metadata options", func() {
+		Expect(MetadataOptionsNeedUpdate(eksv1.NodeGroup{}, eksv1.NodeGroup{})).To(BeFalse())
+	})
+
+	It("should require IMDSv2 for node groups that set metadata options when their launch template has none", func() {
+		group := eksv1.NodeGroup{MetadataOptions: &eksv1.MetadataOptions{}}
+		Expect(MetadataOptionsNeedUpdate(eksv1.NodeGroup{}, group)).To(BeTrue())
+	})
+
+	It("should not update node groups that set the EC2 defaults when the launch template has no metadata options", func() {
+		group := eksv1.NodeGroup{
+			MetadataOptions: &eksv1.MetadataOptions{
+				HTTPTokens:              aws.String(ec2.LaunchTemplateHttpTokensStateOptional),
+				HTTPPutResponseHopLimit: aws.Int64(1),
+			},
+		}
+		Expect(MetadataOptionsNeedUpdate(eksv1.NodeGroup{}, group)).To(BeFalse())
+	})
+
+	It("should compare the defaulted metadata options", func() {
+		upstreamGroup := eksv1.NodeGroup{
+			MetadataOptions: GetMetadataOptions(&ec2.LaunchTemplateInstanceMetadataOptions{
+				HttpTokens:              aws.String(ec2.LaunchTemplateHttpTokensStateRequired),
+				HttpPutResponseHopLimit: aws.Int64(defaultHTTPPutResponseHopLimit),
+				HttpEndpoint:            aws.String(ec2.LaunchTemplateInstanceMetadataEndpointStateEnabled),
+			}),
+		}
+		Expect(MetadataOptionsNeedUpdate(upstreamGroup, eksv1.NodeGroup{})).To(BeFalse())
+
+		group := eksv1.NodeGroup{MetadataOptions: &eksv1.MetadataOptions{}}
+		Expect(MetadataOptionsNeedUpdate(upstreamGroup, group)).To(BeFalse())
+
+		group.MetadataOptions.HTTPTokens = aws.String(ec2.LaunchTemplateHttpTokensStateOptional)
+		Expect(MetadataOptionsNeedUpdate(upstreamGroup, group)).To(BeTrue())
+	})
+})
+
+var _ = Describe("ValidateNetworkInterfaces", func() {
+	var group eksv1.NodeGroup
+
+	BeforeEach(func() {
+		group = eksv1.NodeGroup{
+			NodegroupName:  aws.String("test"),
+			SecurityGroups: []string{"sg-nodes"},
+			NetworkInterfaces: []eksv1.NetworkInterface{
+				{
+					DeviceIndex:    aws.Int64(1),
+					SecurityGroups: []string{"sg-storage"},
+				},
+			},
+		}
+	})
+
+	It("should accept security groups and secondary network interfaces", func() {
+		Expect(ValidateNetworkInterfaces(group)).To(Succeed())
+	})
+
+	It("should reject security groups with a user provided launch template", func() {
+		group.NetworkInterfaces = nil
+		group.LaunchTemplate = &eksv1.LaunchTemplate{ID: aws.String("test")}
+		Expect(ValidateNetworkInterfaces(group)).ToNot(Succeed())
+	})
+
+	It("should reject the primary network interface", func() {
+		group.NetworkInterfaces[0].DeviceIndex = aws.Int64(0)
+		Expect(ValidateNetworkInterfaces(group)).ToNot(Succeed())
+	})
+
+	It("should reject duplicate device indexes", func() {
+		group.NetworkInterfaces = append(group.NetworkInterfaces, eksv1.NetworkInterface{
+			DeviceIndex: aws.Int64(1),
+		})
+		Expect(ValidateNetworkInterfaces(group)).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("ValidateRemoteAccess", func() {
+	var (
+		mockController           *gomock.Controller
+		ec2ServiceMock           *mock_services.MockEC2ServiceInterface
+		validateRemoteAccessOpts *ValidateRemoteAccessOpts
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		ec2ServiceMock = mock_services.NewMockEC2ServiceInterface(mockController)
+		validateRemoteAccessOpts = &ValidateRemoteAccessOpts{
+			EC2Service: ec2ServiceMock,
+			NodeGroup: eksv1.NodeGroup{
+				NodegroupName:  aws.String("test"),
+				Ec2SshKey:      aws.String("key"),
+				SecurityGroups: []string{"sg-nodes", "sg-ssh"},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should accept security groups allowing inbound SSH", func() {
+		ec2ServiceMock.EXPECT().DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+			GroupIds: aws.StringSlice([]string{"sg-nodes", "sg-ssh"}),
+		}).Return(&ec2.DescribeSecurityGroupsOutput{
+			SecurityGroups: []*ec2.SecurityGroup{
+				{GroupId: aws.String("sg-nodes")},
+				{
+					GroupId: aws.String("sg-ssh"),
+					IpPermissions: []*ec2.IpPermission{
+						{IpProtocol: aws.String("tcp"), FromPort: aws.Int64(443), ToPort: aws.Int64(443)},
+						{IpProtocol: aws.String("tcp"), FromPort: aws.Int64(22), ToPort: aws.Int64(22)},
+					},
+				},
+			},
+		}, nil)
+
+		Expect(ValidateRemoteAccess(validateRemoteAccessOpts)).To(Succeed())
+	})
+
+	It("should reject security groups without inbound SSH", func() {
+		ec2ServiceMock.EXPECT().DescribeSecurityGroups(gomock.Any()).Return(&ec2.DescribeSecurityGroupsOutput{
+			SecurityGroups: []*ec2.SecurityGroup{
+				{
+					GroupId:       aws.String("sg-nodes"),
+					IpPermissions: []*ec2.IpPermission{{IpProtocol: aws.String("tcp"), FromPort: aws.Int64(443), ToPort: aws.Int64(443)}},
+				},
+				{
+					GroupId:       aws.String("sg-ssh"),
+					IpPermissions: []*ec2.IpPermission{{IpProtocol: aws.String("udp"), FromPort: aws.Int64(0), ToPort: aws.Int64(65535)}},
+				},
+			},
+		}, nil)
+
+		Expect(ValidateRemoteAccess(validateRemoteAccessOpts)).To(MatchError(ContainSubstring("none of the securityGroups [sg-nodes, sg-ssh] allow inbound SSH")))
+	})
+
+	It("should not check node groups without an SSH key or security groups", func() {
+		validateRemoteAccessOpts.NodeGroup.Ec2SshKey = nil
+		Expect(ValidateRemoteAccess(validateRemoteAccessOpts)).To(Succeed())
+
+		validateRemoteAccessOpts.NodeGroup.Ec2SshKey = aws.String("key")
+		validateRemoteAccessOpts.NodeGroup.SecurityGroups = nil
+		Expect(ValidateRemoteAccess(validateRemoteAccessOpts)).To(Succeed())
+	})
+})
+
+var _ = Describe("buildNetworkConfig", func() {
+	It("should leave the network configuration to EKS when nothing is set", func() {
+		securityGroups, networkInterfaces, err := buildNetworkConfig(eksv1.NodeGroup{}, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(securityGroups).To(BeNil())
+		Expect(networkInterfaces).To(BeNil())
+	})
+
+	It("should add the cluster security group to the node security groups", func() {
+		group := eksv1.NodeGroup{SecurityGroups: []string{"sg-cluster", "sg-nodes"}}
+		securityGroups, networkInterfaces, err := buildNetworkConfig(group, "sg-cluster")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(securityGroups).To(Equal(aws.StringSlice([]string{"sg-cluster", "sg-nodes"})))
+		Expect(networkInterfaces).To(BeNil())
+	})
+
+	It("should set the security groups on the primary network interface when secondary interfaces are set", func() {
+		group := eksv1.NodeGroup{
+			SecurityGroups: []string{"sg-nodes"},
+			NetworkInterfaces: []eksv1.NetworkInterface{
+				{
+					DeviceIndex: aws.Int64(1),
+				},
+			},
+		}
+		securityGroups, networkInterfaces, err := buildNetworkConfig(group, "sg-cluster")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(securityGroups).To(BeNil())
+		Expect(networkInterfaces).To(Equal([]*ec2.LaunchTemplateInstanceNetworkInterfaceSpecificationRequest{
+			{
+				DeviceIndex:         aws.Int64(0),
+				Groups:              aws.StringSlice([]string{"sg-cluster", "sg-nodes"}),
+				DeleteOnTermination: aws.Bool(true),
+			},
+			{
+				DeviceIndex:         aws.Int64(1),
+				Groups:              []*string{},
+				DeleteOnTermination: aws.Bool(true),
+			},
+		}))
+	})
+
+	It("should fail when the cluster security group is not known", func() {
+		_, _, err := buildNetworkConfig(eksv1.NodeGroup{SecurityGroups: []string{"sg-nodes"}}, "")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("GetNetworkConfig", func() {
+	It("should read back the node security groups without the cluster security group", func() {
+		securityGroups, networkInterfaces := GetNetworkConfig(&ec2.ResponseLaunchTemplateData{
+			SecurityGroupIds: aws.StringSlice([]string{"sg-cluster", "sg-nodes"}),
+		}, "sg-cluster")
+		Expect(securityGroups).To(Equal([]string{"sg-nodes"}))
+		Expect(networkInterfaces).To(BeNil())
+	})
+
+	It("should read back the primary and secondary network interfaces", func() {
+		group := eksv1.NodeGroup{
+			SecurityGroups: []string{"sg-cluster"},
+			NetworkInterfaces: []eksv1.NetworkInterface{
+				{
+					DeviceIndex:    aws.Int64(1),
+					SecurityGroups: []string{"sg-storage"},
+				},
+			},
+		}
+		securityGroups, networkInterfaces := GetNetworkConfig(&ec2.ResponseLaunchTemplateData{
+			NetworkInterfaces: []*ec2.LaunchTemplateInstanceNetworkInterfaceSpecification{
+				{
+					DeviceIndex: aws.Int64(0),
+					Groups:      aws.StringSlice([]string{"sg-cluster"}),
+				},
+				{
+					DeviceIndex: aws.Int64(1),
+					Groups:      aws.StringSlice([]string{"sg-storage"}),
+				},
+			},
+		}, "sg-cluster")
+		Expect(securityGroups).To(BeNil())
+		Expect(networkInterfaces).To(Equal(group.NetworkInterfaces))
+
+		upstreamGroup := eksv1.NodeGroup{SecurityGroups: securityGroups, NetworkInterfaces: networkInterfaces}
+		Expect(NetworkConfigEqual(upstreamGroup, group, "sg-cluster")).To(BeTrue())
+	})
+})
+
+var _ = Describe("NetworkConfigEqual", func() {
+	It("should detect changed security groups", func() {
+		upstreamGroup := eksv1.NodeGroup{SecurityGroups: []string{"sg-nodes"}}
+		Expect(NetworkConfigEqual(upstreamGroup, eksv1.NodeGroup{SecurityGroups: []string{"sg-nodes"}}, "sg-cluster")).To(BeTrue())
+		Expect(NetworkConfigEqual(upstreamGroup, eksv1.NodeGroup{SecurityGroups: []string{"sg-other"}}, "sg-cluster")).To(BeFalse())
+		Expect(NetworkConfigEqual(upstreamGroup, eksv1.NodeGroup{}, "sg-cluster")).To(BeFalse())
+	})
+
+	It("should detect changed network interfaces", func() {
+		upstreamGroup := eksv1.NodeGroup{
+			NetworkInterfaces: []eksv1.NetworkInterface{{DeviceIndex: aws.Int64(1), SecurityGroups: []string{"sg-a"}}},
+		}
+		group := eksv1.NodeGroup{
+			NetworkInterfaces: []eksv1.NetworkInterface{{DeviceIndex: aws.Int64(1), SecurityGroups: []string{"sg-b"}}},
+		}
+		Expect(NetworkConfigEqual(upstreamGroup, group, "sg-cluster")).To(BeFalse())
+	})
+})
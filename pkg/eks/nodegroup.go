@@ -0,0 +1,261 @@
+package eks
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+)
+
+const (
+	// UpgradeStrategyRespectPDB fails the update of a node group if pods cannot be drained because of a pod
+	// disruption budget.
+	UpgradeStrategyRespectPDB = "respectPDB"
+	// UpgradeStrategyForce replaces the nodes of a node group even if pods cannot be drained because of a pod
+	// disruption budget.
+	UpgradeStrategyForce = "force"
+
+	// NodeGroupPolicyAdopt writes the current config of a node group created outside of the operator into the spec.
+	NodeGroupPolicyAdopt = "adopt"
+	// NodeGroupPolicyIgnore only observes a node group created outside of the operator, it is never updated or deleted.
+	NodeGroupPolicyIgnore = "ignore"
+
+	maxUnavailableNodes      = 100
+	maxUnavailablePercentage = 100
+)
+
+// ValidateUpdateConfig checks the update config and upgrade strategy of a node group.
+func ValidateUpdateConfig(group eksv1.NodeGroup) error {
+	name := aws.StringValue(group.NodegroupName)
+	switch upgradeStrategy := aws.StringValue(group.UpgradeStrategy); upgradeStrategy {
+	case "", UpgradeStrategyRespectPDB, UpgradeStrategyForce:
+	default:
+		return fmt.Errorf("nodegroup [%s]: invalid upgradeStrategy [%s], valid values are [%s, %s]", name, upgradeStrategy,
+			UpgradeStrategyRespectPDB, UpgradeStrategyForce)
+	}
+
+	updateConfig := group.UpdateConfig
+	if updateConfig == nil {
+		return nil
+	}
+
+	if (updateConfig.MaxUnavailable == nil) == (updateConfig.MaxUnavailablePercentage == nil) {
+		return fmt.Errorf("nodegroup [%s]: exactly one of maxUnavailable and maxUnavailablePercentage must be set in updateConfig", name)
+	}
+	if maxUnavailable := updateConfig.MaxUnavailable; maxUnavailable != nil && (*maxUnavailable < 1 || *maxUnavailable > maxUnavailableNodes) {
+		return fmt.Errorf("nodegroup [%s]: maxUnavailable must be between 1 and %d", name, maxUnavailableNodes)
+	}
+	if percentage := updateConfig.MaxUnavailablePercentage; percentage != nil && (*percentage < 1 || *percentage > maxUnavailablePercentage) {
+		return fmt.Errorf("nodegroup [%s]: maxUnavailablePercentage must be between 1 and %d", name, maxUnavailablePercentage)
+	}
+
+	return nil
+}
+
+// IsForceUpgrade returns true if the nodes of the node group should be replaced even if their pods cannot be drained.
+func IsForceUpgrade(group eksv1.NodeGroup) bool {
+	return aws.StringValue(group.UpgradeStrategy) == UpgradeStrategyForce
+}
+
+func buildUpdateConfig(group eksv1.NodeGroup) *eks.NodegroupUpdateConfig {
+	if group.UpdateConfig == nil {
+		return nil
+	}
+
+	return &eks.NodegroupUpdateConfig{
+		MaxUnavailable:           group.UpdateConfig.MaxUnavailable,
+		MaxUnavailablePercentage: group.UpdateConfig.MaxUnavailablePercentage,
+	}
+}
+
+// GetUpdateConfig converts the update config of an EKS node group back to the node group representation.
+func GetUpdateConfig(updateConfig *eks.NodegroupUpdateConfig) *eksv1.NodeGroupUpdateConfig {
+	if updateConfig == nil {
+		return nil
+	}
+
+	return &eksv1.NodeGroupUpdateConfig{
+		MaxUnavailable:           updateConfig.MaxUnavailable,
+		MaxUnavailablePercentage: updateConfig.MaxUnavailablePercentage,
+	}
+}
+
+// GetUpdateConfigUpdate returns the update config to send to EKS if the node group sets one that differs from the
+// upstream node group, or nil otherwise.
+func GetUpdateConfigUpdate(upstreamGroup, group eksv1.NodeGroup) *eks.NodegroupUpdateConfig {
+	if group.UpdateConfig == nil {
+		return nil
+	}
+	if upstreamGroup.UpdateConfig != nil &&
+		aws.Int64Value(upstreamGroup.UpdateConfig.MaxUnavailable) == aws.Int64Value(group.UpdateConfig.MaxUnavailable) &&
+		aws.Int64Value(upstreamGroup.UpdateConfig.MaxUnavailablePercentage) == aws.Int64Value(group.UpdateConfig.MaxUnavailablePercentage) {
+		return nil
+	}
+
+	return buildUpdateConfig(group)
+}
+
+// ValidateRemediation checks the remediation of a node group.
+func ValidateRemediation(group eksv1.NodeGroup) error {
+	remediation := group.Remediation
+	if remediation == nil {
+		return nil
+	}
+
+	name := aws.StringValue(group.NodegroupName)
+	if group.LaunchTemplate != nil {
+		return fmt.Errorf("nodegroup [%s]: remediation can only be used with the launch template managed by the operator", name)
+	}
+	if len(remediation.FallbackInstanceTypes) != 0 && aws.BoolValue(group.RequestSpotInstances) {
+		return fmt.Errorf("nodegroup [%s]: fallbackInstanceTypes cannot be used with spot instances, use spotInstanceTypes instead", name)
+	}
+
+	instanceTypes := map[string]bool{aws.StringValue(group.InstanceType): true}
+	for _, instanceType := range remediation.FallbackInstanceTypes {
+		if instanceType == "" {
+			return fmt.Errorf("nodegroup [%s]: fallbackInstanceTypes cannot contain an empty instance type", name)
+		}
+		if instanceTypes[instanceType] {
+			return fmt.Errorf("nodegroup [%s]: instance type [%s] is used more than once by the node group and its fallbackInstanceTypes", name, instanceType)
+		}
+		instanceTypes[instanceType] = true
+	}
+
+	return nil
+}
+
+// NewNodeGroupStatus returns the status of an upstream node group, keeping the last update, the remediation and
+// the resolved instance requirements recorded in its previous status.
+func NewNodeGroupStatus(nodegroup *eks.Nodegroup, previous eksv1.NodeGroupStatus) eksv1.NodeGroupStatus {
+	status := eksv1.NodeGroupStatus{
+		Name:                         aws.StringValue(nodegroup.NodegroupName),
+		ARN:                          aws.StringValue(nodegroup.NodegroupArn),
+		Status:                       aws.StringValue(nodegroup.Status),
+		Version:                      aws.StringValue(nodegroup.Version),
+		ReleaseVersion:               aws.StringValue(nodegroup.ReleaseVersion),
+		CapacityType:                 aws.StringValue(nodegroup.CapacityType),
+		LastUpdateID:                 previous.LastUpdateID,
+		FallbackInstanceType:         previous.FallbackInstanceType,
+		LastRemediation:              previous.LastRemediation,
+		LastRemediationTime:          previous.LastRemediationTime,
+		ResolvedInstanceRequirements: previous.ResolvedInstanceRequirements,
+		ResolvedInstanceTypes:        previous.ResolvedInstanceTypes,
+		DroppedInstanceTypes:         previous.DroppedInstanceTypes,
+	}
+
+	if len(nodegroup.InstanceTypes) != 0 {
+		status.InstanceTypes = aws.StringValueSlice(nodegroup.InstanceTypes)
+	}
+
+	if nodegroup.LaunchTemplate != nil {
+		status.LaunchTemplateID = aws.StringValue(nodegroup.LaunchTemplate.Id)
+		status.LaunchTemplateVersion = aws.StringValue(nodegroup.LaunchTemplate.Version)
+	}
+
+	if nodegroup.ScalingConfig != nil {
+		status.ScalingConfig = eksv1.NodeGroupScalingConfig{
+			MinSize:     aws.Int64Value(nodegroup.ScalingConfig.MinSize),
+			MaxSize:     aws.Int64Value(nodegroup.ScalingConfig.MaxSize),
+			DesiredSize: aws.Int64Value(nodegroup.ScalingConfig.DesiredSize),
+		}
+	}
+
+	if nodegroup.Resources != nil {
+		for _, asg := range nodegroup.Resources.AutoScalingGroups {
+			status.AutoScalingGroups = append(status.AutoScalingGroups, aws.StringValue(asg.Name))
+		}
+	}
+
+	if nodegroup.Health != nil {
+		for _, issue := range nodegroup.Health.Issues {
+			status.Issues = append(status.Issues, eksv1.NodeGroupIssue{
+				Code:        aws.StringValue(issue.Code),
+				Message:     aws.StringValue(issue.Message),
+				ResourceIDs: aws.StringValueSlice(issue.ResourceIds),
+			})
+		}
+	}
+
+	return status
+}
+
+// WithFallbackInstanceType returns the node group with its instance type replaced by the fallback instance type, as
+// long as the fallback is still configured in its remediation.
+func WithFallbackInstanceType(group eksv1.NodeGroup, fallbackInstanceType string) eksv1.NodeGroup {
+	if fallbackInstanceType == "" || group.Remediation == nil {
+		return group
+	}
+
+	for _, instanceType := range group.Remediation.FallbackInstanceTypes {
+		if instanceType == fallbackInstanceType {
+			group.InstanceType = aws.String(fallbackInstanceType)
+			return group
+		}
+	}
+
+	return group
+}
+
+// NextFallbackInstanceType returns the fallback instance type that follows the one in use by the node group, or an
+// empty string if there are none left.
+func NextFallbackInstanceType(group eksv1.NodeGroup, fallbackInstanceType string) string {
+	if group.Remediation == nil {
+		return ""
+	}
+
+	fallbacks := group.Remediation.FallbackInstanceTypes
+	next := 0
+	for i, instanceType := range fallbacks {
+		if instanceType == fallbackInstanceType {
+			next = i + 1
+			break
+		}
+	}
+	if next >= len(fallbacks) {
+		return ""
+	}
+
+	return fallbacks[next]
+}
+
+// ValidateNodeGroupPolicies checks the policies of the node groups created outside of the operator.
+func ValidateNodeGroupPolicies(spec eksv1.EKSClusterConfigSpec) error {
+	ngs := make(map[string]bool, len(spec.NodeGroups))
+	for _, ng := range spec.NodeGroups {
+		ngs[aws.StringValue(ng.NodegroupName)] = true
+	}
+
+	names := make([]string, 0, len(spec.NodeGroupPolicies))
+	for name := range spec.NodeGroupPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch policy := spec.NodeGroupPolicies[name]; policy {
+		case NodeGroupPolicyAdopt:
+		case NodeGroupPolicyIgnore:
+			if ngs[name] {
+				return fmt.Errorf("nodegroup [%s] cannot be ignored because it is in nodeGroups", name)
+			}
+		default:
+			return fmt.Errorf("nodegroup [%s]: invalid policy [%s], valid values are [%s, %s]", name, policy,
+				NodeGroupPolicyAdopt, NodeGroupPolicyIgnore)
+		}
+	}
+
+	return nil
+}
+
+// AdoptNodeGroup returns the node group to add to the spec for an upstream node group created outside of the
+// operator, with its current config.
+func AdoptNodeGroup(upstreamGroup eksv1.NodeGroup, managedTemplateID string) eksv1.NodeGroup {
+	group := upstreamGroup
+	if lt := group.LaunchTemplate; lt != nil && (lt.ID == nil || aws.StringValue(lt.ID) == managedTemplateID) {
+		// the data of the launch template managed by the operator is already on the node group
+		group.LaunchTemplate = nil
+	}
+
+	return group
+}
@@ -0,0 +1,259 @@
+package eks
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+)
+
+var _ = Describe("ValidateUpdateConfig", func() {
+	var group eksv1.NodeGroup
+
+	BeforeEach(func() {
+		group = eksv1.NodeGroup{
+			NodegroupName:   aws.String("test"),
+			UpgradeStrategy: aws.String(UpgradeStrategyForce),
+			UpdateConfig: &eksv1.NodeGroupUpdateConfig{
+				MaxUnavailablePercentage: aws.Int64(33),
+			},
+		}
+	})
+
+	It("should accept a valid update config and upgrade strategy", func() {
+		Expect(ValidateUpdateConfig(group)).To(Succeed())
+		Expect(ValidateUpdateConfig(eksv1.NodeGroup{})).To(Succeed())
+	})
+
+	It("should reject unknown upgrade strategies", func() {
+		group.UpgradeStrategy = aws.String("drain")
+		Expect(ValidateUpdateConfig(group)).ToNot(Succeed())
+	})
+
+	It("should require exactly one of maxUnavailable and maxUnavailablePercentage", func() {
+		group.UpdateConfig.MaxUnavailable = aws.Int64(2)
+		Expect(ValidateUpdateConfig(group)).ToNot(Succeed())
+
+		group.UpdateConfig = &eksv1.NodeGroupUpdateConfig{}
+		Expect(ValidateUpdateConfig(group)).ToNot(Succeed())
+	})
+
+	It("should reject out of range values", func() {
+		group.UpdateConfig.MaxUnavailablePercentage = aws.Int64(0)
+		Expect(ValidateUpdateConfig(group)).ToNot(Succeed())
+
+		group.UpdateConfig = &eksv1.NodeGroupUpdateConfig{MaxUnavailable: aws.Int64(101)}
+		Expect(ValidateUpdateConfig(group)).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("GetUpdateConfigUpdate", func() {
+	It("should not update node groups that do not set an update config", func() {
+		upstreamGroup := eksv1.NodeGroup{UpdateConfig: &eksv1.NodeGroupUpdateConfig{MaxUnavailable: aws.Int64(1)}}
+		Expect(GetUpdateConfigUpdate(upstreamGroup, eksv1.NodeGroup{})).To(BeNil())
+	})
+
+	It("should only return an update when the update config changed", func() {
+		upstreamGroup := eksv1.NodeGroup{
+			UpdateConfig: GetUpdateConfig(&eks.NodegroupUpdateConfig{MaxUnavailable: aws.Int64(1)}),
+		}
+		group := eksv1.NodeGroup{UpdateConfig: &eksv1.NodeGroupUpdateConfig{MaxUnavailable: aws.Int64(1)}}
+		Expect(GetUpdateConfigUpdate(upstreamGroup, group)).To(BeNil())
+
+		group.UpdateConfig.MaxUnavailable = aws.Int64(3)
+		Expect(GetUpdateConfigUpdate(upstreamGroup, group)).To(Equal(&eks.NodegroupUpdateConfig{MaxUnavailable: aws.Int64(3)}))
+	})
+})
+
+var _ = Describe("ValidateRemediation", func() {
+	var group eksv1.NodeGroup
+
+	BeforeEach(func() {
+		group = eksv1.NodeGroup{
+			NodegroupName: aws.String("test"),
+			InstanceType:  aws.String("t3.medium"),
+			Remediation: &eksv1.NodeGroupRemediation{
+				NewLaunchTemplateVersion: aws.Bool(true),
+				FallbackInstanceTypes:    []string{"t3a.medium", "m5.large"},
+			},
+		}
+	})
+
+	It("should accept a valid remediation", func() {
+		Expect(ValidateRemediation(group)).To(Succeed())
+		Expect(ValidateRemediation(eksv1.NodeGroup{})).To(Succeed())
+	})
+
+	It("should reject remediation of node groups with their own launch template", func() {
+		group.LaunchTemplate = &eksv1.LaunchTemplate{ID: aws.String("lt-123")}
+		Expect(ValidateRemediation(group)).ToNot(Succeed())
+	})
+
+	It("should reject fallback instance types for spot instances", func() {
+		group.RequestSpotInstances = aws.Bool(true)
+		Expect(ValidateRemediation(group)).ToNot(Succeed())
+	})
+
+	It("should reject repeated instance types", func() {
+		group.Remediation.FallbackInstanceTypes = []string{"t3a.medium", "t3.medium"}
+		Expect(ValidateRemediation(group)).ToNot(Succeed())
+
+		group.Remediation.FallbackInstanceTypes = []string{""}
+		Expect(ValidateRemediation(group)).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("NewNodeGroupStatus", func() {
+	It("should report the state and health issues of the node group", func() {
+		previous := eksv1.NodeGroupStatus{
+			Name:                 "test",
+			Status:               eks.NodegroupStatusActive,
+			LastUpdateID:         "update-1",
+			FallbackInstanceType: "t3a.medium",
+			LastRemediation:      "switched to fallback instance type [t3a.medium]",
+			LastRemediationTime:  "2023-01-01T00:00:00Z",
+			ResolvedInstanceRequirements: &eksv1.InstanceRequirements{
+				VCPUCount: &eksv1.InstanceRequirementsRange{Min: aws.Int64(2)},
+				MemoryMiB: &eksv1.InstanceRequirementsRange{Min: aws.Int64(4096)},
+			},
+			ResolvedInstanceTypes: []string{"m5.large", "m6i.large"},
+		}
+		status := NewNodeGroupStatus(&eks.Nodegroup{
+			NodegroupName:  aws.String("test"),
+			NodegroupArn:   aws.String("arn:aws:eks:us-west-2:012345678910:nodegroup/test-cluster/test/1"),
+			Status:         aws.String(eks.NodegroupStatusDegraded),
+			Version:        aws.String("1.27"),
+			ReleaseVersion: aws.String("1.27.1-20230703"),
+			CapacityType:   aws.String(eks.CapacityTypesOnDemand),
+			InstanceTypes:  aws.StringSlice([]string{"m5.large", "m6i.large"}),
+			LaunchTemplate: &eks.LaunchTemplateSpecification{Id: aws.String("lt-123"), Version: aws.String("2")},
+			ScalingConfig: &eks.NodegroupScalingConfig{
+				MinSize:     aws.Int64(1),
+				MaxSize:     aws.Int64(3),
+				DesiredSize: aws.Int64(2),
+			},
+			Resources: &eks.NodegroupResources{
+				AutoScalingGroups: []*eks.AutoScalingGroup{{Name: aws.String("eks-test-asg")}},
+			},
+			Health: &eks.NodegroupHealth{
+				Issues: []*eks.Issue{
+					{
+						Code:        aws.String(eks.NodegroupIssueCodeAsgInstanceLaunchFailures),
+						Message:     aws.String("could not launch instances"),
+						ResourceIds: aws.StringSlice([]string{"eks-test-asg"}),
+					},
+				},
+			},
+		}, previous)
+
+		Expect(status).To(Equal(eksv1.NodeGroupStatus{
+			Name:                  "test",
+			ARN:                   "arn:aws:eks:us-west-2:012345678910:nodegroup/test-cluster/test/1",
+			Status:                eks.NodegroupStatusDegraded,
+			Version:               "1.27",
+			ReleaseVersion:        "1.27.1-20230703",
+			LaunchTemplateID:      "lt-123",
+			LaunchTemplateVersion: "2",
+			CapacityType:          eks.CapacityTypesOnDemand,
+			AutoScalingGroups:     []string{"eks-test-asg"},
+			ScalingConfig: eksv1.NodeGroupScalingConfig{
+				MinSize:     1,
+				MaxSize:     3,
+				DesiredSize: 2,
+			},
+			LastUpdateID: "update-1",
+			Issues: []eksv1.NodeGroupIssue{
+				{
+					Code:        eks.NodegroupIssueCodeAsgInstanceLaunchFailures,
+					Message:     "could not launch instances",
+					ResourceIDs: []string{"eks-test-asg"},
+				},
+			},
+			FallbackInstanceType: "t3a.medium",
+			LastRemediation:      "switched to fallback instance type [t3a.medium]",
+			LastRemediationTime:  "2023-01-01T00:00:00Z",
+			InstanceTypes:        []string{"m5.large", "m6i.large"},
+			ResolvedInstanceRequirements: &eksv1.InstanceRequirements{
+				VCPUCount: &eksv1.InstanceRequirementsRange{Min: aws.Int64(2)},
+				MemoryMiB: &eksv1.InstanceRequirementsRange{Min: aws.Int64(4096)},
+			},
+			ResolvedInstanceTypes: []string{"m5.large", "m6i.large"},
+		}))
+	})
+})
+
+var _ = Describe("FallbackInstanceType", func() {
+	var group eksv1.NodeGroup
+
+	BeforeEach(func() {
+		group = eksv1.NodeGroup{
+			InstanceType: aws.String("t3.medium"),
+			Remediation: &eksv1.NodeGroupRemediation{
+				FallbackInstanceTypes: []string{"t3a.medium", "m5.large"},
+			},
+		}
+	})
+
+	It("should return the fallback instance types in order", func() {
+		Expect(NextFallbackInstanceType(group, "")).To(Equal("t3a.medium"))
+		Expect(NextFallbackInstanceType(group, "t3a.medium")).To(Equal("m5.large"))
+		Expect(NextFallbackInstanceType(group, "m5.large")).To(BeEmpty())
+		Expect(NextFallbackInstanceType(eksv1.NodeGroup{}, "")).To(BeEmpty())
+	})
+
+	It("should only use fallback instance types that are still configured", func() {
+		Expect(WithFallbackInstanceType(group, "m5.large").InstanceType).To(Equal(aws.String("m5.large")))
+		Expect(WithFallbackInstanceType(group, "c5.large").InstanceType).To(Equal(aws.String("t3.medium")))
+		Expect(WithFallbackInstanceType(group, "").InstanceType).To(Equal(aws.String("t3.medium")))
+	})
+})
+
+var _ = Describe("ValidateNodeGroupPolicies", func() {
+	var spec eksv1.EKSClusterConfigSpec
+
+	BeforeEach(func() {
+		spec = eksv1.EKSClusterConfigSpec{
+			NodeGroups: []eksv1.NodeGroup{{NodegroupName: aws.String("managed")}},
+			NodeGroupPolicies: map[string]string{
+				"managed":  NodeGroupPolicyAdopt,
+				"external": NodeGroupPolicyIgnore,
+			},
+		}
+	})
+
+	It("should accept valid policies", func() {
+		Expect(ValidateNodeGroupPolicies(spec)).To(Succeed())
+		Expect(ValidateNodeGroupPolicies(eksv1.EKSClusterConfigSpec{})).To(Succeed())
+	})
+
+	It("should reject unknown policies", func() {
+		spec.NodeGroupPolicies["external"] = "delete"
+		Expect(ValidateNodeGroupPolicies(spec)).ToNot(Succeed())
+	})
+
+	It("should reject ignoring node groups in the spec", func() {
+		spec.NodeGroupPolicies["managed"] = NodeGroupPolicyIgnore
+		Expect(ValidateNodeGroupPolicies(spec)).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("AdoptNodeGroup", func() {
+	It("should keep launch templates that are not managed by the operator", func() {
+		upstreamGroup := eksv1.NodeGroup{
+			NodegroupName:  aws.String("external"),
+			LaunchTemplate: &eksv1.LaunchTemplate{ID: aws.String("lt-external"), Version: aws.Int64(3)},
+		}
+		Expect(AdoptNodeGroup(upstreamGroup, "lt-managed")).To(Equal(upstreamGroup))
+	})
+
+	It("should drop the launch template managed by the operator", func() {
+		group := AdoptNodeGroup(eksv1.NodeGroup{
+			NodegroupName:  aws.String("external"),
+			InstanceType:   aws.String("t3.medium"),
+			LaunchTemplate: &eksv1.LaunchTemplate{ID: aws.String("lt-managed"), Version: aws.Int64(3)},
+		}, "lt-managed")
+		Expect(group.LaunchTemplate).To(BeNil())
+		Expect(group.InstanceType).To(Equal(aws.String("t3.medium")))
+	})
+})
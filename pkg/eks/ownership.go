@@ -0,0 +1,91 @@
+package eks
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+)
+
+const (
+	// OwnershipObserveOnly only records the state of the cluster, mutating APIs are never called.
+	OwnershipObserveOnly = "ObserveOnly"
+	// OwnershipManaged updates the cluster to match the spec but never deletes it.
+	OwnershipManaged = "Managed"
+	// OwnershipOwned updates the cluster and deletes it when the config is removed.
+	OwnershipOwned = "Owned"
+)
+
+// GetOwnership returns the ownership of the cluster, imported clusters are managed and other clusters are owned
+// unless set otherwise.
+func GetOwnership(spec eksv1.EKSClusterConfigSpec) string {
+	if ownership := aws.StringValue(spec.Ownership); ownership != "" {
+		return ownership
+	}
+	if spec.Imported {
+		return OwnershipManaged
+	}
+
+	return OwnershipOwned
+}
+
+// ValidateOwnership checks the ownership of the config. Clusters that are not imported cannot be observed only
+// before they are created and imported clusters can only be owned once their resources are discovered for the
+// current generation, as only the discovered resources are deleted with the cluster. Imported clusters are not
+// discovered yet when they are created, their resources are discovered before the config is validated again.
+func ValidateOwnership(config *eksv1.EKSClusterConfig) error {
+	ownership := GetOwnership(config.Spec)
+	switch ownership {
+	case OwnershipObserveOnly:
+		if !config.Spec.Imported && config.Status.Phase == "" {
+			return fmt.Errorf("ownership [%s] can only be used for imported or existing clusters", ownership)
+		}
+	case OwnershipManaged:
+	case OwnershipOwned:
+		if config.Spec.Imported && config.Status.Phase != "" && NeedsDiscovery(config) {
+			return fmt.Errorf("ownership of imported cluster can only be promoted to [%s] once its resources are discovered", ownership)
+		}
+	default:
+		return fmt.Errorf("invalid ownership [%s], valid values are [%s, %s, %s]", ownership,
+			OwnershipObserveOnly, OwnershipManaged, OwnershipOwned)
+	}
+
+	return nil
+}
+
+// NeedsDiscovery returns true if the resources of an imported cluster must be discovered, which is the case when
+// they were never discovered or when an owned config changed since they were, so that a promotion to owned deletes
+// the resources that exist when it is requested.
+func NeedsDiscovery(config *eksv1.EKSClusterConfig) bool {
+	if !config.Spec.Imported {
+		return false
+	}
+	discovered := config.Status.DiscoveredResources
+	if discovered == nil {
+		return true
+	}
+
+	return GetOwnership(config.Spec) == OwnershipOwned && discovered.Generation != config.Generation
+}
+
+// GetDiscoveredRolesToDelete returns the discovered roles of an imported cluster that are set to be deleted with it.
+// Roles set in DeleteDiscoveredRoles that were not discovered are never deleted.
+func GetDiscoveredRolesToDelete(config *eksv1.EKSClusterConfig) []string {
+	discovered := config.Status.DiscoveredResources
+	if discovered == nil {
+		return nil
+	}
+	toDelete := make(map[string]bool)
+	for _, role := range config.Spec.DeleteDiscoveredRoles {
+		toDelete[role] = true
+	}
+
+	var roles []string
+	for _, role := range append([]string{discovered.ServiceRole}, discovered.NodeRoles...) {
+		if role != "" && toDelete[role] {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
@@ -0,0 +1,139 @@
+package eks
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+)
+
+var _ = Describe("GetOwnership", func() {
+	It("should default to managed for imported clusters and owned otherwise", func() {
+		Expect(GetOwnership(eksv1.EKSClusterConfigSpec{Imported: true})).To(Equal(OwnershipManaged))
+		Expect(GetOwnership(eksv1.EKSClusterConfigSpec{})).To(Equal(OwnershipOwned))
+	})
+
+	It("should return the ownership that is set", func() {
+		Expect(GetOwnership(eksv1.EKSClusterConfigSpec{Imported: true, Ownership: aws.String(OwnershipObserveOnly)})).To(Equal(OwnershipObserveOnly))
+	})
+})
+
+var _ = Describe("ValidateOwnership", func() {
+	var config *eksv1.EKSClusterConfig
+
+	BeforeEach(func() {
+		config = &eksv1.EKSClusterConfig{
+			Spec: eksv1.EKSClusterConfigSpec{
+				Imported: true,
+			},
+		}
+	})
+
+	It("should accept the default ownership", func() {
+		Expect(ValidateOwnership(config)).To(Succeed())
+		Expect(ValidateOwnership(&eksv1.EKSClusterConfig{})).To(Succeed())
+	})
+
+	It("should reject unknown ownerships", func() {
+		config.Spec.Ownership = aws.String("Shared")
+		Expect(ValidateOwnership(config)).ToNot(Succeed())
+	})
+
+	It("should only promote imported clusters to owned once their resources are discovered", func() {
+		config.Status.Phase = "active"
+		config.Generation = 2
+		config.Spec.Ownership = aws.String(OwnershipOwned)
+		Expect(ValidateOwnership(config)).ToNot(Succeed())
+
+		config.Status.DiscoveredResources = &eksv1.DiscoveredResources{Stacks: []string{"test-eks-vpc"}, Generation: 1}
+		Expect(ValidateOwnership(config)).ToNot(Succeed())
+
+		config.Status.DiscoveredResources.Generation = 2
+		Expect(ValidateOwnership(config)).To(Succeed())
+	})
+
+	It("should promote imported clusters without stacks to owned", func() {
+		// clusters created outside the operator, for instance by Terraform, have no stacks and their roles are kept
+		config.Status.Phase = "active"
+		config.Generation = 2
+		config.Spec.Ownership = aws.String(OwnershipOwned)
+		config.Status.DiscoveredResources = &eksv1.DiscoveredResources{
+			ServiceRole: "arn:aws:iam::123456789012:role/test",
+			NodeRoles:   []string{"arn:aws:iam::123456789012:role/nodes"},
+			Generation:  2,
+		}
+		Expect(ValidateOwnership(config)).To(Succeed())
+
+		config.Status.DiscoveredResources = &eksv1.DiscoveredResources{Generation: 2}
+		Expect(ValidateOwnership(config)).To(Succeed())
+	})
+
+	It("should accept imported clusters created as owned before their resources are discovered", func() {
+		config.Spec.Ownership = aws.String(OwnershipOwned)
+		Expect(ValidateOwnership(config)).To(Succeed())
+	})
+
+	It("should only observe clusters that exist", func() {
+		config.Spec.Ownership = aws.String(OwnershipObserveOnly)
+		Expect(ValidateOwnership(config)).To(Succeed())
+
+		config.Spec.Imported = false
+		Expect(ValidateOwnership(config)).ToNot(Succeed())
+
+		config.Status.Phase = "active"
+		Expect(ValidateOwnership(config)).To(Succeed())
+	})
+})
+
+var _ = Describe("GetDiscoveredRolesToDelete", func() {
+	It("should only return the discovered roles set to be deleted", func() {
+		config := &eksv1.EKSClusterConfig{
+			Spec: eksv1.EKSClusterConfigSpec{
+				DeleteDiscoveredRoles: []string{
+					"arn:aws:iam::123456789012:role/service",
+					"arn:aws:iam::123456789012:role/other",
+				},
+			},
+		}
+		Expect(GetDiscoveredRolesToDelete(config)).To(BeEmpty())
+
+		config.Status.DiscoveredResources = &eksv1.DiscoveredResources{
+			ServiceRole: "arn:aws:iam::123456789012:role/service",
+			NodeRoles:   []string{"arn:aws:iam::123456789012:role/nodes"},
+		}
+		Expect(GetDiscoveredRolesToDelete(config)).To(Equal([]string{"arn:aws:iam::123456789012:role/service"}))
+	})
+})
+
+var _ = Describe("NeedsDiscovery", func() {
+	var config *eksv1.EKSClusterConfig
+
+	BeforeEach(func() {
+		config = &eksv1.EKSClusterConfig{
+			Spec: eksv1.EKSClusterConfigSpec{
+				Imported: true,
+			},
+		}
+		config.Generation = 2
+	})
+
+	It("should discover the resources of imported clusters once", func() {
+		Expect(NeedsDiscovery(config)).To(BeTrue())
+
+		config.Status.DiscoveredResources = &eksv1.DiscoveredResources{Generation: 1}
+		Expect(NeedsDiscovery(config)).To(BeFalse())
+
+		config.Spec.Imported = false
+		config.Status.DiscoveredResources = nil
+		Expect(NeedsDiscovery(config)).To(BeFalse())
+	})
+
+	It("should discover the resources again when an owned config changes", func() {
+		config.Spec.Ownership = aws.String(OwnershipOwned)
+		config.Status.DiscoveredResources = &eksv1.DiscoveredResources{Generation: 1}
+		Expect(NeedsDiscovery(config)).To(BeTrue())
+
+		config.Status.DiscoveredResources.Generation = 2
+		Expect(NeedsDiscovery(config)).To(BeFalse())
+	})
+})
@@ -0,0 +1,225 @@
+package eks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services"
+)
+
+const (
+	// minClusterSubnetFreeIPs is the number of free IP addresses EKS requires in each subnet of the cluster for the
+	// network interfaces of the control plane.
+	minClusterSubnetFreeIPs = 6
+	// minClusterAvailabilityZones is the number of availability zones the subnets of the cluster must span.
+	minClusterAvailabilityZones = 2
+)
+
+type ValidateNetworkOpts struct {
+	EC2Service services.EC2ServiceInterface
+	Config     *eksv1.EKSClusterConfig
+}
+
+// ValidateNetwork checks the subnets and security groups provided in the config against EC2 before the cluster is
+// created: the subnets of the cluster must be in a single VPC, span two availability zones and have free IP
+// addresses, and the security groups and the subnets of the node groups must be in the same VPC. It returns an
+// error listing every problem by field. Configs without subnets, whose network is generated, are not checked.
+func ValidateNetwork(opts *ValidateNetworkOpts) error {
+	spec := opts.Config.Spec
+	if len(spec.Subnets) == 0 {
+		return nil
+	}
+
+	subnets, err := describeSubnets(opts.EC2Service, spec.Subnets)
+	if err != nil {
+		return fmt.Errorf("subnets: error describing subnets [%s]: %w", strings.Join(spec.Subnets, ", "), err)
+	}
+
+	var errs []string
+	vpcSubnets := make(map[string][]string)
+	zones := make(map[string]bool)
+	for _, subnetID := range spec.Subnets {
+		subnet, ok := subnets[subnetID]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("subnets: subnet [%s] not found", subnetID))
+			continue
+		}
+		vpcID := aws.StringValue(subnet.VpcId)
+		vpcSubnets[vpcID] = append(vpcSubnets[vpcID], subnetID)
+		zones[aws.StringValue(subnet.AvailabilityZone)] = true
+		if freeIPs := aws.Int64Value(subnet.AvailableIpAddressCount); freeIPs < minClusterSubnetFreeIPs {
+			errs = append(errs, fmt.Sprintf("subnets: subnet [%s] has [%d] free IP addresses, at least [%d] are required",
+				subnetID, freeIPs, minClusterSubnetFreeIPs))
+		}
+	}
+	if len(vpcSubnets) > 1 {
+		var vpcs []string
+		for vpcID, ids := range vpcSubnets {
+			vpcs = append(vpcs, fmt.Sprintf("%s: %s", vpcID, strings.Join(ids, ", ")))
+		}
+		sort.Strings(vpcs)
+		errs = append(errs, fmt.Sprintf("subnets: subnets must be in a single VPC, they are in [%s]", strings.Join(vpcs, "; ")))
+	}
+	if len(subnets) != 0 && len(zones) < minClusterAvailabilityZones {
+		errs = append(errs, fmt.Sprintf("subnets: subnets must be in at least [%d] availability zones, they are in [%s]",
+			minClusterAvailabilityZones, strings.Join(sortedKeys(zones), ", ")))
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("invalid network for cluster [%s]: %s", opts.Config.Name, strings.Join(errs, "; "))
+	}
+
+	var vpcID string
+	for id := range vpcSubnets {
+		vpcID = id
+	}
+	vpcs, err := opts.EC2Service.DescribeVpcs(&ec2.DescribeVpcsInput{
+		VpcIds: aws.StringSlice([]string{vpcID}),
+	})
+	if err != nil {
+		return fmt.Errorf("subnets: error describing vpc [%s] of the subnets: %w", vpcID, err)
+	}
+	if len(vpcs.Vpcs) == 0 || aws.StringValue(vpcs.Vpcs[0].State) != ec2.VpcStateAvailable {
+		errs = append(errs, fmt.Sprintf("subnets: vpc [%s] of the subnets is not available", vpcID))
+	}
+
+	if len(spec.SecurityGroups) != 0 {
+		securityGroups, err := describeSecurityGroups(opts.EC2Service, spec.SecurityGroups)
+		if err != nil {
+			return fmt.Errorf("securityGroups: error describing security groups [%s]: %w", strings.Join(spec.SecurityGroups, ", "), err)
+		}
+		for _, securityGroupID := range spec.SecurityGroups {
+			securityGroup, ok := securityGroups[securityGroupID]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("securityGroups: security group [%s] not found", securityGroupID))
+			} else if aws.StringValue(securityGroup.VpcId) != vpcID {
+				errs = append(errs, fmt.Sprintf("securityGroups: security group [%s] is in vpc [%s], not in vpc [%s] of the subnets",
+					securityGroupID, aws.StringValue(securityGroup.VpcId), vpcID))
+			}
+		}
+	}
+
+	nodegroupErrs, err := validateNodeGroupSubnets(opts.EC2Service, spec.NodeGroups, vpcID)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, nodegroupErrs...)
+
+	if len(errs) != 0 {
+		return fmt.Errorf("invalid network for cluster [%s]: %s", opts.Config.Name, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+type ValidateNodeGroupSubnetsOpts struct {
+	EC2Service services.EC2ServiceInterface
+	Config     *eksv1.EKSClusterConfig
+	NodeGroups []eksv1.NodeGroup
+	VPCID      string
+}
+
+// ValidateNodeGroupSubnets checks that the subnets of the node groups about to be created exist and are in the VPC
+// of the cluster, whether its network was provided or generated. Node groups without subnets use the subnets of the
+// cluster and are not checked.
+func ValidateNodeGroupSubnets(opts *ValidateNodeGroupSubnetsOpts) error {
+	errs, err := validateNodeGroupSubnets(opts.EC2Service, opts.NodeGroups, opts.VPCID)
+	if err != nil {
+		return err
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("invalid subnets for nodegroups of cluster [%s]: %s", opts.Config.Name, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// validateNodeGroupSubnets returns the problems of the subnets of the node groups by field.
+func validateNodeGroupSubnets(ec2Service services.EC2ServiceInterface, nodegroups []eksv1.NodeGroup, vpcID string) ([]string, error) {
+	var subnetIDs []string
+	for _, ng := range nodegroups {
+		subnetIDs = append(subnetIDs, ng.Subnets...)
+	}
+	if len(subnetIDs) == 0 {
+		return nil, nil
+	}
+
+	subnets, err := describeSubnets(ec2Service, subnetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("nodeGroups: error describing subnets [%s]: %w", strings.Join(subnetIDs, ", "), err)
+	}
+
+	var errs []string
+	for _, ng := range nodegroups {
+		for _, subnetID := range ng.Subnets {
+			subnet, ok := subnets[subnetID]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("nodeGroups[%s].subnets: subnet [%s] not found", aws.StringValue(ng.NodegroupName), subnetID))
+			} else if aws.StringValue(subnet.VpcId) != vpcID {
+				errs = append(errs, fmt.Sprintf("nodeGroups[%s].subnets: subnet [%s] is in vpc [%s], not in vpc [%s] of the cluster",
+					aws.StringValue(ng.NodegroupName), subnetID, aws.StringValue(subnet.VpcId), vpcID))
+			}
+		}
+	}
+
+	return errs, nil
+}
+
+// describeSubnets returns the subnets that exist, by ID. The subnets are filtered by ID rather than requested by
+// ID, which fails with InvalidSubnetID.NotFound as soon as one of them does not exist.
+func describeSubnets(ec2Service services.EC2ServiceInterface, subnetIDs []string) (map[string]*ec2.Subnet, error) {
+	output, err := ec2Service.DescribeSubnets(&ec2.DescribeSubnetsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("subnet-id"),
+				Values: aws.StringSlice(subnetIDs),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subnets := make(map[string]*ec2.Subnet, len(output.Subnets))
+	for _, subnet := range output.Subnets {
+		subnets[aws.StringValue(subnet.SubnetId)] = subnet
+	}
+
+	return subnets, nil
+}
+
+// describeSecurityGroups returns the security groups that exist, by ID. Like subnets, they are filtered by ID so
+// that missing groups do not fail the request.
+func describeSecurityGroups(ec2Service services.EC2ServiceInterface, securityGroupIDs []string) (map[string]*ec2.SecurityGroup, error) {
+	output, err := ec2Service.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("group-id"),
+				Values: aws.StringSlice(securityGroupIDs),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	securityGroups := make(map[string]*ec2.SecurityGroup, len(output.SecurityGroups))
+	for _, securityGroup := range output.SecurityGroups {
+		securityGroups[aws.StringValue(securityGroup.GroupId)] = securityGroup
+	}
+
+	return securityGroups, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
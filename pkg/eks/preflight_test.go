@@ -0,0 +1,176 @@
+package eks
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+)
+
+var _ = Describe("ValidateNetwork", func() {
+	var (
+		mockController      *gomock.Controller
+		ec2ServiceMock      *mock_services.MockEC2ServiceInterface
+		validateNetworkOpts *ValidateNetworkOpts
+		subnets             []*ec2.Subnet
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		ec2ServiceMock = mock_services.NewMockEC2ServiceInterface(mockController)
+		validateNetworkOpts = &ValidateNetworkOpts{
+			EC2Service: ec2ServiceMock,
+			Config: &eksv1.EKSClusterConfig{
+				Spec: eksv1.EKSClusterConfigSpec{
+					Subnets:        []string{"subnet-1", "subnet-2"},
+					SecurityGroups: []string{"sg-1"},
+					NodeGroups: []eksv1.NodeGroup{
+						{
+							NodegroupName: aws.String("ng"),
+							Subnets:       []string{"subnet-3"},
+						},
+					},
+				},
+			},
+		}
+		subnets = []*ec2.Subnet{
+			{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-1"), AvailabilityZone: aws.String("us-east-1a"), AvailableIpAddressCount: aws.Int64(100)},
+			{SubnetId: aws.String("subnet-2"), VpcId: aws.String("vpc-1"), AvailabilityZone: aws.String("us-east-1b"), AvailableIpAddressCount: aws.Int64(100)},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	expectVPC := func() {
+		ec2ServiceMock.EXPECT().DescribeVpcs(&ec2.DescribeVpcsInput{VpcIds: aws.StringSlice([]string{"vpc-1"})}).Return(
+			&ec2.DescribeVpcsOutput{Vpcs: []*ec2.Vpc{{VpcId: aws.String("vpc-1"), State: aws.String(ec2.VpcStateAvailable)}}}, nil)
+	}
+
+	subnetsInput := func(subnetIDs ...string) *ec2.DescribeSubnetsInput {
+		return &ec2.DescribeSubnetsInput{
+			Filters: []*ec2.Filter{{Name: aws.String("subnet-id"), Values: aws.StringSlice(subnetIDs)}},
+		}
+	}
+
+	It("should accept a network in a single vpc", func() {
+		ec2ServiceMock.EXPECT().DescribeSubnets(subnetsInput("subnet-1", "subnet-2")).Return(
+			&ec2.DescribeSubnetsOutput{Subnets: subnets}, nil)
+		expectVPC()
+		ec2ServiceMock.EXPECT().DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+			Filters: []*ec2.Filter{{Name: aws.String("group-id"), Values: aws.StringSlice([]string{"sg-1"})}},
+		}).Return(&ec2.DescribeSecurityGroupsOutput{SecurityGroups: []*ec2.SecurityGroup{{GroupId: aws.String("sg-1"), VpcId: aws.String("vpc-1")}}}, nil)
+		ec2ServiceMock.EXPECT().DescribeSubnets(subnetsInput("subnet-3")).Return(
+			&ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{{SubnetId: aws.String("subnet-3"), VpcId: aws.String("vpc-1")}}}, nil)
+
+		Expect(ValidateNetwork(validateNetworkOpts)).To(Succeed())
+	})
+
+	It("should not check generated networks", func() {
+		validateNetworkOpts.Config.Spec.Subnets = nil
+		Expect(ValidateNetwork(validateNetworkOpts)).To(Succeed())
+	})
+
+	It("should reject subnets in one availability zone, different vpcs or without free IP addresses", func() {
+		subnets[1].AvailabilityZone = aws.String("us-east-1a")
+		subnets[1].VpcId = aws.String("vpc-2")
+		subnets[1].AvailableIpAddressCount = aws.Int64(2)
+		ec2ServiceMock.EXPECT().DescribeSubnets(gomock.Any()).Return(&ec2.DescribeSubnetsOutput{Subnets: subnets}, nil)
+
+		err := ValidateNetwork(validateNetworkOpts)
+		Expect(err).To(MatchError(ContainSubstring("at least [2] availability zones")))
+		Expect(err).To(MatchError(ContainSubstring("single VPC")))
+		Expect(err).To(MatchError(ContainSubstring("subnet [subnet-2] has [2] free IP addresses")))
+	})
+
+	It("should reject security groups and nodegroup subnets from another vpc", func() {
+		ec2ServiceMock.EXPECT().DescribeSubnets(subnetsInput("subnet-1", "subnet-2")).Return(
+			&ec2.DescribeSubnetsOutput{Subnets: subnets}, nil)
+		expectVPC()
+		ec2ServiceMock.EXPECT().DescribeSecurityGroups(gomock.Any()).Return(
+			&ec2.DescribeSecurityGroupsOutput{SecurityGroups: []*ec2.SecurityGroup{{GroupId: aws.String("sg-1"), VpcId: aws.String("vpc-2")}}}, nil)
+		ec2ServiceMock.EXPECT().DescribeSubnets(subnetsInput("subnet-3")).Return(
+			&ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{{SubnetId: aws.String("subnet-3"), VpcId: aws.String("vpc-2")}}}, nil)
+
+		err := ValidateNetwork(validateNetworkOpts)
+		Expect(err).To(MatchError(ContainSubstring("securityGroups: security group [sg-1] is in vpc [vpc-2]")))
+		Expect(err).To(MatchError(ContainSubstring("nodeGroups[ng].subnets: subnet [subnet-3] is in vpc [vpc-2]")))
+	})
+
+	It("should report missing subnets by field", func() {
+		validateNetworkOpts.Config.Spec.Subnets = []string{"subnet-1", "subnet-2", "subnet-missing"}
+		ec2ServiceMock.EXPECT().DescribeSubnets(subnetsInput("subnet-1", "subnet-2", "subnet-missing")).Return(
+			&ec2.DescribeSubnetsOutput{Subnets: subnets}, nil)
+
+		err := ValidateNetwork(validateNetworkOpts)
+		Expect(err).To(MatchError(ContainSubstring("subnets: subnet [subnet-missing] not found")))
+		Expect(err).ToNot(MatchError(ContainSubstring("subnet [subnet-1]")))
+	})
+
+	It("should report missing security groups and nodegroup subnets by field", func() {
+		validateNetworkOpts.Config.Spec.SecurityGroups = []string{"sg-1", "sg-missing"}
+		ec2ServiceMock.EXPECT().DescribeSubnets(subnetsInput("subnet-1", "subnet-2")).Return(
+			&ec2.DescribeSubnetsOutput{Subnets: subnets}, nil)
+		expectVPC()
+		ec2ServiceMock.EXPECT().DescribeSecurityGroups(gomock.Any()).Return(
+			&ec2.DescribeSecurityGroupsOutput{SecurityGroups: []*ec2.SecurityGroup{{GroupId: aws.String("sg-1"), VpcId: aws.String("vpc-1")}}}, nil)
+		ec2ServiceMock.EXPECT().DescribeSubnets(subnetsInput("subnet-3")).Return(&ec2.DescribeSubnetsOutput{}, nil)
+
+		err := ValidateNetwork(validateNetworkOpts)
+		Expect(err).To(MatchError(ContainSubstring("securityGroups: security group [sg-missing] not found")))
+		Expect(err).To(MatchError(ContainSubstring("nodeGroups[ng].subnets: subnet [subnet-3] not found")))
+		Expect(err).ToNot(MatchError(ContainSubstring("security group [sg-1]")))
+	})
+})
+
+var _ = Describe("ValidateNodeGroupSubnets", func() {
+	var (
+		mockController               *gomock.Controller
+		ec2ServiceMock               *mock_services.MockEC2ServiceInterface
+		validateNodeGroupSubnetsOpts *ValidateNodeGroupSubnetsOpts
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		ec2ServiceMock = mock_services.NewMockEC2ServiceInterface(mockController)
+		validateNodeGroupSubnetsOpts = &ValidateNodeGroupSubnetsOpts{
+			EC2Service: ec2ServiceMock,
+			Config:     &eksv1.EKSClusterConfig{},
+			NodeGroups: []eksv1.NodeGroup{
+				{NodegroupName: aws.String("ng1"), Subnets: []string{"subnet-1"}},
+				{NodegroupName: aws.String("ng2")},
+			},
+			VPCID: "vpc-1",
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should accept subnets in the vpc of the cluster", func() {
+		ec2ServiceMock.EXPECT().DescribeSubnets(gomock.Any()).Return(
+			&ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-1")}}}, nil)
+
+		Expect(ValidateNodeGroupSubnets(validateNodeGroupSubnetsOpts)).To(Succeed())
+	})
+
+	It("should not check node groups using the subnets of the cluster", func() {
+		validateNodeGroupSubnetsOpts.NodeGroups = validateNodeGroupSubnetsOpts.NodeGroups[1:]
+		Expect(ValidateNodeGroupSubnets(validateNodeGroupSubnetsOpts)).To(Succeed())
+	})
+
+	It("should reject subnets from another vpc", func() {
+		validateNodeGroupSubnetsOpts.NodeGroups[1].Subnets = []string{"subnet-missing"}
+		ec2ServiceMock.EXPECT().DescribeSubnets(gomock.Any()).Return(
+			&ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-2")}}}, nil)
+
+		err := ValidateNodeGroupSubnets(validateNodeGroupSubnetsOpts)
+		Expect(err).To(MatchError(ContainSubstring("nodeGroups[ng1].subnets: subnet [subnet-1] is in vpc [vpc-2], not in vpc [vpc-1]")))
+		Expect(err).To(MatchError(ContainSubstring("nodeGroups[ng2].subnets: subnet [subnet-missing] not found")))
+	})
+})
@@ -0,0 +1,76 @@
+package eks
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+)
+
+// renderedClusterSecurityGroupID stands for the security group EKS creates for the cluster, which is not known
+// before the cluster exists.
+const renderedClusterSecurityGroupID = "<cluster-security-group-id>"
+
+// RenderedNodeGroup holds the requests sent to create a node group.
+type RenderedNodeGroup struct {
+	CreateNodegroupInput *eks.CreateNodegroupInput `json:"createNodegroupInput"`
+	// LaunchTemplateData is the data of the managed launch template version, it is not set for node groups with
+	// their own launch template
+	LaunchTemplateData *ec2.RequestLaunchTemplateData `json:"launchTemplateData,omitempty"`
+}
+
+// RenderedCluster holds the requests sent to create a cluster and its node groups.
+type RenderedCluster struct {
+	CreateClusterInput *eks.CreateClusterInput `json:"createClusterInput"`
+	NodeGroups         []RenderedNodeGroup     `json:"nodeGroups,omitempty"`
+}
+
+type RenderClusterOpts struct {
+	Config *eksv1.EKSClusterConfig
+}
+
+// RenderCluster returns the requests sent to create the cluster of the config and its node groups, without
+// access to AWS. Values generated by the operator while creating the cluster, such as the service role, the
+// network and the node role, are left unset unless the config specifies them. The cluster security group is
+// rendered as a placeholder.
+func RenderCluster(opts *RenderClusterOpts) (*RenderedCluster, error) {
+	// building the launch template data encodes the user data of the node groups in place
+	config := opts.Config.DeepCopy()
+	if len(config.Status.Subnets) == 0 {
+		config.Status.Subnets = config.Spec.Subnets
+	}
+	if len(config.Status.SecurityGroups) == 0 {
+		config.Status.SecurityGroups = config.Spec.SecurityGroups
+	}
+	if config.Status.ClusterSecurityGroupID == "" {
+		config.Status.ClusterSecurityGroupID = renderedClusterSecurityGroupID
+	}
+
+	rendered := &RenderedCluster{
+		CreateClusterInput: newClusterInput(config, aws.StringValue(config.Spec.ServiceRole)),
+	}
+	for _, group := range config.Spec.NodeGroups {
+		renderedGroup := RenderedNodeGroup{
+			CreateNodegroupInput: newNodegroupInput(config, group),
+		}
+		if group.LaunchTemplate != nil {
+			renderedGroup.CreateNodegroupInput.LaunchTemplate = &eks.LaunchTemplateSpecification{
+				Id: group.LaunchTemplate.ID,
+			}
+			if version := aws.Int64Value(group.LaunchTemplate.Version); version != 0 {
+				renderedGroup.CreateNodegroupInput.LaunchTemplate.Version = aws.String(fmt.Sprint(version))
+			}
+		} else {
+			launchTemplateData, err := buildLaunchTemplateData(nil, config.Status.ClusterSecurityGroupID, group)
+			if err != nil {
+				return nil, fmt.Errorf("error building launch template data for nodegroup [%s]: %w", aws.StringValue(group.NodegroupName), err)
+			}
+			renderedGroup.LaunchTemplateData = launchTemplateData
+		}
+		rendered.NodeGroups = append(rendered.NodeGroups, renderedGroup)
+	}
+
+	return rendered, nil
+}
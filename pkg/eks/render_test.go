@@ -0,0 +1,78 @@
+package eks
+
+import (
+	"encoding/base64"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+)
+
+var _ = Describe("RenderCluster", func() {
+	var config *eksv1.EKSClusterConfig
+
+	BeforeEach(func() {
+		config = &eksv1.EKSClusterConfig{
+			Spec: eksv1.EKSClusterConfigSpec{
+				DisplayName:       "test",
+				KubernetesVersion: aws.String("1.27"),
+				ServiceRole:       aws.String("test-service-role"),
+				Subnets:           []string{"subnet-1", "subnet-2"},
+				SecurityGroups:    []string{"sg-1"},
+				NodeGroups: []eksv1.NodeGroup{
+					{
+						NodegroupName: aws.String("ng-managed"),
+						InstanceType:  aws.String("t3.medium"),
+						DesiredSize:   aws.Int64(1),
+						MinSize:       aws.Int64(1),
+						MaxSize:       aws.Int64(2),
+						UserData:      aws.String("MIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=\"==MYBOUNDARY==\"\r\n\r\n--==MYBOUNDARY==\r\n\r\n--==MYBOUNDARY==--"),
+					},
+					{
+						NodegroupName: aws.String("ng-custom"),
+						NodeRole:      aws.String("test-node-role"),
+						LaunchTemplate: &eksv1.LaunchTemplate{
+							ID:      aws.String("lt-1"),
+							Version: aws.Int64(3),
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("should render the cluster and nodegroup inputs", func() {
+		rendered, err := RenderCluster(&RenderClusterOpts{Config: config})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(aws.StringValue(rendered.CreateClusterInput.Name)).To(Equal("test"))
+		Expect(aws.StringValue(rendered.CreateClusterInput.RoleArn)).To(Equal("test-service-role"))
+		Expect(aws.StringValueSlice(rendered.CreateClusterInput.ResourcesVpcConfig.SubnetIds)).To(Equal([]string{"subnet-1", "subnet-2"}))
+		Expect(aws.StringValueSlice(rendered.CreateClusterInput.ResourcesVpcConfig.SecurityGroupIds)).To(Equal([]string{"sg-1"}))
+
+		Expect(rendered.NodeGroups).To(HaveLen(2))
+		managed := rendered.NodeGroups[0]
+		Expect(aws.StringValue(managed.CreateNodegroupInput.CapacityType)).To(Equal(eks.CapacityTypesOnDemand))
+		Expect(aws.StringValueSlice(managed.CreateNodegroupInput.Subnets)).To(Equal([]string{"subnet-1", "subnet-2"}))
+		Expect(managed.CreateNodegroupInput.NodeRole).To(BeNil())
+		Expect(managed.CreateNodegroupInput.LaunchTemplate).To(BeNil())
+		Expect(aws.StringValue(managed.LaunchTemplateData.InstanceType)).To(Equal("t3.medium"))
+		Expect(base64.StdEncoding.DecodeString(aws.StringValue(managed.LaunchTemplateData.UserData))).To(BeEquivalentTo(aws.StringValue(config.Spec.NodeGroups[0].UserData)))
+
+		custom := rendered.NodeGroups[1]
+		Expect(aws.StringValue(custom.CreateNodegroupInput.NodeRole)).To(Equal("test-node-role"))
+		Expect(aws.StringValue(custom.CreateNodegroupInput.LaunchTemplate.Id)).To(Equal("lt-1"))
+		Expect(aws.StringValue(custom.CreateNodegroupInput.LaunchTemplate.Version)).To(Equal("3"))
+		Expect(custom.LaunchTemplateData).To(BeNil())
+	})
+
+	It("should render the cluster security group of nodegroups with security groups as a placeholder", func() {
+		config.Spec.NodeGroups[0].SecurityGroups = []string{"sg-2"}
+
+		rendered, err := RenderCluster(&RenderClusterOpts{Config: config})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(aws.StringValueSlice(rendered.NodeGroups[0].LaunchTemplateData.SecurityGroupIds)).To(Equal([]string{renderedClusterSecurityGroupID, "sg-2"}))
+	})
+})
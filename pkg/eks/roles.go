@@ -0,0 +1,320 @@
+package eks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services"
+)
+
+const (
+	eksServicePrincipal = "eks.amazonaws.com"
+	ec2ServicePrincipal = "ec2.amazonaws.com"
+
+	clusterPolicy            = "AmazonEKSClusterPolicy"
+	workerNodePolicy         = "AmazonEKSWorkerNodePolicy"
+	cniPolicy                = "AmazonEKS_CNI_Policy"
+	containerRegistryPolicy  = "AmazonEC2ContainerRegistryReadOnly"
+	awsManagedPolicyARNInfix = ":iam::aws:policy/"
+)
+
+type ValidateRolesOpts struct {
+	IAMService services.IAMServiceInterface
+	Config     *eksv1.EKSClusterConfig
+}
+
+// ValidateRoles checks the service role and the node roles supplied in the config before the cluster is created.
+// It returns an error listing, for each role, the services its trust policy does not allow to assume it. The AWS
+// managed policies missing from the roles are returned as warnings instead, as customer managed or inline policies
+// can grant the same permissions. Roles generated by the operator are not checked.
+func ValidateRoles(opts *ValidateRolesOpts) ([]string, error) {
+	var warnings, errs []string
+	if roleName := aws.StringValue(opts.Config.Spec.ServiceRole); roleName != "" {
+		roleName = roleNameFromARN(roleName)
+		missingPolicies, err := validateRole(opts.IAMService, roleName, eksServicePrincipal, []string{clusterPolicy})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("serviceRole: %v", err))
+		} else if len(missingPolicies) != 0 {
+			warnings = append(warnings, fmt.Sprintf("serviceRole: %s", missingPoliciesWarning(roleName, missingPolicies)))
+		}
+	}
+
+	type result struct {
+		missingPolicies []string
+		err             error
+	}
+	checked := make(map[string]result)
+	for _, ng := range opts.Config.Spec.NodeGroups {
+		nodeRole := aws.StringValue(ng.NodeRole)
+		if nodeRole == "" {
+			continue
+		}
+		r, ok := checked[nodeRole]
+		if !ok {
+			r.missingPolicies, r.err = validateNodeRole(opts.IAMService, opts.Config, nodeRole)
+			checked[nodeRole] = r
+		}
+		if r.err != nil {
+			errs = append(errs, fmt.Sprintf("nodeGroups[%s].nodeRole: %v", aws.StringValue(ng.NodegroupName), r.err))
+		} else if len(r.missingPolicies) != 0 {
+			warnings = append(warnings, fmt.Sprintf("nodeGroups[%s].nodeRole: %s", aws.StringValue(ng.NodegroupName),
+				missingPoliciesWarning(roleNameFromARN(nodeRole), r.missingPolicies)))
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("invalid roles for cluster [%s]: %s", opts.Config.Name, strings.Join(errs, "; "))
+	}
+
+	return warnings, nil
+}
+
+// validateNodeRole checks that EC2 can assume the node role and that it has the policies nodes need to join the
+// cluster and pull images. The CNI policy is only required for IPv4 clusters, IPv6 clusters need a custom policy.
+func validateNodeRole(iamService services.IAMServiceInterface, config *eksv1.EKSClusterConfig, nodeRole string) ([]string, error) {
+	requiredPolicies := []string{workerNodePolicy, containerRegistryPolicy}
+	if !IsIPv6Cluster(config) {
+		requiredPolicies = append(requiredPolicies, cniPolicy)
+	}
+
+	return validateRole(iamService, roleNameFromARN(nodeRole), ec2ServicePrincipal, requiredPolicies)
+}
+
+// validateRole returns an error if the trust policy of the role does not allow the service to assume it, and the
+// AWS managed policies that are not attached to it otherwise.
+func validateRole(iamService services.IAMServiceInterface, roleName, servicePrincipal string, requiredPolicies []string) ([]string, error) {
+	role, err := iamService.GetRole(&iam.GetRoleInput{
+		RoleName: aws.String(roleName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting role [%s]: %w", roleName, err)
+	}
+
+	trusted, err := trustsService(aws.StringValue(role.Role.AssumeRolePolicyDocument), servicePrincipal)
+	if err != nil {
+		return nil, fmt.Errorf("error reading trust policy of role [%s]: %w", roleName, err)
+	}
+	if !trusted {
+		return nil, fmt.Errorf("role [%s]: trust policy does not allow [%s] to assume the role", roleName, servicePrincipal)
+	}
+
+	attached := make(map[string]bool)
+	input := &iam.ListAttachedRolePoliciesInput{
+		RoleName: aws.String(roleName),
+	}
+	for {
+		output, err := iamService.ListAttachedRolePolicies(input)
+		if err != nil {
+			return nil, fmt.Errorf("error listing policies of role [%s]: %w", roleName, err)
+		}
+		for _, policy := range output.AttachedPolicies {
+			policyARN := aws.StringValue(policy.PolicyArn)
+			if i := strings.Index(policyARN, awsManagedPolicyARNInfix); i != -1 {
+				attached[policyARN[i+len(awsManagedPolicyARNInfix):]] = true
+			}
+		}
+		if !aws.BoolValue(output.IsTruncated) {
+			break
+		}
+		input.Marker = output.Marker
+	}
+	var missingPolicies []string
+	for _, policy := range requiredPolicies {
+		if !attached[policy] {
+			missingPolicies = append(missingPolicies, policy)
+		}
+	}
+
+	return missingPolicies, nil
+}
+
+func missingPoliciesWarning(roleName string, missingPolicies []string) string {
+	return fmt.Sprintf("role [%s] does not have the AWS managed policies [%s], its other policies must grant the same permissions",
+		roleName, strings.Join(missingPolicies, ", "))
+}
+
+type DeleteRoleOpts struct {
+	IAMService services.IAMServiceInterface
+	Role       string
+}
+
+// DeleteRole detaches the policies of the role, removes it from its instance profiles and deletes it. Roles that do
+// not exist are skipped.
+func DeleteRole(opts *DeleteRoleOpts) error {
+	roleName := roleNameFromARN(opts.Role)
+	if _, err := opts.IAMService.GetRole(&iam.GetRoleInput{RoleName: aws.String(roleName)}); err != nil {
+		if roleDoesNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error getting role [%s]: %w", roleName, err)
+	}
+
+	// the policies and instance profiles are listed before they are removed, so that removing them does not
+	// change the pages being listed
+	var policyARNs []*string
+	attachedInput := &iam.ListAttachedRolePoliciesInput{RoleName: aws.String(roleName)}
+	for {
+		output, err := opts.IAMService.ListAttachedRolePolicies(attachedInput)
+		if err != nil {
+			return fmt.Errorf("error listing policies of role [%s]: %w", roleName, err)
+		}
+		for _, policy := range output.AttachedPolicies {
+			policyARNs = append(policyARNs, policy.PolicyArn)
+		}
+		if !aws.BoolValue(output.IsTruncated) {
+			break
+		}
+		attachedInput.Marker = output.Marker
+	}
+
+	var policyNames []*string
+	inlineInput := &iam.ListRolePoliciesInput{RoleName: aws.String(roleName)}
+	for {
+		output, err := opts.IAMService.ListRolePolicies(inlineInput)
+		if err != nil {
+			return fmt.Errorf("error listing inline policies of role [%s]: %w", roleName, err)
+		}
+		policyNames = append(policyNames, output.PolicyNames...)
+		if !aws.BoolValue(output.IsTruncated) {
+			break
+		}
+		inlineInput.Marker = output.Marker
+	}
+
+	var profileNames []*string
+	profilesInput := &iam.ListInstanceProfilesForRoleInput{RoleName: aws.String(roleName)}
+	for {
+		output, err := opts.IAMService.ListInstanceProfilesForRole(profilesInput)
+		if err != nil {
+			return fmt.Errorf("error listing instance profiles of role [%s]: %w", roleName, err)
+		}
+		for _, profile := range output.InstanceProfiles {
+			profileNames = append(profileNames, profile.InstanceProfileName)
+		}
+		if !aws.BoolValue(output.IsTruncated) {
+			break
+		}
+		profilesInput.Marker = output.Marker
+	}
+
+	for _, policyARN := range policyARNs {
+		if _, err := opts.IAMService.DetachRolePolicy(&iam.DetachRolePolicyInput{
+			RoleName:  aws.String(roleName),
+			PolicyArn: policyARN,
+		}); err != nil {
+			return fmt.Errorf("error detaching policy [%s] from role [%s]: %w", aws.StringValue(policyARN), roleName, err)
+		}
+	}
+	for _, policyName := range policyNames {
+		if _, err := opts.IAMService.DeleteRolePolicy(&iam.DeleteRolePolicyInput{
+			RoleName:   aws.String(roleName),
+			PolicyName: policyName,
+		}); err != nil {
+			return fmt.Errorf("error deleting inline policy [%s] of role [%s]: %w", aws.StringValue(policyName), roleName, err)
+		}
+	}
+	for _, profileName := range profileNames {
+		if _, err := opts.IAMService.RemoveRoleFromInstanceProfile(&iam.RemoveRoleFromInstanceProfileInput{
+			RoleName:            aws.String(roleName),
+			InstanceProfileName: profileName,
+		}); err != nil {
+			return fmt.Errorf("error removing role [%s] from instance profile [%s]: %w", roleName, aws.StringValue(profileName), err)
+		}
+	}
+
+	if _, err := opts.IAMService.DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String(roleName)}); err != nil && !roleDoesNotExist(err) {
+		return fmt.Errorf("error deleting role [%s]: %w", roleName, err)
+	}
+
+	return nil
+}
+
+func roleDoesNotExist(err error) bool {
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && awsErr.Code() == iam.ErrCodeNoSuchEntityException
+}
+
+// policyDocument is the part of an IAM policy document needed to check which services can assume a role.
+type policyDocument struct {
+	Statement []struct {
+		Effect string
+		Action stringOrSlice
+		// Principal is either "*" or an object of principals by type
+		Principal json.RawMessage
+	}
+}
+
+// stringOrSlice is a policy element that is either a string or a list of strings.
+type stringOrSlice []string
+
+func (s *stringOrSlice) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*s = []string{value}
+		return nil
+	}
+
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*s = values
+	return nil
+}
+
+// trustsService returns true if the URL encoded trust policy allows the service to assume the role.
+func trustsService(encodedDocument, servicePrincipal string) (bool, error) {
+	document, err := url.QueryUnescape(encodedDocument)
+	if err != nil {
+		return false, err
+	}
+
+	var policy policyDocument
+	if err := json.Unmarshal([]byte(document), &policy); err != nil {
+		return false, err
+	}
+	for _, statement := range policy.Statement {
+		if statement.Effect != "Allow" || !containsAction(statement.Action, "sts:AssumeRole") {
+			continue
+		}
+		var principal struct {
+			Service stringOrSlice
+		}
+		if err := json.Unmarshal(statement.Principal, &principal); err != nil {
+			continue
+		}
+		for _, service := range principal.Service {
+			if service == servicePrincipal {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func containsAction(actions []string, action string) bool {
+	for _, a := range actions {
+		if a == action || a == "sts:*" || a == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// roleNameFromARN returns the name of the role of an ARN, or the value itself if it is already a name.
+func roleNameFromARN(role string) string {
+	if i := strings.LastIndex(role, "/"); i != -1 {
+		return role[i+1:]
+	}
+
+	return role
+}
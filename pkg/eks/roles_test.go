@@ -0,0 +1,193 @@
+package eks
+
+import (
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+)
+
+var _ = Describe("ValidateRoles", func() {
+	var (
+		mockController    *gomock.Controller
+		iamServiceMock    *mock_services.MockIAMServiceInterface
+		validateRolesOpts *ValidateRolesOpts
+	)
+
+	trustPolicy := func(service string) *string {
+		return aws.String(url.QueryEscape(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":["` + service + `"]},"Action":"sts:AssumeRole"}]}`))
+	}
+	attachedPolicies := func(names ...string) *iam.ListAttachedRolePoliciesOutput {
+		output := &iam.ListAttachedRolePoliciesOutput{}
+		for _, name := range names {
+			output.AttachedPolicies = append(output.AttachedPolicies, &iam.AttachedPolicy{
+				PolicyName: aws.String(name),
+				PolicyArn:  aws.String("arn:aws:iam::aws:policy/" + name),
+			})
+		}
+		return output
+	}
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		iamServiceMock = mock_services.NewMockIAMServiceInterface(mockController)
+		validateRolesOpts = &ValidateRolesOpts{
+			IAMService: iamServiceMock,
+			Config: &eksv1.EKSClusterConfig{
+				Spec: eksv1.EKSClusterConfigSpec{
+					ServiceRole: aws.String("service-role"),
+					NodeGroups: []eksv1.NodeGroup{
+						{
+							NodegroupName: aws.String("ng1"),
+							NodeRole:      aws.String("arn:aws:iam::123456789012:role/node-role"),
+						},
+						{
+							NodegroupName: aws.String("ng2"),
+							NodeRole:      aws.String("arn:aws:iam::123456789012:role/node-role"),
+						},
+					},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should accept roles with the required trust and managed policies", func() {
+		iamServiceMock.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("service-role")}).Return(
+			&iam.GetRoleOutput{Role: &iam.Role{AssumeRolePolicyDocument: trustPolicy("eks.amazonaws.com")}}, nil)
+		iamServiceMock.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("service-role")}).Return(
+			attachedPolicies("AmazonEKSClusterPolicy"), nil)
+		iamServiceMock.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("node-role")}).Return(
+			&iam.GetRoleOutput{Role: &iam.Role{AssumeRolePolicyDocument: trustPolicy("ec2.amazonaws.com")}}, nil)
+		iamServiceMock.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("node-role")}).Return(
+			attachedPolicies("AmazonEKSWorkerNodePolicy", "AmazonEKS_CNI_Policy", "AmazonEC2ContainerRegistryReadOnly"), nil)
+
+		warnings, err := ValidateRoles(validateRolesOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("should not check generated roles", func() {
+		validateRolesOpts.Config.Spec.ServiceRole = nil
+		validateRolesOpts.Config.Spec.NodeGroups = []eksv1.NodeGroup{{NodegroupName: aws.String("ng")}}
+
+		warnings, err := ValidateRoles(validateRolesOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("should report the roles whose trust policy does not allow the service", func() {
+		iamServiceMock.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("service-role")}).Return(
+			&iam.GetRoleOutput{Role: &iam.Role{AssumeRolePolicyDocument: trustPolicy("ec2.amazonaws.com")}}, nil)
+		iamServiceMock.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("node-role")}).Return(
+			&iam.GetRoleOutput{Role: &iam.Role{AssumeRolePolicyDocument: trustPolicy("eks.amazonaws.com")}}, nil)
+
+		_, err := ValidateRoles(validateRolesOpts)
+		Expect(err).To(MatchError(ContainSubstring("serviceRole: role [service-role]: trust policy does not allow [eks.amazonaws.com] to assume the role")))
+		Expect(err).To(MatchError(ContainSubstring("nodeGroups[ng1].nodeRole: role [node-role]: trust policy does not allow [ec2.amazonaws.com] to assume the role")))
+		Expect(err).To(MatchError(ContainSubstring("nodeGroups[ng2].nodeRole")))
+	})
+
+	It("should report the AWS managed policies missing from the roles without failing", func() {
+		iamServiceMock.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("service-role")}).Return(
+			&iam.GetRoleOutput{Role: &iam.Role{AssumeRolePolicyDocument: trustPolicy("eks.amazonaws.com")}}, nil)
+		iamServiceMock.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("service-role")}).Return(
+			&iam.ListAttachedRolePoliciesOutput{AttachedPolicies: []*iam.AttachedPolicy{{
+				PolicyName: aws.String("cluster"),
+				PolicyArn:  aws.String("arn:aws:iam::123456789012:policy/cluster"),
+			}}}, nil)
+		iamServiceMock.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("node-role")}).Return(
+			&iam.GetRoleOutput{Role: &iam.Role{AssumeRolePolicyDocument: trustPolicy("ec2.amazonaws.com")}}, nil)
+		iamServiceMock.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("node-role")}).Return(
+			attachedPolicies("AmazonEKS_CNI_Policy"), nil)
+
+		warnings, err := ValidateRoles(validateRolesOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warnings).To(Equal([]string{
+			"serviceRole: role [service-role] does not have the AWS managed policies [AmazonEKSClusterPolicy], its other policies must grant the same permissions",
+			"nodeGroups[ng1].nodeRole: role [node-role] does not have the AWS managed policies [AmazonEKSWorkerNodePolicy, AmazonEC2ContainerRegistryReadOnly], its other policies must grant the same permissions",
+			"nodeGroups[ng2].nodeRole: role [node-role] does not have the AWS managed policies [AmazonEKSWorkerNodePolicy, AmazonEC2ContainerRegistryReadOnly], its other policies must grant the same permissions",
+		}))
+	})
+
+	It("should not require the CNI policy for IPv6 clusters", func() {
+		validateRolesOpts.Config.Spec.ServiceRole = nil
+		validateRolesOpts.Config.Spec.KubernetesNetworkConfig = &eksv1.KubernetesNetworkConfig{IPFamily: aws.String(eks.IpFamilyIpv6)}
+		iamServiceMock.EXPECT().GetRole(gomock.Any()).Return(
+			&iam.GetRoleOutput{Role: &iam.Role{AssumeRolePolicyDocument: trustPolicy("ec2.amazonaws.com")}}, nil)
+		iamServiceMock.EXPECT().ListAttachedRolePolicies(gomock.Any()).Return(
+			attachedPolicies("AmazonEKSWorkerNodePolicy", "AmazonEC2ContainerRegistryReadOnly"), nil)
+
+		warnings, err := ValidateRoles(validateRolesOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+	})
+})
+
+var _ = Describe("DeleteRole", func() {
+	var (
+		mockController *gomock.Controller
+		iamServiceMock *mock_services.MockIAMServiceInterface
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		iamServiceMock = mock_services.NewMockIAMServiceInterface(mockController)
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should detach the policies and instance profiles of the role before deleting it", func() {
+		iamServiceMock.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("nodes")}).Return(&iam.GetRoleOutput{}, nil)
+		iamServiceMock.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("nodes")}).
+			Return(&iam.ListAttachedRolePoliciesOutput{
+				AttachedPolicies: []*iam.AttachedPolicy{{PolicyArn: aws.String("arn:aws:iam::aws:policy/AmazonEKSWorkerNodePolicy")}},
+			}, nil)
+		iamServiceMock.EXPECT().ListRolePolicies(&iam.ListRolePoliciesInput{RoleName: aws.String("nodes")}).
+			Return(&iam.ListRolePoliciesOutput{PolicyNames: aws.StringSlice([]string{"inline"})}, nil)
+		iamServiceMock.EXPECT().ListInstanceProfilesForRole(&iam.ListInstanceProfilesForRoleInput{RoleName: aws.String("nodes")}).
+			Return(&iam.ListInstanceProfilesForRoleOutput{
+				InstanceProfiles: []*iam.InstanceProfile{{InstanceProfileName: aws.String("nodes-profile")}},
+			}, nil)
+		iamServiceMock.EXPECT().DetachRolePolicy(&iam.DetachRolePolicyInput{
+			RoleName:  aws.String("nodes"),
+			PolicyArn: aws.String("arn:aws:iam::aws:policy/AmazonEKSWorkerNodePolicy"),
+		}).Return(nil, nil)
+		iamServiceMock.EXPECT().DeleteRolePolicy(&iam.DeleteRolePolicyInput{
+			RoleName:   aws.String("nodes"),
+			PolicyName: aws.String("inline"),
+		}).Return(nil, nil)
+		iamServiceMock.EXPECT().RemoveRoleFromInstanceProfile(&iam.RemoveRoleFromInstanceProfileInput{
+			RoleName:            aws.String("nodes"),
+			InstanceProfileName: aws.String("nodes-profile"),
+		}).Return(nil, nil)
+		iamServiceMock.EXPECT().DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("nodes")}).Return(nil, nil)
+
+		Expect(DeleteRole(&DeleteRoleOpts{
+			IAMService: iamServiceMock,
+			Role:       "arn:aws:iam::123456789012:role/nodes",
+		})).To(Succeed())
+	})
+
+	It("should skip roles that do not exist", func() {
+		iamServiceMock.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("nodes")}).
+			Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "role not found", nil))
+
+		Expect(DeleteRole(&DeleteRoleOpts{
+			IAMService: iamServiceMock,
+			Role:       "nodes",
+		})).To(Succeed())
+	})
+})
@@ -11,6 +11,7 @@ type EKSServiceInterface interface {
 	ListClusters(input *eks.ListClustersInput) (*eks.ListClustersOutput, error)
 	DescribeCluster(input *eks.DescribeClusterInput) (*eks.DescribeClusterOutput, error)
 	UpdateClusterConfig(input *eks.UpdateClusterConfigInput) (*eks.UpdateClusterConfigOutput, error)
+	AssociateEncryptionConfig(input *eks.AssociateEncryptionConfigInput) (*eks.AssociateEncryptionConfigOutput, error)
 	UpdateClusterVersion(input *eks.UpdateClusterVersionInput) (*eks.UpdateClusterVersionOutput, error)
 	CreateNodegroup(input *eks.CreateNodegroupInput) (*eks.CreateNodegroupOutput, error)
 	UpdateNodegroupConfig(input *eks.UpdateNodegroupConfigInput) (*eks.UpdateNodegroupConfigOutput, error)
@@ -22,6 +23,11 @@ type EKSServiceInterface interface {
 	UntagResource(input *eks.UntagResourceInput) (*eks.UntagResourceOutput, error)
 	CreateAddon(input *eks.CreateAddonInput) (*eks.CreateAddonOutput, error)
 	DescribeAddon(input *eks.DescribeAddonInput) (*eks.DescribeAddonOutput, error)
+	DescribeUpdate(input *eks.DescribeUpdateInput) (*eks.DescribeUpdateOutput, error)
+	ListAddons(input *eks.ListAddonsInput) (*eks.ListAddonsOutput, error)
+	DescribeAddonVersions(input *eks.DescribeAddonVersionsInput) (*eks.DescribeAddonVersionsOutput, error)
+	ListInsights(input *eks.ListInsightsInput) (*eks.ListInsightsOutput, error)
+	DescribeInsight(input *eks.DescribeInsightInput) (*eks.DescribeInsightOutput, error)
 }
 
 type eksService struct {
@@ -74,6 +80,10 @@ func (c *eksService) DescribeNodegroup(input *eks.DescribeNodegroupInput) (*eks.
 	return c.svc.DescribeNodegroup(input)
 }
 
+func (c *eksService) AssociateEncryptionConfig(input *eks.AssociateEncryptionConfigInput) (*eks.AssociateEncryptionConfigOutput, error) {
+	return c.svc.AssociateEncryptionConfig(input)
+}
+
 func (c *eksService) UpdateClusterVersion(input *eks.UpdateClusterVersionInput) (*eks.UpdateClusterVersionOutput, error) {
 	return c.svc.UpdateClusterVersion(input)
 }
@@ -97,3 +107,23 @@ func (c *eksService) CreateAddon(input *eks.CreateAddonInput) (*eks.CreateAddonO
 func (c *eksService) DescribeAddon(input *eks.DescribeAddonInput) (*eks.DescribeAddonOutput, error) {
 	return c.svc.DescribeAddon(input)
 }
+
+func (c *eksService) DescribeUpdate(input *eks.DescribeUpdateInput) (*eks.DescribeUpdateOutput, error) {
+	return c.svc.DescribeUpdate(input)
+}
+
+func (c *eksService) ListAddons(input *eks.ListAddonsInput) (*eks.ListAddonsOutput, error) {
+	return c.svc.ListAddons(input)
+}
+
+func (c *eksService) DescribeAddonVersions(input *eks.DescribeAddonVersionsInput) (*eks.DescribeAddonVersionsOutput, error) {
+	return c.svc.DescribeAddonVersions(input)
+}
+
+func (c *eksService) ListInsights(input *eks.ListInsightsInput) (*eks.ListInsightsOutput, error) {
+	return c.svc.ListInsights(input)
+}
+
+func (c *eksService) DescribeInsight(input *eks.DescribeInsightInput) (*eks.DescribeInsightOutput, error) {
+	return c.svc.DescribeInsight(input)
+}
@@ -7,8 +7,15 @@ import (
 
 type IAMServiceInterface interface {
 	GetRole(input *iam.GetRoleInput) (*iam.GetRoleOutput, error)
+	ListAttachedRolePolicies(input *iam.ListAttachedRolePoliciesInput) (*iam.ListAttachedRolePoliciesOutput, error)
 	ListOIDCProviders(input *iam.ListOpenIDConnectProvidersInput) (*iam.ListOpenIDConnectProvidersOutput, error)
 	CreateOIDCProvider(input *iam.CreateOpenIDConnectProviderInput) (*iam.CreateOpenIDConnectProviderOutput, error)
+	DetachRolePolicy(input *iam.DetachRolePolicyInput) (*iam.DetachRolePolicyOutput, error)
+	ListRolePolicies(input *iam.ListRolePoliciesInput) (*iam.ListRolePoliciesOutput, error)
+	DeleteRolePolicy(input *iam.DeleteRolePolicyInput) (*iam.DeleteRolePolicyOutput, error)
+	ListInstanceProfilesForRole(input *iam.ListInstanceProfilesForRoleInput) (*iam.ListInstanceProfilesForRoleOutput, error)
+	RemoveRoleFromInstanceProfile(input *iam.RemoveRoleFromInstanceProfileInput) (*iam.RemoveRoleFromInstanceProfileOutput, error)
+	DeleteRole(input *iam.DeleteRoleInput) (*iam.DeleteRoleOutput, error)
 }
 
 type iamService struct {
@@ -25,6 +32,10 @@ func (c *iamService) GetRole(input *iam.GetRoleInput) (*iam.GetRoleOutput, error
 	return c.svc.GetRole(input)
 }
 
+func (c *iamService) ListAttachedRolePolicies(input *iam.ListAttachedRolePoliciesInput) (*iam.ListAttachedRolePoliciesOutput, error) {
+	return c.svc.ListAttachedRolePolicies(input)
+}
+
 func (c *iamService) ListOIDCProviders(input *iam.ListOpenIDConnectProvidersInput) (*iam.ListOpenIDConnectProvidersOutput, error) {
 	return c.svc.ListOpenIDConnectProviders(input)
 }
@@ -32,3 +43,27 @@ func (c *iamService) ListOIDCProviders(input *iam.ListOpenIDConnectProvidersInpu
 func (c *iamService) CreateOIDCProvider(input *iam.CreateOpenIDConnectProviderInput) (*iam.CreateOpenIDConnectProviderOutput, error) {
 	return c.svc.CreateOpenIDConnectProvider(input)
 }
+
+func (c *iamService) DetachRolePolicy(input *iam.DetachRolePolicyInput) (*iam.DetachRolePolicyOutput, error) {
+	return c.svc.DetachRolePolicy(input)
+}
+
+func (c *iamService) ListRolePolicies(input *iam.ListRolePoliciesInput) (*iam.ListRolePoliciesOutput, error) {
+	return c.svc.ListRolePolicies(input)
+}
+
+func (c *iamService) DeleteRolePolicy(input *iam.DeleteRolePolicyInput) (*iam.DeleteRolePolicyOutput, error) {
+	return c.svc.DeleteRolePolicy(input)
+}
+
+func (c *iamService) ListInstanceProfilesForRole(input *iam.ListInstanceProfilesForRoleInput) (*iam.ListInstanceProfilesForRoleOutput, error) {
+	return c.svc.ListInstanceProfilesForRole(input)
+}
+
+func (c *iamService) RemoveRoleFromInstanceProfile(input *iam.RemoveRoleFromInstanceProfileInput) (*iam.RemoveRoleFromInstanceProfileOutput, error) {
+	return c.svc.RemoveRoleFromInstanceProfile(input)
+}
+
+func (c *iamService) DeleteRole(input *iam.DeleteRoleInput) (*iam.DeleteRoleOutput, error) {
+	return c.svc.DeleteRole(input)
+}
@@ -6,3 +6,5 @@ package mock_services
 //go:generate ../../../../bin/mockgen -destination eks_mock.go -package mock_services -source ../eks.go EKSServiceInterface
 //go:generate ../../../../bin/mockgen -destination iam_mock.go -package mock_services -source ../iam.go IAMServiceInterface
 //go:generate ../../../../bin/mockgen -destination ec2_mock.go -package mock_services -source ../ec2.go EC2ServiceInterface
+//go:generate ../../../../bin/mockgen -destination sts_mock.go -package mock_services -source ../sts.go STSServiceInterface
+//go:generate ../../../../bin/mockgen -destination servicequotas_mock.go -package mock_services -source ../servicequotas.go ServiceQuotasServiceInterface
@@ -34,6 +34,21 @@ func (m *MockEKSServiceInterface) EXPECT() *MockEKSServiceInterfaceMockRecorder
 	return m.recorder
 }
 
+// AssociateEncryptionConfig mocks base method.
+func (m *MockEKSServiceInterface) AssociateEncryptionConfig(input *eks.AssociateEncryptionConfigInput) (*eks.AssociateEncryptionConfigOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssociateEncryptionConfig", input)
+	ret0, _ := ret[0].(*eks.AssociateEncryptionConfigOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssociateEncryptionConfig indicates an expected call of AssociateEncryptionConfig.
+func (mr *MockEKSServiceInterfaceMockRecorder) AssociateEncryptionConfig(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssociateEncryptionConfig", reflect.TypeOf((*MockEKSServiceInterface)(nil).AssociateEncryptionConfig), input)
+}
+
 // CreateAddon mocks base method.
 func (m *MockEKSServiceInterface) CreateAddon(input *eks.CreateAddonInput) (*eks.CreateAddonOutput, error) {
 	m.ctrl.T.Helper()
@@ -124,6 +139,21 @@ func (mr *MockEKSServiceInterfaceMockRecorder) DescribeAddon(input interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeAddon", reflect.TypeOf((*MockEKSServiceInterface)(nil).DescribeAddon), input)
 }
 
+// DescribeAddonVersions mocks base method.
+func (m *MockEKSServiceInterface) DescribeAddonVersions(input *eks.DescribeAddonVersionsInput) (*eks.DescribeAddonVersionsOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeAddonVersions", input)
+	ret0, _ := ret[0].(*eks.DescribeAddonVersionsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeAddonVersions indicates an expected call of DescribeAddonVersions.
+func (mr *MockEKSServiceInterfaceMockRecorder) DescribeAddonVersions(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeAddonVersions", reflect.TypeOf((*MockEKSServiceInterface)(nil).DescribeAddonVersions), input)
+}
+
 // DescribeCluster mocks base method.
 func (m *MockEKSServiceInterface) DescribeCluster(input *eks.DescribeClusterInput) (*eks.DescribeClusterOutput, error) {
 	m.ctrl.T.Helper()
@@ -139,6 +169,21 @@ func (mr *MockEKSServiceInterfaceMockRecorder) DescribeCluster(input interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeCluster", reflect.TypeOf((*MockEKSServiceInterface)(nil).DescribeCluster), input)
 }
 
+// DescribeInsight mocks base method.
+func (m *MockEKSServiceInterface) DescribeInsight(input *eks.DescribeInsightInput) (*eks.DescribeInsightOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeInsight", input)
+	ret0, _ := ret[0].(*eks.DescribeInsightOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeInsight indicates an expected call of DescribeInsight.
+func (mr *MockEKSServiceInterfaceMockRecorder) DescribeInsight(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeInsight", reflect.TypeOf((*MockEKSServiceInterface)(nil).DescribeInsight), input)
+}
+
 // DescribeNodegroup mocks base method.
 func (m *MockEKSServiceInterface) DescribeNodegroup(input *eks.DescribeNodegroupInput) (*eks.DescribeNodegroupOutput, error) {
 	m.ctrl.T.Helper()
@@ -154,6 +199,36 @@ func (mr *MockEKSServiceInterfaceMockRecorder) DescribeNodegroup(input interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeNodegroup", reflect.TypeOf((*MockEKSServiceInterface)(nil).DescribeNodegroup), input)
 }
 
+// DescribeUpdate mocks base method.
+func (m *MockEKSServiceInterface) DescribeUpdate(input *eks.DescribeUpdateInput) (*eks.DescribeUpdateOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeUpdate", input)
+	ret0, _ := ret[0].(*eks.DescribeUpdateOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeUpdate indicates an expected call of DescribeUpdate.
+func (mr *MockEKSServiceInterfaceMockRecorder) DescribeUpdate(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeUpdate", reflect.TypeOf((*MockEKSServiceInterface)(nil).DescribeUpdate), input)
+}
+
+// ListAddons mocks base method.
+func (m *MockEKSServiceInterface) ListAddons(input *eks.ListAddonsInput) (*eks.ListAddonsOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAddons", input)
+	ret0, _ := ret[0].(*eks.ListAddonsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAddons indicates an expected call of ListAddons.
+func (mr *MockEKSServiceInterfaceMockRecorder) ListAddons(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAddons", reflect.TypeOf((*MockEKSServiceInterface)(nil).ListAddons), input)
+}
+
 // ListClusters mocks base method.
 func (m *MockEKSServiceInterface) ListClusters(input *eks.ListClustersInput) (*eks.ListClustersOutput, error) {
 	m.ctrl.T.Helper()
@@ -169,6 +244,21 @@ func (mr *MockEKSServiceInterfaceMockRecorder) ListClusters(input interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusters", reflect.TypeOf((*MockEKSServiceInterface)(nil).ListClusters), input)
 }
 
+// ListInsights mocks base method.
+func (m *MockEKSServiceInterface) ListInsights(input *eks.ListInsightsInput) (*eks.ListInsightsOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInsights", input)
+	ret0, _ := ret[0].(*eks.ListInsightsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInsights indicates an expected call of ListInsights.
+func (mr *MockEKSServiceInterfaceMockRecorder) ListInsights(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInsights", reflect.TypeOf((*MockEKSServiceInterface)(nil).ListInsights), input)
+}
+
 // ListNodegroups mocks base method.
 func (m *MockEKSServiceInterface) ListNodegroups(input *eks.ListNodegroupsInput) (*eks.ListNodegroupsOutput, error) {
 	m.ctrl.T.Helper()
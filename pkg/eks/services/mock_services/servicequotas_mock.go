@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../servicequotas.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	reflect "reflect"
+
+	servicequotas "github.com/aws/aws-sdk-go/service/servicequotas"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockServiceQuotasServiceInterface is a mock of ServiceQuotasServiceInterface interface.
+type MockServiceQuotasServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceQuotasServiceInterfaceMockRecorder
+}
+
+// MockServiceQuotasServiceInterfaceMockRecorder is the mock recorder for MockServiceQuotasServiceInterface.
+type MockServiceQuotasServiceInterfaceMockRecorder struct {
+	mock *MockServiceQuotasServiceInterface
+}
+
+// NewMockServiceQuotasServiceInterface creates a new mock instance.
+func NewMockServiceQuotasServiceInterface(ctrl *gomock.Controller) *MockServiceQuotasServiceInterface {
+	mock := &MockServiceQuotasServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockServiceQuotasServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServiceQuotasServiceInterface) EXPECT() *MockServiceQuotasServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// GetServiceQuota mocks base method.
+func (m *MockServiceQuotasServiceInterface) GetServiceQuota(input *servicequotas.GetServiceQuotaInput) (*servicequotas.GetServiceQuotaOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServiceQuota", input)
+	ret0, _ := ret[0].(*servicequotas.GetServiceQuotaOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServiceQuota indicates an expected call of GetServiceQuota.
+func (mr *MockServiceQuotasServiceInterfaceMockRecorder) GetServiceQuota(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceQuota", reflect.TypeOf((*MockServiceQuotasServiceInterface)(nil).GetServiceQuota), input)
+}
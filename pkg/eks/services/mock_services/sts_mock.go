@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../sts.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	reflect "reflect"
+
+	request "github.com/aws/aws-sdk-go/aws/request"
+	sts "github.com/aws/aws-sdk-go/service/sts"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSTSServiceInterface is a mock of STSServiceInterface interface.
+type MockSTSServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSTSServiceInterfaceMockRecorder
+}
+
+// MockSTSServiceInterfaceMockRecorder is the mock recorder for MockSTSServiceInterface.
+type MockSTSServiceInterfaceMockRecorder struct {
+	mock *MockSTSServiceInterface
+}
+
+// NewMockSTSServiceInterface creates a new mock instance.
+func NewMockSTSServiceInterface(ctrl *gomock.Controller) *MockSTSServiceInterface {
+	mock := &MockSTSServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockSTSServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSTSServiceInterface) EXPECT() *MockSTSServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// GetCallerIdentityRequest mocks base method.
+func (m *MockSTSServiceInterface) GetCallerIdentityRequest(input *sts.GetCallerIdentityInput) (*request.Request, *sts.GetCallerIdentityOutput) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCallerIdentityRequest", input)
+	ret0, _ := ret[0].(*request.Request)
+	ret1, _ := ret[1].(*sts.GetCallerIdentityOutput)
+	return ret0, ret1
+}
+
+// GetCallerIdentityRequest indicates an expected call of GetCallerIdentityRequest.
+func (mr *MockSTSServiceInterfaceMockRecorder) GetCallerIdentityRequest(input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCallerIdentityRequest", reflect.TypeOf((*MockSTSServiceInterface)(nil).GetCallerIdentityRequest), input)
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+)
+
+type ServiceQuotasServiceInterface interface {
+	GetServiceQuota(input *servicequotas.GetServiceQuotaInput) (*servicequotas.GetServiceQuotaOutput, error)
+}
+
+type serviceQuotasService struct {
+	svc *servicequotas.ServiceQuotas
+}
+
+func NewServiceQuotasService(sess *session.Session) ServiceQuotasServiceInterface {
+	return &serviceQuotasService{
+		svc: servicequotas.New(sess),
+	}
+}
+
+func (c *serviceQuotasService) GetServiceQuota(input *servicequotas.GetServiceQuotaInput) (*servicequotas.GetServiceQuotaOutput, error) {
+	return c.svc.GetServiceQuota(input)
+}
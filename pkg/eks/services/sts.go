@@ -0,0 +1,25 @@
+package services
+
+import (
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+type STSServiceInterface interface {
+	GetCallerIdentityRequest(input *sts.GetCallerIdentityInput) (*request.Request, *sts.GetCallerIdentityOutput)
+}
+
+type stsService struct {
+	svc *sts.STS
+}
+
+func NewSTSService(sess *session.Session) STSServiceInterface {
+	return &stsService{
+		svc: sts.New(sess),
+	}
+}
+
+func (c *stsService) GetCallerIdentityRequest(input *sts.GetCallerIdentityInput) (*request.Request, *sts.GetCallerIdentityOutput) {
+	return c.svc.GetCallerIdentityRequest(input)
+}
@@ -1,9 +1,13 @@
 package eks
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/eks"
 	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
 	"github.com/rancher/eks-operator/pkg/eks/services"
@@ -13,6 +17,10 @@ import (
 
 const (
 	allOpen = "0.0.0.0/0"
+
+	// UpgradeInsightsAcknowledgedAnnotation is set to a kubernetes version to upgrade the cluster to that version
+	// even though EKS upgrade insights report errors for it.
+	UpgradeInsightsAcknowledgedAnnotation = "eks.cattle.io/acknowledge-upgrade-insights"
 )
 
 type UpdateClusterVersionOpts struct {
@@ -21,21 +29,103 @@ type UpdateClusterVersionOpts struct {
 	UpstreamClusterSpec *eksv1.EKSClusterConfigSpec
 }
 
-func UpdateClusterVersion(opts *UpdateClusterVersionOpts) (bool, error) {
-	updated := false
+// UpdateClusterVersion starts an update of the kubernetes version of the cluster if it differs from the upstream
+// cluster, the started update is returned. The control plane is upgraded to the next minor version only, as EKS
+// does not accept larger upgrades.
+func UpdateClusterVersion(opts *UpdateClusterVersionOpts) (*eks.Update, error) {
 	if aws.StringValue(opts.UpstreamClusterSpec.KubernetesVersion) != aws.StringValue(opts.Config.Spec.KubernetesVersion) {
-		logrus.Infof("updating kubernetes version for cluster [%s]", opts.Config.Name)
-		_, err := opts.EKSService.UpdateClusterVersion(&eks.UpdateClusterVersionInput{
+		steps, err := GetUpgradeSteps(aws.StringValue(opts.UpstreamClusterSpec.KubernetesVersion), aws.StringValue(opts.Config.Spec.KubernetesVersion))
+		if err != nil {
+			return nil, fmt.Errorf("error updating cluster [%s] kubernetes version: %w", opts.Config.Name, err)
+		}
+
+		logrus.Infof("updating kubernetes version for cluster [%s] to [%s]", opts.Config.Name, steps[0])
+		output, err := opts.EKSService.UpdateClusterVersion(&eks.UpdateClusterVersionInput{
 			Name:    aws.String(opts.Config.Spec.DisplayName),
-			Version: opts.Config.Spec.KubernetesVersion,
+			Version: aws.String(steps[0]),
 		})
 		if err != nil {
-			return updated, fmt.Errorf("error updating cluster [%s] kubernetes version: %w", opts.Config.Name, err)
+			return nil, fmt.Errorf("error updating cluster [%s] kubernetes version: %w", opts.Config.Name, err)
 		}
-		updated = true
+		if output == nil {
+			return nil, nil
+		}
+		return output.Update, nil
 	}
 
-	return updated, nil
+	return nil, nil
+}
+
+type CheckUpgradeInsightsOpts struct {
+	EKSService        services.EKSServiceInterface
+	Config            *eksv1.EKSClusterConfig
+	KubernetesVersion string
+}
+
+// CheckUpgradeInsights returns the upgrade readiness insights of EKS with a warning or error status for the given
+// kubernetes version, such as deprecated API usage or kubelet version skew. It also returns true if the upgrade must
+// be blocked, which is the case when an insight has an error status and the findings for the version have not been
+// acknowledged with the UpgradeInsightsAcknowledgedAnnotation. Clusters whose credentials cannot read insights are
+// not blocked.
+func CheckUpgradeInsights(opts *CheckUpgradeInsightsOpts) ([]eksv1.UpgradeInsight, bool, error) {
+	input := &eks.ListInsightsInput{
+		ClusterName: aws.String(opts.Config.Spec.DisplayName),
+		Filter: &eks.InsightsFilter{
+			Categories:         aws.StringSlice([]string{eks.CategoryUpgradeReadiness}),
+			KubernetesVersions: aws.StringSlice([]string{opts.KubernetesVersion}),
+			Statuses:           aws.StringSlice([]string{eks.InsightStatusValueWarning, eks.InsightStatusValueError}),
+		},
+	}
+
+	var summaries []*eks.InsightSummary
+	for {
+		output, err := opts.EKSService.ListInsights(input)
+		if err != nil {
+			var awsErr awserr.Error
+			if errors.As(err, &awsErr) && awsErr.Code() == eks.ErrCodeAccessDeniedException {
+				logrus.Warnf("cannot check upgrade insights of cluster [%s]: %v", opts.Config.Name, err)
+				return nil, false, nil
+			}
+			return nil, false, fmt.Errorf("error listing upgrade insights of cluster [%s]: %w", opts.Config.Name, err)
+		}
+		summaries = append(summaries, output.Insights...)
+		if aws.StringValue(output.NextToken) == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	acknowledged := opts.Config.Annotations[UpgradeInsightsAcknowledgedAnnotation] == opts.KubernetesVersion
+	var insights []eksv1.UpgradeInsight
+	var blocked bool
+	for _, summary := range summaries {
+		insight := eksv1.UpgradeInsight{
+			ID:   aws.StringValue(summary.Id),
+			Name: aws.StringValue(summary.Name),
+		}
+		if summary.InsightStatus != nil {
+			insight.Status = aws.StringValue(summary.InsightStatus.Status)
+			insight.Reason = aws.StringValue(summary.InsightStatus.Reason)
+		}
+
+		output, err := opts.EKSService.DescribeInsight(&eks.DescribeInsightInput{
+			ClusterName: aws.String(opts.Config.Spec.DisplayName),
+			Id:          summary.Id,
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("error describing upgrade insight [%s] of cluster [%s]: %w", insight.Name, opts.Config.Name, err)
+		}
+		if output.Insight != nil {
+			insight.Recommendation = aws.StringValue(output.Insight.Recommendation)
+		}
+
+		if insight.Status == eks.InsightStatusValueError && !acknowledged {
+			blocked = true
+		}
+		insights = append(insights, insight)
+	}
+
+	return insights, blocked, nil
 }
 
 type UpdateResourceTagsOpts struct {
@@ -81,22 +171,26 @@ type UpdateLoggingTypesOpts struct {
 	UpstreamClusterSpec *eksv1.EKSClusterConfigSpec
 }
 
-func UpdateClusterLoggingTypes(opts *UpdateLoggingTypesOpts) (bool, error) {
-	updated := false
+// UpdateClusterLoggingTypes starts an update of the logging types of the cluster if they differ from the upstream
+// cluster, the started update is returned.
+func UpdateClusterLoggingTypes(opts *UpdateLoggingTypesOpts) (*eks.Update, error) {
 	if loggingTypesUpdate := getLoggingTypesUpdate(opts.Config.Spec.LoggingTypes, opts.UpstreamClusterSpec.LoggingTypes); loggingTypesUpdate != nil {
-		_, err := opts.EKSService.UpdateClusterConfig(
+		output, err := opts.EKSService.UpdateClusterConfig(
 			&eks.UpdateClusterConfigInput{
 				Name:    aws.String(opts.Config.Spec.DisplayName),
 				Logging: loggingTypesUpdate,
 			},
 		)
 		if err != nil {
-			return false, fmt.Errorf("error updating cluster [%s] logging types: %w", opts.Config.Name, err)
+			return nil, fmt.Errorf("error updating cluster [%s] logging types: %w", opts.Config.Name, err)
 		}
-		updated = true
+		if output == nil {
+			return nil, nil
+		}
+		return output.Update, nil
 	}
 
-	return updated, nil
+	return nil, nil
 }
 
 type UpdateClusterAccessOpts struct {
@@ -105,15 +199,15 @@ type UpdateClusterAccessOpts struct {
 	UpstreamClusterSpec *eksv1.EKSClusterConfigSpec
 }
 
-func UpdateClusterAccess(opts *UpdateClusterAccessOpts) (bool, error) {
-	updated := false
-
+// UpdateClusterAccess starts an update of the public and private endpoint access of the cluster if it differs from
+// the upstream cluster, the started update is returned.
+func UpdateClusterAccess(opts *UpdateClusterAccessOpts) (*eks.Update, error) {
 	publicAccessUpdate := opts.Config.Spec.PublicAccess != nil && aws.BoolValue(opts.UpstreamClusterSpec.PublicAccess) != aws.BoolValue(opts.Config.Spec.PublicAccess)
 	privateAccessUpdate := opts.Config.Spec.PrivateAccess != nil && aws.BoolValue(opts.UpstreamClusterSpec.PrivateAccess) != aws.BoolValue(opts.Config.Spec.PrivateAccess)
 	if publicAccessUpdate || privateAccessUpdate {
 		// public and private access updates need to be sent together. When they are sent one at a time
 		// the request may be denied due to having both public and private access disabled.
-		_, err := opts.EKSService.UpdateClusterConfig(
+		output, err := opts.EKSService.UpdateClusterConfig(
 			&eks.UpdateClusterConfigInput{
 				Name: aws.String(opts.Config.Spec.DisplayName),
 				ResourcesVpcConfig: &eks.VpcConfigRequest{
@@ -123,12 +217,15 @@ func UpdateClusterAccess(opts *UpdateClusterAccessOpts) (bool, error) {
 			},
 		)
 		if err != nil {
-			return false, fmt.Errorf("error updating cluster [%s] public/private access: %w", opts.Config.Name, err)
+			return nil, fmt.Errorf("error updating cluster [%s] public/private access: %w", opts.Config.Name, err)
 		}
-		updated = true
+		if output == nil {
+			return nil, nil
+		}
+		return output.Update, nil
 	}
 
-	return updated, nil
+	return nil, nil
 }
 
 type UpdateClusterPublicAccessSourcesOpts struct {
@@ -137,14 +234,15 @@ type UpdateClusterPublicAccessSourcesOpts struct {
 	UpstreamClusterSpec *eksv1.EKSClusterConfigSpec
 }
 
-func UpdateClusterPublicAccessSources(opts *UpdateClusterPublicAccessSourcesOpts) (bool, error) {
-	updated := false
+// UpdateClusterPublicAccessSources starts an update of the public access CIDRs of the cluster if they differ from
+// the upstream cluster, the started update is returned.
+func UpdateClusterPublicAccessSources(opts *UpdateClusterPublicAccessSourcesOpts) (*eks.Update, error) {
 	// check public access CIDRs for update (public access sources)
 
 	filteredSpecPublicAccessSources := filterPublicAccessSources(opts.Config.Spec.PublicAccessSources)
 	filteredUpstreamPublicAccessSources := filterPublicAccessSources(opts.UpstreamClusterSpec.PublicAccessSources)
 	if !utils.CompareStringSliceElements(filteredSpecPublicAccessSources, filteredUpstreamPublicAccessSources) {
-		_, err := opts.EKSService.UpdateClusterConfig(
+		output, err := opts.EKSService.UpdateClusterConfig(
 			&eks.UpdateClusterConfigInput{
 				Name: aws.String(opts.Config.Spec.DisplayName),
 				ResourcesVpcConfig: &eks.VpcConfigRequest{
@@ -153,13 +251,135 @@ func UpdateClusterPublicAccessSources(opts *UpdateClusterPublicAccessSourcesOpts
 			},
 		)
 		if err != nil {
-			return false, fmt.Errorf("error updating cluster [%s] public access sources: %w", opts.Config.Name, err)
+			return nil, fmt.Errorf("error updating cluster [%s] public access sources: %w", opts.Config.Name, err)
 		}
 
-		updated = true
+		if output == nil {
+			return nil, nil
+		}
+		return output.Update, nil
 	}
 
-	return updated, nil
+	return nil, nil
+}
+
+type UpdateClusterNetworkOpts struct {
+	EKSService          services.EKSServiceInterface
+	EC2Service          services.EC2ServiceInterface
+	Config              *eksv1.EKSClusterConfig
+	UpstreamClusterSpec *eksv1.EKSClusterConfigSpec
+	// VPCID is the VPC of the cluster, which the subnets must be in
+	VPCID string
+}
+
+// UpdateClusterNetwork starts an update of the subnets and security groups of the control plane if they differ from
+// the upstream cluster, the started update is returned. They are only managed if the config provides its subnets and
+// the subnets must be in the VPC of the cluster.
+func UpdateClusterNetwork(opts *UpdateClusterNetworkOpts) (*eks.Update, error) {
+	spec := opts.Config.Spec
+	if len(spec.Subnets) == 0 {
+		return nil, nil
+	}
+
+	vpcConfig := &eks.VpcConfigRequest{}
+	if !utils.CompareStringSliceElements(spec.Subnets, opts.UpstreamClusterSpec.Subnets) {
+		if err := validateSubnetsVPC(opts.EC2Service, spec.Subnets, opts.VPCID); err != nil {
+			return nil, fmt.Errorf("error validating subnets of cluster [%s]: %w", opts.Config.Name, err)
+		}
+		vpcConfig.SubnetIds = aws.StringSlice(spec.Subnets)
+	}
+	if spec.SecurityGroups != nil && !utils.CompareStringSliceElements(spec.SecurityGroups, opts.UpstreamClusterSpec.SecurityGroups) {
+		vpcConfig.SecurityGroupIds = aws.StringSlice(spec.SecurityGroups)
+	}
+	if vpcConfig.SubnetIds == nil && vpcConfig.SecurityGroupIds == nil {
+		return nil, nil
+	}
+
+	output, err := opts.EKSService.UpdateClusterConfig(
+		&eks.UpdateClusterConfigInput{
+			Name:               aws.String(spec.DisplayName),
+			ResourcesVpcConfig: vpcConfig,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error updating cluster [%s] subnets and security groups: %w", opts.Config.Name, err)
+	}
+
+	if output == nil {
+		return nil, nil
+	}
+	return output.Update, nil
+}
+
+// validateSubnetsVPC returns an error if one of the subnets does not exist or is not in the VPC.
+func validateSubnetsVPC(ec2Service services.EC2ServiceInterface, subnets []string, vpcID string) error {
+	output, err := ec2Service.DescribeSubnets(&ec2.DescribeSubnetsInput{
+		SubnetIds: aws.StringSlice(subnets),
+	})
+	if err != nil {
+		return err
+	}
+
+	var outsideSubnets []string
+	for _, subnet := range output.Subnets {
+		if aws.StringValue(subnet.VpcId) != vpcID {
+			outsideSubnets = append(outsideSubnets, aws.StringValue(subnet.SubnetId))
+		}
+	}
+	if len(outsideSubnets) != 0 {
+		return fmt.Errorf("subnets [%s] are not in the vpc [%s] of the cluster", strings.Join(outsideSubnets, ", "), vpcID)
+	}
+
+	return nil
+}
+
+type EnableSecretsEncryptionOpts struct {
+	EKSService          services.EKSServiceInterface
+	Config              *eksv1.EKSClusterConfig
+	UpstreamClusterSpec *eksv1.EKSClusterConfigSpec
+}
+
+// EnableSecretsEncryption starts associating the KMS key of the config, or the one generated for it, with the
+// cluster if secrets encryption is enabled in the config but not on the upstream cluster, the started update is
+// returned. Secrets encryption cannot be disabled and its key cannot be changed once it is enabled.
+func EnableSecretsEncryption(opts *EnableSecretsEncryptionOpts) (*eks.Update, error) {
+	kmsKey := aws.StringValue(opts.Config.Spec.KmsKey)
+	if aws.BoolValue(opts.UpstreamClusterSpec.SecretsEncryption) {
+		if !aws.BoolValue(opts.Config.Spec.SecretsEncryption) {
+			return nil, fmt.Errorf("secrets encryption cannot be disabled for cluster [%s]", opts.Config.Name)
+		}
+		if upstreamKmsKey := aws.StringValue(opts.UpstreamClusterSpec.KmsKey); kmsKey != "" && kmsKey != upstreamKmsKey {
+			return nil, fmt.Errorf("kms key of cluster [%s] cannot be changed from [%s] once secrets encryption is enabled", opts.Config.Name, upstreamKmsKey)
+		}
+		return nil, nil
+	}
+	if !aws.BoolValue(opts.Config.Spec.SecretsEncryption) {
+		return nil, nil
+	}
+	kmsKey = GetKMSKey(opts.Config)
+	if kmsKey == "" {
+		return nil, fmt.Errorf("kms key must be set or generated to enable secrets encryption for cluster [%s]", opts.Config.Name)
+	}
+
+	output, err := opts.EKSService.AssociateEncryptionConfig(&eks.AssociateEncryptionConfigInput{
+		ClusterName: aws.String(opts.Config.Spec.DisplayName),
+		EncryptionConfig: []*eks.EncryptionConfig{
+			{
+				Provider: &eks.Provider{
+					KeyArn: aws.String(kmsKey),
+				},
+				Resources: aws.StringSlice([]string{"secrets"}),
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error enabling secrets encryption for cluster [%s]: %w", opts.Config.Name, err)
+	}
+
+	if output == nil {
+		return nil, nil
+	}
+	return output.Update, nil
 }
 
 type UpdateNodegroupVersionOpts struct {
@@ -171,17 +391,95 @@ type UpdateNodegroupVersionOpts struct {
 	LTVersions     map[string]string
 }
 
-func UpdateNodegroupVersion(opts *UpdateNodegroupVersionOpts) error {
-	if _, err := opts.EKSService.UpdateNodegroupVersion(opts.NGVersionInput); err != nil {
+// UpdateNodegroupVersion sends the version or launch template update of a node group to EKS and returns the update
+// that was started.
+func UpdateNodegroupVersion(opts *UpdateNodegroupVersionOpts) (*eks.Update, error) {
+	output, err := opts.EKSService.UpdateNodegroupVersion(opts.NGVersionInput)
+	if err != nil {
 		if version, ok := opts.LTVersions[aws.StringValue(opts.NodeGroup.NodegroupName)]; ok {
 			// If there was an error updating the node group and a Rancher-managed launch template version was created,
 			// then the version that caused the issue needs to be deleted to prevent bad versions from piling up.
 			DeleteLaunchTemplateVersions(opts.EC2Service, opts.Config.Status.ManagedLaunchTemplateID, []*string{aws.String(version)})
 		}
-		return err
+		return nil, err
 	}
 
-	return nil
+	if output == nil {
+		return nil, nil
+	}
+	return output.Update, nil
+}
+
+type RefreshUpdateOpts struct {
+	EKSService    services.EKSServiceInterface
+	Config        *eksv1.EKSClusterConfig
+	NodegroupName string
+	Update        eksv1.Update
+}
+
+// RefreshUpdate returns the update recorded on the status with the status and errors currently reported by EKS.
+func RefreshUpdate(opts *RefreshUpdateOpts) (eksv1.Update, error) {
+	input := &eks.DescribeUpdateInput{
+		Name:     aws.String(opts.Config.Spec.DisplayName),
+		UpdateId: aws.String(opts.Update.ID),
+	}
+	if opts.NodegroupName != "" {
+		input.NodegroupName = aws.String(opts.NodegroupName)
+	}
+
+	output, err := opts.EKSService.DescribeUpdate(input)
+	if err != nil {
+		return opts.Update, fmt.Errorf("error describing update [%s] of cluster [%s]: %w", opts.Update.ID, opts.Config.Name, err)
+	}
+
+	if output == nil || output.Update == nil {
+		return opts.Update, nil
+	}
+
+	return NewUpdate(output.Update, opts.Update.Generation), nil
+}
+
+// NewUpdate converts an update returned by EKS to its status representation. The generation is the generation of
+// the config the update was sent for.
+func NewUpdate(update *eks.Update, generation int64) eksv1.Update {
+	newUpdate := eksv1.Update{
+		ID:         aws.StringValue(update.Id),
+		Type:       aws.StringValue(update.Type),
+		Status:     aws.StringValue(update.Status),
+		Generation: generation,
+	}
+	for _, updateError := range update.Errors {
+		newUpdate.Errors = append(newUpdate.Errors, eksv1.UpdateError{
+			ErrorCode:    aws.StringValue(updateError.ErrorCode),
+			ErrorMessage: aws.StringValue(updateError.ErrorMessage),
+			ResourceIDs:  aws.StringValueSlice(updateError.ResourceIds),
+		})
+	}
+
+	return newUpdate
+}
+
+// IsUpdateInProgress returns true if EKS has not finished the update.
+func IsUpdateInProgress(update eksv1.Update) bool {
+	return update.ID != "" && update.Status == eks.UpdateStatusInProgress
+}
+
+// UpdateFailureMessage returns a message describing why the update failed, including the error codes reported
+// by EKS.
+func UpdateFailureMessage(update eksv1.Update) string {
+	var updateErrors []string
+	for _, updateError := range update.Errors {
+		message := fmt.Sprintf("%s: %s", updateError.ErrorCode, updateError.ErrorMessage)
+		if len(updateError.ResourceIDs) != 0 {
+			message = fmt.Sprintf("%s [%s]", message, strings.Join(updateError.ResourceIDs, ", "))
+		}
+		updateErrors = append(updateErrors, message)
+	}
+	if len(updateErrors) == 0 {
+		return fmt.Sprintf("%s update [%s] %s", update.Type, update.ID, strings.ToLower(update.Status))
+	}
+
+	return fmt.Sprintf("%s update [%s] %s: %s", update.Type, update.ID, strings.ToLower(update.Status), strings.Join(updateErrors, "; "))
 }
 
 func getLoggingTypesUpdate(loggingTypes []string, upstreamLoggingTypes []string) *eks.Logging {
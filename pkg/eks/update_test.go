@@ -4,6 +4,8 @@ import (
 	"errors"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
@@ -31,11 +33,11 @@ var _ = Describe("UpdateClusterVersion", func() {
 				},
 				Spec: eksv1.EKSClusterConfigSpec{
 					DisplayName:       "test-cluster",
-					KubernetesVersion: aws.String("test1"),
+					KubernetesVersion: aws.String("1.27"),
 				},
 			},
 			UpstreamClusterSpec: &eksv1.EKSClusterConfigSpec{
-				KubernetesVersion: aws.String("test2"),
+				KubernetesVersion: aws.String("1.26"),
 			},
 		}
 	})
@@ -50,23 +52,163 @@ var _ = Describe("UpdateClusterVersion", func() {
 				Name:    aws.String(updateClusterVersionOptions.Config.Spec.DisplayName),
 				Version: updateClusterVersionOptions.Config.Spec.KubernetesVersion,
 			},
-		).Return(nil, nil)
-		updated, err := UpdateClusterVersion(updateClusterVersionOptions)
-		Expect(updated).To(BeTrue())
+		).Return(&eks.UpdateClusterVersionOutput{Update: &eks.Update{Id: aws.String("test")}}, nil)
+		update, err := UpdateClusterVersion(updateClusterVersionOptions)
+		Expect(update).ToNot(BeNil())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not fail if update cluster version returned no output", func() {
+		eksServiceMock.EXPECT().UpdateClusterVersion(gomock.Any()).Return(nil, nil)
+		update, err := UpdateClusterVersion(updateClusterVersionOptions)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(update).To(BeNil())
+	})
+
+	It("should only update cluster version to the next minor version", func() {
+		updateClusterVersionOptions.UpstreamClusterSpec.KubernetesVersion = aws.String("1.24")
+		eksServiceMock.EXPECT().UpdateClusterVersion(
+			&eks.UpdateClusterVersionInput{
+				Name:    aws.String(updateClusterVersionOptions.Config.Spec.DisplayName),
+				Version: aws.String("1.25"),
+			},
+		).Return(&eks.UpdateClusterVersionOutput{Update: &eks.Update{Id: aws.String("test")}}, nil)
+		update, err := UpdateClusterVersion(updateClusterVersionOptions)
+		Expect(update).ToNot(BeNil())
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("should not downgrade cluster version", func() {
+		updateClusterVersionOptions.UpstreamClusterSpec.KubernetesVersion = aws.String("1.28")
+		update, err := UpdateClusterVersion(updateClusterVersionOptions)
+		Expect(update).To(BeNil())
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("should not update cluster version if version didn't change", func() {
-		updateClusterVersionOptions.UpstreamClusterSpec.KubernetesVersion = aws.String("test1")
-		updated, err := UpdateClusterVersion(updateClusterVersionOptions)
-		Expect(updated).To(BeFalse())
+		updateClusterVersionOptions.UpstreamClusterSpec.KubernetesVersion = aws.String("1.27")
+		update, err := UpdateClusterVersion(updateClusterVersionOptions)
+		Expect(update).To(BeNil())
 		Expect(err).NotTo(HaveOccurred())
 	})
 
 	It("should return error if update cluster version failed", func() {
 		eksServiceMock.EXPECT().UpdateClusterVersion(gomock.Any()).Return(nil, errors.New("error updating cluster version"))
-		updated, err := UpdateClusterVersion(updateClusterVersionOptions)
-		Expect(updated).To(BeFalse())
+		update, err := UpdateClusterVersion(updateClusterVersionOptions)
+		Expect(update).To(BeNil())
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("CheckUpgradeInsights", func() {
+	var (
+		mockController              *gomock.Controller
+		eksServiceMock              *mock_services.MockEKSServiceInterface
+		checkUpgradeInsightsOptions *CheckUpgradeInsightsOpts
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		eksServiceMock = mock_services.NewMockEKSServiceInterface(mockController)
+		checkUpgradeInsightsOptions = &CheckUpgradeInsightsOpts{
+			EKSService: eksServiceMock,
+			Config: &eksv1.EKSClusterConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-cluster",
+				},
+				Spec: eksv1.EKSClusterConfigSpec{
+					DisplayName: "test-cluster",
+				},
+			},
+			KubernetesVersion: "1.27",
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	expectInsights := func(summaries ...*eks.InsightSummary) {
+		eksServiceMock.EXPECT().ListInsights(
+			&eks.ListInsightsInput{
+				ClusterName: aws.String("test-cluster"),
+				Filter: &eks.InsightsFilter{
+					Categories:         aws.StringSlice([]string{eks.CategoryUpgradeReadiness}),
+					KubernetesVersions: aws.StringSlice([]string{"1.27"}),
+					Statuses:           aws.StringSlice([]string{eks.InsightStatusValueWarning, eks.InsightStatusValueError}),
+				},
+			},
+		).Return(&eks.ListInsightsOutput{Insights: summaries}, nil)
+		for _, summary := range summaries {
+			eksServiceMock.EXPECT().DescribeInsight(
+				&eks.DescribeInsightInput{
+					ClusterName: aws.String("test-cluster"),
+					Id:          summary.Id,
+				},
+			).Return(&eks.DescribeInsightOutput{Insight: &eks.Insight{Recommendation: aws.String("update the manifests")}}, nil)
+		}
+	}
+
+	insightSummary := func(id, status string) *eks.InsightSummary {
+		return &eks.InsightSummary{
+			Id:            aws.String(id),
+			Name:          aws.String("Deprecated APIs removed in Kubernetes v1.27"),
+			InsightStatus: &eks.InsightStatus{Status: aws.String(status), Reason: aws.String("deprecated API usage detected")},
+		}
+	}
+
+	It("should block upgrade if an insight has an error status", func() {
+		expectInsights(insightSummary("insight-1", eks.InsightStatusValueError))
+		insights, blocked, err := CheckUpgradeInsights(checkUpgradeInsightsOptions)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blocked).To(BeTrue())
+		Expect(insights).To(Equal([]eksv1.UpgradeInsight{
+			{
+				ID:             "insight-1",
+				Name:           "Deprecated APIs removed in Kubernetes v1.27",
+				Status:         eks.InsightStatusValueError,
+				Reason:         "deprecated API usage detected",
+				Recommendation: "update the manifests",
+			},
+		}))
+	})
+
+	It("should not block upgrade if the insights are acknowledged for the version", func() {
+		checkUpgradeInsightsOptions.Config.Annotations = map[string]string{UpgradeInsightsAcknowledgedAnnotation: "1.27"}
+		expectInsights(insightSummary("insight-1", eks.InsightStatusValueError))
+		insights, blocked, err := CheckUpgradeInsights(checkUpgradeInsightsOptions)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blocked).To(BeFalse())
+		Expect(insights).To(HaveLen(1))
+	})
+
+	It("should block upgrade if the insights are acknowledged for another version", func() {
+		checkUpgradeInsightsOptions.Config.Annotations = map[string]string{UpgradeInsightsAcknowledgedAnnotation: "1.26"}
+		expectInsights(insightSummary("insight-1", eks.InsightStatusValueError))
+		_, blocked, err := CheckUpgradeInsights(checkUpgradeInsightsOptions)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blocked).To(BeTrue())
+	})
+
+	It("should not block upgrade for insights with a warning status", func() {
+		expectInsights(insightSummary("insight-1", eks.InsightStatusValueWarning))
+		insights, blocked, err := CheckUpgradeInsights(checkUpgradeInsightsOptions)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blocked).To(BeFalse())
+		Expect(insights).To(HaveLen(1))
+	})
+
+	It("should not block upgrade if insights cannot be read", func() {
+		eksServiceMock.EXPECT().ListInsights(gomock.Any()).Return(nil, awserr.New(eks.ErrCodeAccessDeniedException, "access denied", nil))
+		insights, blocked, err := CheckUpgradeInsights(checkUpgradeInsightsOptions)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blocked).To(BeFalse())
+		Expect(insights).To(BeEmpty())
+	})
+
+	It("should return error if listing insights failed", func() {
+		eksServiceMock.EXPECT().ListInsights(gomock.Any()).Return(nil, errors.New("error listing insights"))
+		_, _, err := CheckUpgradeInsights(checkUpgradeInsightsOptions)
 		Expect(err).To(HaveOccurred())
 	})
 })
@@ -228,23 +370,23 @@ var _ = Describe("UpdateLoggingTypes", func() {
 					},
 				},
 			},
-		).Return(nil, nil)
-		updated, err := UpdateClusterLoggingTypes(updateLoggingTypesOpts)
-		Expect(updated).To(BeTrue())
+		).Return(&eks.UpdateClusterConfigOutput{Update: &eks.Update{Id: aws.String("test")}}, nil)
+		update, err := UpdateClusterLoggingTypes(updateLoggingTypesOpts)
+		Expect(update).ToNot(BeNil())
 		Expect(err).NotTo(HaveOccurred())
 	})
 
 	It("should not update cluster logging types if logging types didn't change", func() {
 		updateLoggingTypesOpts.UpstreamClusterSpec.LoggingTypes = []string{"test1", "test2", "test3-enabled"}
-		updated, err := UpdateClusterLoggingTypes(updateLoggingTypesOpts)
-		Expect(updated).To(BeFalse())
+		update, err := UpdateClusterLoggingTypes(updateLoggingTypesOpts)
+		Expect(update).To(BeNil())
 		Expect(err).NotTo(HaveOccurred())
 	})
 
 	It("should return error if update cluster logging types failed", func() {
 		eksServiceMock.EXPECT().UpdateClusterConfig(gomock.Any()).Return(nil, errors.New("error updating cluster config"))
-		updated, err := UpdateClusterLoggingTypes(updateLoggingTypesOpts)
-		Expect(updated).To(BeFalse())
+		update, err := UpdateClusterLoggingTypes(updateLoggingTypesOpts)
+		Expect(update).To(BeNil())
 		Expect(err).To(HaveOccurred())
 	})
 })
@@ -287,24 +429,24 @@ var _ = Describe("UpdateClusterAccess", func() {
 					EndpointPublicAccess:  aws.Bool(true),
 				},
 			},
-		).Return(nil, nil)
-		updated, err := UpdateClusterAccess(updateClusterAccessOpts)
-		Expect(updated).To(BeTrue())
+		).Return(&eks.UpdateClusterConfigOutput{Update: &eks.Update{Id: aws.String("test")}}, nil)
+		update, err := UpdateClusterAccess(updateClusterAccessOpts)
+		Expect(update).ToNot(BeNil())
 		Expect(err).NotTo(HaveOccurred())
 	})
 
 	It("should not update cluster access if access didn't change", func() {
 		updateClusterAccessOpts.UpstreamClusterSpec.PrivateAccess = aws.Bool(true)
 		updateClusterAccessOpts.UpstreamClusterSpec.PublicAccess = aws.Bool(true)
-		updated, err := UpdateClusterAccess(updateClusterAccessOpts)
-		Expect(updated).To(BeFalse())
+		update, err := UpdateClusterAccess(updateClusterAccessOpts)
+		Expect(update).To(BeNil())
 		Expect(err).NotTo(HaveOccurred())
 	})
 
 	It("should return error if update cluster access failed", func() {
 		eksServiceMock.EXPECT().UpdateClusterConfig(gomock.Any()).Return(nil, errors.New("error updating cluster config"))
-		updated, err := UpdateClusterAccess(updateClusterAccessOpts)
-		Expect(updated).To(BeFalse())
+		update, err := UpdateClusterAccess(updateClusterAccessOpts)
+		Expect(update).To(BeNil())
 		Expect(err).To(HaveOccurred())
 	})
 })
@@ -344,27 +486,217 @@ var _ = Describe("UpdateClusterPublicAccessSources", func() {
 					PublicAccessCidrs: []*string{aws.String("test1"), aws.String("test2")},
 				},
 			},
-		).Return(nil, nil)
-		updated, err := UpdateClusterPublicAccessSources(updateClusterPublicAccessSourcesOpts)
-		Expect(updated).To(BeTrue())
+		).Return(&eks.UpdateClusterConfigOutput{Update: &eks.Update{Id: aws.String("test")}}, nil)
+		update, err := UpdateClusterPublicAccessSources(updateClusterPublicAccessSourcesOpts)
+		Expect(update).ToNot(BeNil())
 		Expect(err).NotTo(HaveOccurred())
 	})
 
 	It("should not update cluster public access sources if public access sources didn't change", func() {
 		updateClusterPublicAccessSourcesOpts.UpstreamClusterSpec.PublicAccessSources = []string{"test1", "test2"}
-		updated, err := UpdateClusterPublicAccessSources(updateClusterPublicAccessSourcesOpts)
-		Expect(updated).To(BeFalse())
+		update, err := UpdateClusterPublicAccessSources(updateClusterPublicAccessSourcesOpts)
+		Expect(update).To(BeNil())
 		Expect(err).NotTo(HaveOccurred())
 	})
 
 	It("should return error if update cluster public access sources failed", func() {
 		eksServiceMock.EXPECT().UpdateClusterConfig(gomock.Any()).Return(nil, errors.New("error updating cluster config"))
-		updated, err := UpdateClusterPublicAccessSources(updateClusterPublicAccessSourcesOpts)
-		Expect(updated).To(BeFalse())
+		update, err := UpdateClusterPublicAccessSources(updateClusterPublicAccessSourcesOpts)
+		Expect(update).To(BeNil())
 		Expect(err).To(HaveOccurred())
 	})
 })
 
+var _ = Describe("UpdateClusterNetwork", func() {
+	var (
+		mockController           *gomock.Controller
+		eksServiceMock           *mock_services.MockEKSServiceInterface
+		ec2ServiceMock           *mock_services.MockEC2ServiceInterface
+		updateClusterNetworkOpts *UpdateClusterNetworkOpts
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		eksServiceMock = mock_services.NewMockEKSServiceInterface(mockController)
+		ec2ServiceMock = mock_services.NewMockEC2ServiceInterface(mockController)
+		updateClusterNetworkOpts = &UpdateClusterNetworkOpts{
+			EKSService: eksServiceMock,
+			EC2Service: ec2ServiceMock,
+			Config: &eksv1.EKSClusterConfig{
+				Spec: eksv1.EKSClusterConfigSpec{
+					DisplayName:    "test",
+					Subnets:        []string{"subnet-1", "subnet-2", "subnet-3"},
+					SecurityGroups: []string{"sg-1"},
+				},
+			},
+			UpstreamClusterSpec: &eksv1.EKSClusterConfigSpec{
+				Subnets:        []string{"subnet-1", "subnet-2"},
+				SecurityGroups: []string{"sg-1"},
+			},
+			VPCID: "vpc-1",
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should update the subnets of the cluster", func() {
+		ec2ServiceMock.EXPECT().DescribeSubnets(&ec2.DescribeSubnetsInput{
+			SubnetIds: aws.StringSlice([]string{"subnet-1", "subnet-2", "subnet-3"}),
+		}).Return(&ec2.DescribeSubnetsOutput{
+			Subnets: []*ec2.Subnet{
+				{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-1")},
+				{SubnetId: aws.String("subnet-2"), VpcId: aws.String("vpc-1")},
+				{SubnetId: aws.String("subnet-3"), VpcId: aws.String("vpc-1")},
+			},
+		}, nil)
+		eksServiceMock.EXPECT().UpdateClusterConfig(
+			&eks.UpdateClusterConfigInput{
+				Name: aws.String("test"),
+				ResourcesVpcConfig: &eks.VpcConfigRequest{
+					SubnetIds: aws.StringSlice([]string{"subnet-1", "subnet-2", "subnet-3"}),
+				},
+			},
+		).Return(&eks.UpdateClusterConfigOutput{Update: &eks.Update{Id: aws.String("test")}}, nil)
+		update, err := UpdateClusterNetwork(updateClusterNetworkOpts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(update).ToNot(BeNil())
+	})
+
+	It("should update the security groups of the cluster", func() {
+		updateClusterNetworkOpts.UpstreamClusterSpec.Subnets = updateClusterNetworkOpts.Config.Spec.Subnets
+		updateClusterNetworkOpts.Config.Spec.SecurityGroups = []string{"sg-2"}
+		eksServiceMock.EXPECT().UpdateClusterConfig(
+			&eks.UpdateClusterConfigInput{
+				Name: aws.String("test"),
+				ResourcesVpcConfig: &eks.VpcConfigRequest{
+					SecurityGroupIds: aws.StringSlice([]string{"sg-2"}),
+				},
+			},
+		).Return(&eks.UpdateClusterConfigOutput{Update: &eks.Update{Id: aws.String("test")}}, nil)
+		update, err := UpdateClusterNetwork(updateClusterNetworkOpts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(update).ToNot(BeNil())
+	})
+
+	It("should not update the cluster if the network didn't change or isn't provided", func() {
+		updateClusterNetworkOpts.UpstreamClusterSpec.Subnets = []string{"subnet-3", "subnet-2", "subnet-1"}
+		update, err := UpdateClusterNetwork(updateClusterNetworkOpts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(update).To(BeNil())
+
+		updateClusterNetworkOpts.Config.Spec.Subnets = nil
+		updateClusterNetworkOpts.Config.Spec.SecurityGroups = []string{"sg-2"}
+		update, err = UpdateClusterNetwork(updateClusterNetworkOpts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(update).To(BeNil())
+	})
+
+	It("should return error if a subnet is not in the vpc of the cluster", func() {
+		ec2ServiceMock.EXPECT().DescribeSubnets(gomock.Any()).Return(&ec2.DescribeSubnetsOutput{
+			Subnets: []*ec2.Subnet{
+				{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-1")},
+				{SubnetId: aws.String("subnet-2"), VpcId: aws.String("vpc-1")},
+				{SubnetId: aws.String("subnet-3"), VpcId: aws.String("vpc-2")},
+			},
+		}, nil)
+		update, err := UpdateClusterNetwork(updateClusterNetworkOpts)
+		Expect(err).To(MatchError(ContainSubstring("subnet-3")))
+		Expect(update).To(BeNil())
+	})
+})
+
+var _ = Describe("EnableSecretsEncryption", func() {
+	var (
+		mockController              *gomock.Controller
+		eksServiceMock              *mock_services.MockEKSServiceInterface
+		enableSecretsEncryptionOpts *EnableSecretsEncryptionOpts
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		eksServiceMock = mock_services.NewMockEKSServiceInterface(mockController)
+		enableSecretsEncryptionOpts = &EnableSecretsEncryptionOpts{
+			EKSService: eksServiceMock,
+			Config: &eksv1.EKSClusterConfig{
+				Spec: eksv1.EKSClusterConfigSpec{
+					DisplayName:       "test",
+					SecretsEncryption: aws.Bool(true),
+					KmsKey:            aws.String("arn:aws:kms:us-east-1:123456789012:key/test"),
+				},
+			},
+			UpstreamClusterSpec: &eksv1.EKSClusterConfigSpec{
+				SecretsEncryption: aws.Bool(false),
+				KmsKey:            aws.String(""),
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should associate the kms key with the cluster", func() {
+		eksServiceMock.EXPECT().AssociateEncryptionConfig(
+			&eks.AssociateEncryptionConfigInput{
+				ClusterName: aws.String("test"),
+				EncryptionConfig: []*eks.EncryptionConfig{
+					{
+						Provider: &eks.Provider{
+							KeyArn: aws.String("arn:aws:kms:us-east-1:123456789012:key/test"),
+						},
+						Resources: aws.StringSlice([]string{"secrets"}),
+					},
+				},
+			},
+		).Return(&eks.AssociateEncryptionConfigOutput{Update: &eks.Update{Id: aws.String("test")}}, nil)
+		update, err := EnableSecretsEncryption(enableSecretsEncryptionOpts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(update).ToNot(BeNil())
+	})
+
+	It("should not update the cluster if secrets encryption is already enabled", func() {
+		enableSecretsEncryptionOpts.UpstreamClusterSpec.SecretsEncryption = aws.Bool(true)
+		enableSecretsEncryptionOpts.UpstreamClusterSpec.KmsKey = enableSecretsEncryptionOpts.Config.Spec.KmsKey
+		update, err := EnableSecretsEncryption(enableSecretsEncryptionOpts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(update).To(BeNil())
+	})
+
+	It("should not update the cluster if secrets encryption is disabled", func() {
+		enableSecretsEncryptionOpts.Config.Spec.SecretsEncryption = aws.Bool(false)
+		update, err := EnableSecretsEncryption(enableSecretsEncryptionOpts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(update).To(BeNil())
+	})
+
+	It("should return error if the kms key is not set", func() {
+		enableSecretsEncryptionOpts.Config.Spec.KmsKey = nil
+		update, err := EnableSecretsEncryption(enableSecretsEncryptionOpts)
+		Expect(err).To(HaveOccurred())
+		Expect(update).To(BeNil())
+	})
+
+	It("should return error if secrets encryption is disabled or its key changed", func() {
+		enableSecretsEncryptionOpts.UpstreamClusterSpec.SecretsEncryption = aws.Bool(true)
+		enableSecretsEncryptionOpts.UpstreamClusterSpec.KmsKey = aws.String("arn:aws:kms:us-east-1:123456789012:key/other")
+		_, err := EnableSecretsEncryption(enableSecretsEncryptionOpts)
+		Expect(err).To(HaveOccurred())
+
+		enableSecretsEncryptionOpts.Config.Spec.SecretsEncryption = aws.Bool(false)
+		_, err = EnableSecretsEncryption(enableSecretsEncryptionOpts)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return error if associating the kms key failed", func() {
+		eksServiceMock.EXPECT().AssociateEncryptionConfig(gomock.Any()).Return(nil, errors.New("error associating encryption config"))
+		update, err := EnableSecretsEncryption(enableSecretsEncryptionOpts)
+		Expect(err).To(HaveOccurred())
+		Expect(update).To(BeNil())
+	})
+})
+
 var _ = Describe("UpdateNodegroupVersion", func() {
 	var (
 		mockController             *gomock.Controller
@@ -397,13 +729,99 @@ var _ = Describe("UpdateNodegroupVersion", func() {
 	})
 
 	It("should update node group version", func() {
-		eksServiceMock.EXPECT().UpdateNodegroupVersion(updateNodegroupVersionOpts.NGVersionInput).Return(nil, nil)
-		Expect(UpdateNodegroupVersion(updateNodegroupVersionOpts)).To(Succeed())
+		eksServiceMock.EXPECT().UpdateNodegroupVersion(updateNodegroupVersionOpts.NGVersionInput).Return(&eks.UpdateNodegroupVersionOutput{
+			Update: &eks.Update{
+				Id:     aws.String("test"),
+				Status: aws.String(eks.UpdateStatusInProgress),
+			},
+		}, nil)
+		update, err := UpdateNodegroupVersion(updateNodegroupVersionOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(aws.StringValue(update.Id)).To(Equal("test"))
 	})
 
 	It("should delete launch template version if update fails", func() {
 		eksServiceMock.EXPECT().UpdateNodegroupVersion(updateNodegroupVersionOpts.NGVersionInput).Return(nil, errors.New("error"))
 		ec2ServiceMock.EXPECT().DeleteLaunchTemplateVersions(gomock.Any()).Return(nil, nil)
-		Expect(UpdateNodegroupVersion(updateNodegroupVersionOpts)).To(HaveOccurred())
+		_, err := UpdateNodegroupVersion(updateNodegroupVersionOpts)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("RefreshUpdate", func() {
+	var (
+		mockController    *gomock.Controller
+		eksServiceMock    *mock_services.MockEKSServiceInterface
+		refreshUpdateOpts *RefreshUpdateOpts
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		eksServiceMock = mock_services.NewMockEKSServiceInterface(mockController)
+		refreshUpdateOpts = &RefreshUpdateOpts{
+			EKSService: eksServiceMock,
+			Config: &eksv1.EKSClusterConfig{
+				Spec: eksv1.EKSClusterConfigSpec{
+					DisplayName: "test",
+				},
+			},
+			NodegroupName: "ng1",
+			Update: eksv1.Update{
+				ID:         "test-update",
+				Type:       eks.UpdateTypeVersionUpdate,
+				Status:     eks.UpdateStatusInProgress,
+				Generation: 2,
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should return the failed update with its errors", func() {
+		eksServiceMock.EXPECT().DescribeUpdate(&eks.DescribeUpdateInput{
+			Name:          aws.String("test"),
+			NodegroupName: aws.String("ng1"),
+			UpdateId:      aws.String("test-update"),
+		}).Return(&eks.DescribeUpdateOutput{
+			Update: &eks.Update{
+				Id:     aws.String("test-update"),
+				Type:   aws.String(eks.UpdateTypeVersionUpdate),
+				Status: aws.String(eks.UpdateStatusFailed),
+				Errors: []*eks.ErrorDetail{
+					{
+						ErrorCode:    aws.String(eks.ErrorCodePodEvictionFailure),
+						ErrorMessage: aws.String("Reached max retries while trying to evict pods"),
+						ResourceIds:  aws.StringSlice([]string{"node-1"}),
+					},
+				},
+			},
+		}, nil)
+
+		update, err := RefreshUpdate(refreshUpdateOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(update.Status).To(Equal(eks.UpdateStatusFailed))
+		Expect(update.Generation).To(Equal(int64(2)))
+		Expect(IsUpdateInProgress(update)).To(BeFalse())
+		Expect(UpdateFailureMessage(update)).To(Equal("VersionUpdate update [test-update] failed: " +
+			"PodEvictionFailure: Reached max retries while trying to evict pods [node-1]"))
+	})
+
+	It("should return the recorded update if describing it fails", func() {
+		eksServiceMock.EXPECT().DescribeUpdate(gomock.Any()).Return(nil, errors.New("error describing update"))
+
+		update, err := RefreshUpdate(refreshUpdateOpts)
+		Expect(err).To(HaveOccurred())
+		Expect(update).To(Equal(refreshUpdateOpts.Update))
+		Expect(IsUpdateInProgress(update)).To(BeTrue())
+	})
+
+	It("should return the recorded update if describing it returns no update", func() {
+		eksServiceMock.EXPECT().DescribeUpdate(gomock.Any()).Return(&eks.DescribeUpdateOutput{}, nil)
+
+		update, err := RefreshUpdate(refreshUpdateOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(update).To(Equal(refreshUpdateOpts.Update))
 	})
 })
@@ -0,0 +1,138 @@
+package eks
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/blang/semver"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services"
+)
+
+// maxNodegroupVersionSkew is the number of minor versions a node group may be behind the control plane.
+const maxNodegroupVersionSkew = 1
+
+// GetUpgradeSteps returns the control plane versions to upgrade to, in order, to go from the current to the target
+// kubernetes version. EKS only upgrades the control plane one minor version at a time.
+func GetUpgradeSteps(currentVersion, targetVersion string) ([]string, error) {
+	current, err := parseKubernetesVersion(currentVersion)
+	if err != nil {
+		return nil, err
+	}
+	target, err := parseKubernetesVersion(targetVersion)
+	if err != nil {
+		return nil, err
+	}
+	if current.Major != target.Major || current.Minor > target.Minor {
+		return nil, fmt.Errorf("cannot upgrade kubernetes version from [%s] to [%s]", currentVersion, targetVersion)
+	}
+
+	var steps []string
+	for minor := current.Minor + 1; minor <= target.Minor; minor++ {
+		steps = append(steps, fmt.Sprintf("%d.%d", current.Major, minor))
+	}
+
+	return steps, nil
+}
+
+// IsWithinVersionSkew returns true if a node group running the given version is supported by a control plane
+// running the cluster version.
+func IsWithinVersionSkew(nodegroupVersion, clusterVersion string) (bool, error) {
+	nodegroup, err := parseKubernetesVersion(nodegroupVersion)
+	if err != nil {
+		return false, err
+	}
+	cluster, err := parseKubernetesVersion(clusterVersion)
+	if err != nil {
+		return false, err
+	}
+
+	return nodegroup.Major == cluster.Major && nodegroup.Minor <= cluster.Minor && cluster.Minor-nodegroup.Minor <= maxNodegroupVersionSkew, nil
+}
+
+func parseKubernetesVersion(version string) (semver.Version, error) {
+	parsed, err := semver.New(fmt.Sprintf("%s.0", version))
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("improper kubernetes version format [%s]", version)
+	}
+
+	return *parsed, nil
+}
+
+type GetIncompatibleAddonsOpts struct {
+	EKSService        services.EKSServiceInterface
+	Config            *eksv1.EKSClusterConfig
+	KubernetesVersion string
+}
+
+// GetIncompatibleAddons returns the installed add-ons, with their versions, whose version is not compatible with
+// the given kubernetes version according to DescribeAddonVersions.
+func GetIncompatibleAddons(opts *GetIncompatibleAddonsOpts) ([]string, error) {
+	var addonNames []*string
+	listInput := &eks.ListAddonsInput{
+		ClusterName: aws.String(opts.Config.Spec.DisplayName),
+	}
+	for {
+		output, err := opts.EKSService.ListAddons(listInput)
+		if err != nil {
+			return nil, fmt.Errorf("error listing add-ons of cluster [%s]: %w", opts.Config.Name, err)
+		}
+		addonNames = append(addonNames, output.Addons...)
+		if aws.StringValue(output.NextToken) == "" {
+			break
+		}
+		listInput.NextToken = output.NextToken
+	}
+
+	var incompatible []string
+	for _, addonName := range addonNames {
+		addon, err := opts.EKSService.DescribeAddon(&eks.DescribeAddonInput{
+			AddonName:   addonName,
+			ClusterName: aws.String(opts.Config.Spec.DisplayName),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing add-on [%s] of cluster [%s]: %w", aws.StringValue(addonName), opts.Config.Name, err)
+		}
+		if addon.Addon == nil {
+			continue
+		}
+
+		addonVersion := aws.StringValue(addon.Addon.AddonVersion)
+		compatible, err := isAddonVersionCompatible(opts.EKSService, aws.StringValue(addonName), addonVersion, opts.KubernetesVersion)
+		if err != nil {
+			return nil, fmt.Errorf("error describing versions of add-on [%s]: %w", aws.StringValue(addonName), err)
+		}
+		if !compatible {
+			incompatible = append(incompatible, fmt.Sprintf("%s %s", aws.StringValue(addonName), addonVersion))
+		}
+	}
+	sort.Strings(incompatible)
+
+	return incompatible, nil
+}
+
+func isAddonVersionCompatible(eksService services.EKSServiceInterface, addonName, addonVersion, kubernetesVersion string) (bool, error) {
+	input := &eks.DescribeAddonVersionsInput{
+		AddonName:         aws.String(addonName),
+		KubernetesVersion: aws.String(kubernetesVersion),
+	}
+	for {
+		output, err := eksService.DescribeAddonVersions(input)
+		if err != nil {
+			return false, err
+		}
+		for _, addon := range output.Addons {
+			for _, versionInfo := range addon.AddonVersions {
+				if aws.StringValue(versionInfo.AddonVersion) == addonVersion {
+					return true, nil
+				}
+			}
+		}
+		if aws.StringValue(output.NextToken) == "" {
+			return false, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
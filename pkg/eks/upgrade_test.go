@@ -0,0 +1,105 @@
+package eks
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+	"github.com/rancher/eks-operator/pkg/eks/services/mock_services"
+)
+
+var _ = Describe("GetUpgradeSteps", func() {
+	It("should step one minor version at a time", func() {
+		steps, err := GetUpgradeSteps("1.24", "1.27")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(steps).To(Equal([]string{"1.25", "1.26", "1.27"}))
+	})
+
+	It("should return no steps for the same version", func() {
+		steps, err := GetUpgradeSteps("1.27", "1.27")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(steps).To(BeEmpty())
+	})
+
+	It("should reject downgrades and improper versions", func() {
+		_, err := GetUpgradeSteps("1.27", "1.26")
+		Expect(err).To(HaveOccurred())
+
+		_, err = GetUpgradeSteps("1.27", "latest")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("IsWithinVersionSkew", func() {
+	It("should allow node groups at or one minor version behind the control plane", func() {
+		Expect(IsWithinVersionSkew("1.25", "1.25")).To(BeTrue())
+		Expect(IsWithinVersionSkew("1.24", "1.25")).To(BeTrue())
+	})
+
+	It("should not allow node groups further behind or ahead of the control plane", func() {
+		Expect(IsWithinVersionSkew("1.23", "1.25")).To(BeFalse())
+		Expect(IsWithinVersionSkew("1.26", "1.25")).To(BeFalse())
+	})
+})
+
+var _ = Describe("GetIncompatibleAddons", func() {
+	var (
+		mockController            *gomock.Controller
+		eksServiceMock            *mock_services.MockEKSServiceInterface
+		getIncompatibleAddonsOpts *GetIncompatibleAddonsOpts
+	)
+
+	BeforeEach(func() {
+		mockController = gomock.NewController(GinkgoT())
+		eksServiceMock = mock_services.NewMockEKSServiceInterface(mockController)
+		getIncompatibleAddonsOpts = &GetIncompatibleAddonsOpts{
+			EKSService: eksServiceMock,
+			Config: &eksv1.EKSClusterConfig{
+				Spec: eksv1.EKSClusterConfigSpec{
+					DisplayName: "test",
+				},
+			},
+			KubernetesVersion: "1.25",
+		}
+	})
+
+	AfterEach(func() {
+		mockController.Finish()
+	})
+
+	It("should return the add-ons whose version is not available for the kubernetes version", func() {
+		eksServiceMock.EXPECT().ListAddons(&eks.ListAddonsInput{ClusterName: aws.String("test")}).Return(&eks.ListAddonsOutput{
+			Addons: aws.StringSlice([]string{"vpc-cni", "coredns"}),
+		}, nil)
+		eksServiceMock.EXPECT().DescribeAddon(&eks.DescribeAddonInput{ClusterName: aws.String("test"), AddonName: aws.String("vpc-cni")}).
+			Return(&eks.DescribeAddonOutput{Addon: &eks.Addon{AddonVersion: aws.String("v1.12.0-eksbuild.1")}}, nil)
+		eksServiceMock.EXPECT().DescribeAddon(&eks.DescribeAddonInput{ClusterName: aws.String("test"), AddonName: aws.String("coredns")}).
+			Return(&eks.DescribeAddonOutput{Addon: &eks.Addon{AddonVersion: aws.String("v1.8.7-eksbuild.3")}}, nil)
+		eksServiceMock.EXPECT().DescribeAddonVersions(&eks.DescribeAddonVersionsInput{AddonName: aws.String("vpc-cni"), KubernetesVersion: aws.String("1.25")}).
+			Return(&eks.DescribeAddonVersionsOutput{
+				Addons: []*eks.AddonInfo{
+					{AddonVersions: []*eks.AddonVersionInfo{{AddonVersion: aws.String("v1.12.0-eksbuild.1")}}},
+				},
+			}, nil)
+		eksServiceMock.EXPECT().DescribeAddonVersions(&eks.DescribeAddonVersionsInput{AddonName: aws.String("coredns"), KubernetesVersion: aws.String("1.25")}).
+			Return(&eks.DescribeAddonVersionsOutput{
+				Addons: []*eks.AddonInfo{
+					{AddonVersions: []*eks.AddonVersionInfo{{AddonVersion: aws.String("v1.9.3-eksbuild.2")}}},
+				},
+			}, nil)
+
+		incompatible, err := GetIncompatibleAddons(getIncompatibleAddonsOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(incompatible).To(Equal([]string{"coredns v1.8.7-eksbuild.3"}))
+	})
+
+	It("should return no add-ons if none are installed", func() {
+		eksServiceMock.EXPECT().ListAddons(gomock.Any()).Return(&eks.ListAddonsOutput{}, nil)
+
+		incompatible, err := GetIncompatibleAddons(getIncompatibleAddonsOpts)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(incompatible).To(BeEmpty())
+	})
+})
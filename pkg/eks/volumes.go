@@ -0,0 +1,252 @@
+package eks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+)
+
+const (
+	gp3MinIops       = 3000
+	gp3MaxIops       = 16000
+	gp3MinThroughput = 125
+	gp3MaxThroughput = 1000
+)
+
+// ValidateBlockDeviceMappings checks the block device mappings of a node group that uses the rancher-managed
+// launch template. With a custom image, a mapping without a volume size is taken to be on the root device of the
+// image, which is checked when the launch template is built.
+func ValidateBlockDeviceMappings(group eksv1.NodeGroup) error {
+	if len(group.BlockDeviceMappings) == 0 {
+		return nil
+	}
+
+	name := aws.StringValue(group.NodegroupName)
+	if group.LaunchTemplate != nil {
+		return fmt.Errorf("nodegroup [%s]: blockDeviceMappings cannot be used with a user provided launch template", name)
+	}
+
+	devices := make(map[string]bool, len(group.BlockDeviceMappings))
+	for _, mapping := range group.BlockDeviceMappings {
+		deviceName, isStorageDevice := getMappingDeviceName(group, mapping)
+		if devices[deviceName] {
+			return fmt.Errorf("nodegroup [%s]: device [%s] is mapped more than once", name, deviceName)
+		}
+		devices[deviceName] = true
+
+		if isStorageDevice && isEmptyStorageMapping(mapping) {
+			return fmt.Errorf("nodegroup [%s]: the mapping of the storage device [%s] does not set any volume setting", name, deviceName)
+		}
+		if isStorageDevice && mapping.VolumeSize != nil {
+			return fmt.Errorf("nodegroup [%s]: volumeSize cannot be set for the storage device [%s], use diskSize instead", name, deviceName)
+		}
+		if !isStorageDevice && aws.Int64Value(mapping.VolumeSize) <= 0 {
+			return fmt.Errorf("nodegroup [%s]: volumeSize must be set for device [%s]", name, deviceName)
+		}
+		if err := validateEBSVolume(mapping); err != nil {
+			return fmt.Errorf("nodegroup [%s]: device [%s]: %w", name, deviceName, err)
+		}
+	}
+
+	return nil
+}
+
+func validateEBSVolume(mapping eksv1.BlockDeviceMapping) error {
+	volumeType := aws.StringValue(mapping.VolumeType)
+	if volumeType != "" {
+		var supported bool
+		for _, value := range ec2.VolumeType_Values() {
+			supported = supported || volumeType == value
+		}
+		if !supported {
+			return fmt.Errorf("unsupported volumeType [%s], valid values are [%s]", volumeType, strings.Join(ec2.VolumeType_Values(), ", "))
+		}
+	}
+
+	if mapping.Iops != nil {
+		switch volumeType {
+		case ec2.VolumeTypeGp3:
+			if iops := aws.Int64Value(mapping.Iops); iops < gp3MinIops || iops > gp3MaxIops {
+				return fmt.Errorf("iops for volumeType [%s] must be between %d and %d", volumeType, gp3MinIops, gp3MaxIops)
+			}
+		case ec2.VolumeTypeIo1, ec2.VolumeTypeIo2:
+		default:
+			return fmt.Errorf("iops can only be set for volumeTypes [%s, %s, %s]", ec2.VolumeTypeGp3, ec2.VolumeTypeIo1, ec2.VolumeTypeIo2)
+		}
+	} else if volumeType == ec2.VolumeTypeIo1 || volumeType == ec2.VolumeTypeIo2 {
+		return fmt.Errorf("iops must be set for volumeType [%s]", volumeType)
+	}
+
+	if mapping.Throughput != nil {
+		if volumeType != ec2.VolumeTypeGp3 {
+			return fmt.Errorf("throughput can only be set for volumeType [%s]", ec2.VolumeTypeGp3)
+		}
+		if throughput := aws.Int64Value(mapping.Throughput); throughput < gp3MinThroughput || throughput > gp3MaxThroughput {
+			return fmt.Errorf("throughput must be between %d and %d", gp3MinThroughput, gp3MaxThroughput)
+		}
+	}
+
+	if aws.StringValue(mapping.KmsKeyID) != "" && !aws.BoolValue(mapping.Encrypted) {
+		return fmt.Errorf("kmsKeyId requires encrypted to be true")
+	}
+
+	return nil
+}
+
+// buildBlockDeviceMappings returns the launch template block device mappings for the node group. The storage device,
+// sized by diskSize, is always the first mapping so that it can be read back from the launch template.
+func buildBlockDeviceMappings(group eksv1.NodeGroup, storageDeviceName *string) []*ec2.LaunchTemplateBlockDeviceMappingRequest {
+	storageDevice := &ec2.LaunchTemplateEbsBlockDeviceRequest{
+		VolumeSize: group.DiskSize,
+	}
+	mappings := []*ec2.LaunchTemplateBlockDeviceMappingRequest{
+		{
+			DeviceName: storageDeviceName,
+			Ebs:        storageDevice,
+		},
+	}
+
+	for _, mapping := range group.BlockDeviceMappings {
+		if _, isStorageDevice := getMappingDeviceName(group, mapping); isStorageDevice || aws.StringValue(mapping.DeviceName) == aws.StringValue(storageDeviceName) {
+			storageDevice.VolumeType = mapping.VolumeType
+			storageDevice.Iops = mapping.Iops
+			storageDevice.Throughput = mapping.Throughput
+			storageDevice.Encrypted = mapping.Encrypted
+			storageDevice.KmsKeyId = mapping.KmsKeyID
+			storageDevice.DeleteOnTermination = mapping.DeleteOnTermination
+			continue
+		}
+
+		mappings = append(mappings, &ec2.LaunchTemplateBlockDeviceMappingRequest{
+			DeviceName: mapping.DeviceName,
+			Ebs: &ec2.LaunchTemplateEbsBlockDeviceRequest{
+				VolumeSize:          mapping.VolumeSize,
+				VolumeType:          mapping.VolumeType,
+				Iops:                mapping.Iops,
+				Throughput:          mapping.Throughput,
+				Encrypted:           mapping.Encrypted,
+				KmsKeyId:            mapping.KmsKeyID,
+				DeleteOnTermination: mapping.DeleteOnTermination,
+			},
+		})
+	}
+
+	return mappings
+}
+
+// GetBlockDeviceMappings converts the block device mappings of a rancher-managed launch template version back to
+// the node group representation. The size of the first mapping is reported through diskSize and is not included.
+func GetBlockDeviceMappings(launchTemplateMappings []*ec2.LaunchTemplateBlockDeviceMapping) []eksv1.BlockDeviceMapping {
+	var mappings []eksv1.BlockDeviceMapping
+	for i, launchTemplateMapping := range launchTemplateMappings {
+		ebs := launchTemplateMapping.Ebs
+		if ebs == nil {
+			continue
+		}
+		mapping := eksv1.BlockDeviceMapping{
+			VolumeType:          ebs.VolumeType,
+			Iops:                ebs.Iops,
+			Throughput:          ebs.Throughput,
+			Encrypted:           ebs.Encrypted,
+			KmsKeyID:            ebs.KmsKeyId,
+			DeleteOnTermination: ebs.DeleteOnTermination,
+		}
+		if i == 0 {
+			if mapping == (eksv1.BlockDeviceMapping{}) {
+				continue
+			}
+		} else {
+			mapping.DeviceName = launchTemplateMapping.DeviceName
+			mapping.VolumeSize = ebs.VolumeSize
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	return mappings
+}
+
+// BlockDeviceMappingsEqual compares the block device mappings of two node groups. Mappings are matched by device
+// name, with the storage device matching either an empty device name or the default storage device of the AMI type.
+func BlockDeviceMappingsEqual(group, otherGroup eksv1.NodeGroup) bool {
+	mappings := blockDeviceMappingsByName(group)
+	otherMappings := blockDeviceMappingsByName(otherGroup)
+	if len(mappings) != len(otherMappings) {
+		return false
+	}
+
+	for deviceName, mapping := range mappings {
+		otherMapping, ok := otherMappings[deviceName]
+		if !ok ||
+			aws.Int64Value(mapping.VolumeSize) != aws.Int64Value(otherMapping.VolumeSize) ||
+			aws.StringValue(mapping.VolumeType) != aws.StringValue(otherMapping.VolumeType) ||
+			aws.Int64Value(mapping.Iops) != aws.Int64Value(otherMapping.Iops) ||
+			aws.Int64Value(mapping.Throughput) != aws.Int64Value(otherMapping.Throughput) ||
+			aws.BoolValue(mapping.Encrypted) != aws.BoolValue(otherMapping.Encrypted) ||
+			aws.StringValue(mapping.KmsKeyID) != aws.StringValue(otherMapping.KmsKeyID) ||
+			aws.BoolValue(mapping.DeleteOnTermination) != aws.BoolValue(otherMapping.DeleteOnTermination) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// blockDeviceMappingsByName returns the block device mappings of the node group by device name. A storage device
+// mapping without settings is left out, as it is when read back from the launch template.
+func blockDeviceMappingsByName(group eksv1.NodeGroup) map[string]eksv1.BlockDeviceMapping {
+	mappings := make(map[string]eksv1.BlockDeviceMapping, len(group.BlockDeviceMappings))
+	for _, mapping := range group.BlockDeviceMappings {
+		deviceName, isStorageDevice := getMappingDeviceName(group, mapping)
+		if isStorageDevice && isEmptyStorageMapping(mapping) {
+			continue
+		}
+		mappings[deviceName] = mapping
+	}
+
+	return mappings
+}
+
+// getMappingDeviceName returns the name a mapping is compared by and true if it maps the storage device, which is
+// named after the default storage device of the AMI type. The storage device is mapped without a device name, with
+// the default name or, for a custom image, with any name and no volume size, as the root device of the image.
+func getMappingDeviceName(group eksv1.NodeGroup, mapping eksv1.BlockDeviceMapping) (string, bool) {
+	defaultDeviceName := getDefaultStorageDeviceName(aws.StringValue(GetAMIType(group)))
+	deviceName := aws.StringValue(mapping.DeviceName)
+	if deviceName == "" || deviceName == defaultDeviceName ||
+		(aws.StringValue(group.ImageID) != "" && mapping.VolumeSize == nil) {
+		return defaultDeviceName, true
+	}
+
+	return deviceName, false
+}
+
+// validateImageRootDeviceMappings checks that the mappings of a node group with a custom image taken to be on the
+// root device of the image, as they have no volume size, use the root device name of the image, and that the
+// mapping of the root device does not set a volume size.
+func validateImageRootDeviceMappings(group eksv1.NodeGroup, rootDeviceName string) error {
+	defaultDeviceName := getDefaultStorageDeviceName(aws.StringValue(GetAMIType(group)))
+	for _, mapping := range group.BlockDeviceMappings {
+		deviceName := aws.StringValue(mapping.DeviceName)
+		if deviceName == rootDeviceName && mapping.VolumeSize != nil {
+			return fmt.Errorf("nodegroup [%s]: volumeSize cannot be set for the root device [%s] of image [%s], use diskSize instead",
+				aws.StringValue(group.NodegroupName), deviceName, aws.StringValue(group.ImageID))
+		}
+		if _, isStorageDevice := getMappingDeviceName(group, mapping); !isStorageDevice ||
+			deviceName == "" || deviceName == defaultDeviceName || deviceName == rootDeviceName {
+			continue
+		}
+		return fmt.Errorf("nodegroup [%s]: device [%s] is not the root device [%s] of image [%s], volumeSize must be set for device [%s]",
+			aws.StringValue(group.NodegroupName), deviceName, rootDeviceName, aws.StringValue(group.ImageID), deviceName)
+	}
+
+	return nil
+}
+
+// isEmptyStorageMapping returns true if the mapping sets none of the settings applied to the storage device.
+func isEmptyStorageMapping(mapping eksv1.BlockDeviceMapping) bool {
+	mapping.DeviceName = nil
+	return mapping == (eksv1.BlockDeviceMapping{})
+}
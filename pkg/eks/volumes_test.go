@@ -0,0 +1,259 @@
+package eks
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	eksv1 "github.com/rancher/eks-operator/pkg/apis/eks.cattle.io/v1"
+)
+
+var _ = Describe("ValidateBlockDeviceMappings", func() {
+	var group eksv1.NodeGroup
+
+	BeforeEach(func() {
+		group = eksv1.NodeGroup{
+			NodegroupName: aws.String("test"),
+			DiskSize:      aws.Int64(20),
+			BlockDeviceMappings: []eksv1.BlockDeviceMapping{
+				{
+					VolumeType: aws.String(ec2.VolumeTypeGp3),
+					Iops:       aws.Int64(4000),
+					Throughput: aws.Int64(250),
+					Encrypted:  aws.Bool(true),
+					KmsKeyID:   aws.String("test-key"),
+				},
+				{
+					DeviceName: aws.String("/dev/xvdb"),
+					VolumeSize: aws.Int64(100),
+					VolumeType: aws.String(ec2.VolumeTypeIo2),
+					Iops:       aws.Int64(10000),
+				},
+			},
+		}
+	})
+
+	It("should accept valid block device mappings", func() {
+		Expect(ValidateBlockDeviceMappings(group)).To(Succeed())
+	})
+
+	It("should reject block device mappings with a user provided launch template", func() {
+		group.LaunchTemplate = &eksv1.LaunchTemplate{ID: aws.String("test")}
+		Expect(ValidateBlockDeviceMappings(group)).ToNot(Succeed())
+	})
+
+	It("should reject a volume size on the storage device", func() {
+		group.BlockDeviceMappings[0].VolumeSize = aws.Int64(50)
+		Expect(ValidateBlockDeviceMappings(group)).ToNot(Succeed())
+	})
+
+	It("should reject a storage device mapping without settings", func() {
+		group.BlockDeviceMappings[0] = eksv1.BlockDeviceMapping{DeviceName: aws.String(defaultStorageDeviceName)}
+		Expect(ValidateBlockDeviceMappings(group)).To(MatchError(ContainSubstring("does not set any volume setting")))
+	})
+
+	It("should reject data volumes without a size", func() {
+		group.BlockDeviceMappings[1].VolumeSize = nil
+		Expect(ValidateBlockDeviceMappings(group)).ToNot(Succeed())
+	})
+
+	It("should accept a mapping on the root device of a custom image without a size", func() {
+		group.ImageID = aws.String("ami-test")
+		group.BlockDeviceMappings[0].DeviceName = aws.String("/dev/sda1")
+		Expect(ValidateBlockDeviceMappings(group)).To(Succeed())
+
+		group.BlockDeviceMappings = append(group.BlockDeviceMappings, eksv1.BlockDeviceMapping{
+			DeviceName: aws.String("/dev/sdb"),
+			Encrypted:  aws.Bool(true),
+		})
+		Expect(ValidateBlockDeviceMappings(group)).To(MatchError(ContainSubstring("is mapped more than once")))
+	})
+
+	It("should reject devices mapped more than once", func() {
+		group.BlockDeviceMappings[1].DeviceName = aws.String(defaultStorageDeviceName)
+		group.BlockDeviceMappings[1].VolumeSize = nil
+		Expect(ValidateBlockDeviceMappings(group)).ToNot(Succeed())
+	})
+
+	It("should reject throughput for volume types other than gp3", func() {
+		group.BlockDeviceMappings[0].VolumeType = aws.String(ec2.VolumeTypeGp2)
+		group.BlockDeviceMappings[0].Iops = nil
+		Expect(ValidateBlockDeviceMappings(group)).ToNot(Succeed())
+	})
+
+	It("should reject out of range gp3 iops", func() {
+		group.BlockDeviceMappings[0].Iops = aws.Int64(100)
+		Expect(ValidateBlockDeviceMappings(group)).ToNot(Succeed())
+	})
+
+	It("should reject io2 volumes without iops", func() {
+		group.BlockDeviceMappings[1].Iops = nil
+		Expect(ValidateBlockDeviceMappings(group)).ToNot(Succeed())
+	})
+
+	It("should reject a kms key without encryption", func() {
+		group.BlockDeviceMappings[0].Encrypted = aws.Bool(false)
+		Expect(ValidateBlockDeviceMappings(group)).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("buildBlockDeviceMappings", func() {
+	It("should only map the storage device when no block device mappings are set", func() {
+		group := eksv1.NodeGroup{DiskSize: aws.Int64(20)}
+		mappings := buildBlockDeviceMappings(group, aws.String(defaultStorageDeviceName))
+		Expect(mappings).To(Equal([]*ec2.LaunchTemplateBlockDeviceMappingRequest{
+			{
+				DeviceName: aws.String(defaultStorageDeviceName),
+				Ebs: &ec2.LaunchTemplateEbsBlockDeviceRequest{
+					VolumeSize: aws.Int64(20),
+				},
+			},
+		}))
+	})
+
+	It("should customise the storage device and add data volumes", func() {
+		group := eksv1.NodeGroup{
+			DiskSize: aws.Int64(20),
+			AMIType:  aws.String(eks.AMITypesBottlerocketX8664),
+			BlockDeviceMappings: []eksv1.BlockDeviceMapping{
+				{
+					DeviceName: aws.String("/dev/xvdc"),
+					VolumeSize: aws.Int64(100),
+				},
+				{
+					DeviceName:          aws.String(bottlerocketStorageDeviceName),
+					VolumeType:          aws.String(ec2.VolumeTypeGp3),
+					Throughput:          aws.Int64(500),
+					DeleteOnTermination: aws.Bool(true),
+				},
+			},
+		}
+		mappings := buildBlockDeviceMappings(group, aws.String(bottlerocketStorageDeviceName))
+		Expect(mappings).To(Equal([]*ec2.LaunchTemplateBlockDeviceMappingRequest{
+			{
+				DeviceName: aws.String(bottlerocketStorageDeviceName),
+				Ebs: &ec2.LaunchTemplateEbsBlockDeviceRequest{
+					VolumeSize:          aws.Int64(20),
+					VolumeType:          aws.String(ec2.VolumeTypeGp3),
+					Throughput:          aws.Int64(500),
+					DeleteOnTermination: aws.Bool(true),
+				},
+			},
+			{
+				DeviceName: aws.String("/dev/xvdc"),
+				Ebs: &ec2.LaunchTemplateEbsBlockDeviceRequest{
+					VolumeSize: aws.Int64(100),
+				},
+			},
+		}))
+	})
+})
+
+var _ = Describe("GetBlockDeviceMappings", func() {
+	It("should round trip the block device mappings of a node group", func() {
+		group := eksv1.NodeGroup{
+			DiskSize: aws.Int64(20),
+			BlockDeviceMappings: []eksv1.BlockDeviceMapping{
+				{
+					VolumeType: aws.String(ec2.VolumeTypeGp3),
+					Iops:       aws.Int64(3000),
+				},
+				{
+					DeviceName: aws.String("/dev/xvdb"),
+					VolumeSize: aws.Int64(100),
+					Encrypted:  aws.Bool(true),
+				},
+			},
+		}
+
+		var launchTemplateMappings []*ec2.LaunchTemplateBlockDeviceMapping
+		for _, mapping := range buildBlockDeviceMappings(group, aws.String(defaultStorageDeviceName)) {
+			launchTemplateMappings = append(launchTemplateMappings, &ec2.LaunchTemplateBlockDeviceMapping{
+				DeviceName: mapping.DeviceName,
+				Ebs: &ec2.LaunchTemplateEbsBlockDevice{
+					VolumeSize: mapping.Ebs.VolumeSize,
+					VolumeType: mapping.Ebs.VolumeType,
+					Iops:       mapping.Ebs.Iops,
+					Encrypted:  mapping.Ebs.Encrypted,
+				},
+			})
+		}
+
+		upstreamGroup := eksv1.NodeGroup{
+			DiskSize:            launchTemplateMappings[0].Ebs.VolumeSize,
+			BlockDeviceMappings: GetBlockDeviceMappings(launchTemplateMappings),
+		}
+		Expect(upstreamGroup.BlockDeviceMappings).To(Equal(group.BlockDeviceMappings))
+		Expect(BlockDeviceMappingsEqual(group, upstreamGroup)).To(BeTrue())
+	})
+
+	It("should round trip a storage device mapping without settings", func() {
+		group := eksv1.NodeGroup{
+			DiskSize:            aws.Int64(20),
+			BlockDeviceMappings: []eksv1.BlockDeviceMapping{{}},
+		}
+
+		var launchTemplateMappings []*ec2.LaunchTemplateBlockDeviceMapping
+		for _, mapping := range buildBlockDeviceMappings(group, aws.String(defaultStorageDeviceName)) {
+			launchTemplateMappings = append(launchTemplateMappings, &ec2.LaunchTemplateBlockDeviceMapping{
+				DeviceName: mapping.DeviceName,
+				Ebs:        &ec2.LaunchTemplateEbsBlockDevice{VolumeSize: mapping.Ebs.VolumeSize},
+			})
+		}
+
+		upstreamGroup := eksv1.NodeGroup{
+			DiskSize:            launchTemplateMappings[0].Ebs.VolumeSize,
+			BlockDeviceMappings: GetBlockDeviceMappings(launchTemplateMappings),
+		}
+		Expect(upstreamGroup.BlockDeviceMappings).To(BeNil())
+		Expect(BlockDeviceMappingsEqual(group, upstreamGroup)).To(BeTrue())
+	})
+
+	It("should not report a plain storage device", func() {
+		mappings := GetBlockDeviceMappings([]*ec2.LaunchTemplateBlockDeviceMapping{
+			{
+				DeviceName: aws.String(defaultStorageDeviceName),
+				Ebs:        &ec2.LaunchTemplateEbsBlockDevice{VolumeSize: aws.Int64(20)},
+			},
+		})
+		Expect(mappings).To(BeNil())
+	})
+})
+
+var _ = Describe("BlockDeviceMappingsEqual", func() {
+	It("should match the storage device by name or empty name", func() {
+		group := eksv1.NodeGroup{
+			BlockDeviceMappings: []eksv1.BlockDeviceMapping{{DeviceName: aws.String(defaultStorageDeviceName), Encrypted: aws.Bool(true)}},
+		}
+		upstreamGroup := eksv1.NodeGroup{
+			BlockDeviceMappings: []eksv1.BlockDeviceMapping{{Encrypted: aws.Bool(true)}},
+		}
+		Expect(BlockDeviceMappingsEqual(group, upstreamGroup)).To(BeTrue())
+	})
+
+	It("should match the root device of a custom image with the storage device", func() {
+		group := eksv1.NodeGroup{
+			ImageID:             aws.String("ami-test"),
+			BlockDeviceMappings: []eksv1.BlockDeviceMapping{{DeviceName: aws.String("/dev/sda1"), Encrypted: aws.Bool(true)}},
+		}
+		upstreamGroup := eksv1.NodeGroup{
+			ImageID: aws.String("ami-test"),
+			BlockDeviceMappings: GetBlockDeviceMappings([]*ec2.LaunchTemplateBlockDeviceMapping{
+				{DeviceName: aws.String("/dev/sda1"), Ebs: &ec2.LaunchTemplateEbsBlockDevice{VolumeSize: aws.Int64(20), Encrypted: aws.Bool(true)}},
+			}),
+		}
+		Expect(BlockDeviceMappingsEqual(group, upstreamGroup)).To(BeTrue())
+	})
+
+	It("should detect changed volumes", func() {
+		group := eksv1.NodeGroup{
+			BlockDeviceMappings: []eksv1.BlockDeviceMapping{{DeviceName: aws.String("/dev/xvdb"), VolumeSize: aws.Int64(100)}},
+		}
+		upstreamGroup := eksv1.NodeGroup{
+			BlockDeviceMappings: []eksv1.BlockDeviceMapping{{DeviceName: aws.String("/dev/xvdb"), VolumeSize: aws.Int64(50)}},
+		}
+		Expect(BlockDeviceMappingsEqual(group, upstreamGroup)).To(BeFalse())
+		Expect(BlockDeviceMappingsEqual(group, eksv1.NodeGroup{})).To(BeFalse())
+	})
+})
@@ -192,6 +192,409 @@ Outputs:
       - !Join [ ",", [ !Ref Subnet01, !Ref Subnet02, !Ref Subnet03 ] ]
       - !Join [ ",", [ !Ref Subnet01, !Ref Subnet02 ] ]
 
+  VpcId:
+    Description: The VPC Id
+    Value: !Ref VPC
+`
+	VpcIPv6Template = `---
+AWSTemplateFormatVersion: '2010-09-09'
+Description: 'Amazon EKS Sample VPC - Dual-stack public and private subnets'
+
+Parameters:
+
+  VpcBlock:
+    Type: String
+    Default: 192.168.0.0/16
+    Description: The CIDR range for the VPC. This should be a valid private (RFC 1918) CIDR range.
+
+  Subnet01Block:
+    Type: String
+    Default: 192.168.64.0/18
+    Description: CidrBlock for public subnet 01 within the VPC
+
+  Subnet02Block:
+    Type: String
+    Default: 192.168.128.0/18
+    Description: CidrBlock for public subnet 02 within the VPC
+
+  Subnet03Block:
+    Type: String
+    Default: 192.168.192.0/18
+    Description: CidrBlock for public subnet 03 within the VPC. This is used only if the region has more than 2 AZs.
+
+  PrivateSubnet01Block:
+    Type: String
+    Default: 192.168.0.0/20
+    Description: CidrBlock for private subnet 01 within the VPC
+
+  PrivateSubnet02Block:
+    Type: String
+    Default: 192.168.16.0/20
+    Description: CidrBlock for private subnet 02 within the VPC
+
+  PrivateSubnet03Block:
+    Type: String
+    Default: 192.168.32.0/20
+    Description: CidrBlock for private subnet 03 within the VPC. This is used only if the region has more than 2 AZs.
+
+Metadata:
+  AWS::CloudFormation::Interface:
+    ParameterGroups:
+      -
+        Label:
+          default: "Worker Network Configuration"
+        Parameters:
+          - VpcBlock
+          - Subnet01Block
+          - Subnet02Block
+          - Subnet03Block
+          - PrivateSubnet01Block
+          - PrivateSubnet02Block
+          - PrivateSubnet03Block
+
+Conditions:
+  Has2Azs:
+    Fn::Or:
+      - Fn::Equals:
+        - {Ref: 'AWS::Region'}
+        - ap-south-1
+      - Fn::Equals:
+        - {Ref: 'AWS::Region'}
+        - ap-northeast-2
+      - Fn::Equals:
+        - {Ref: 'AWS::Region'}
+        - ca-central-1
+      - Fn::Equals:
+        - {Ref: 'AWS::Region'}
+        - cn-north-1
+
+  HasMoreThan2Azs:
+    Fn::Not:
+      - Condition: Has2Azs
+
+Resources:
+  VPC:
+    Type: AWS::EC2::VPC
+    Properties:
+      CidrBlock:  !Ref VpcBlock
+      EnableDnsSupport: true
+      EnableDnsHostnames: true
+      Tags:
+      - Key: Name
+        Value: !Sub '${AWS::StackName}-VPC'
+
+  IPv6CidrBlock:
+    Type: AWS::EC2::VPCCidrBlock
+    Properties:
+      AmazonProvidedIpv6CidrBlock: true
+      VpcId: !Ref VPC
+
+  InternetGateway:
+    Type: "AWS::EC2::InternetGateway"
+
+  VPCGatewayAttachment:
+    Type: "AWS::EC2::VPCGatewayAttachment"
+    Properties:
+      InternetGatewayId: !Ref InternetGateway
+      VpcId: !Ref VPC
+
+  EgressOnlyInternetGateway:
+    Type: AWS::EC2::EgressOnlyInternetGateway
+    Properties:
+      VpcId: !Ref VPC
+
+  NatGatewayEIP:
+    DependsOn: VPCGatewayAttachment
+    Type: AWS::EC2::EIP
+    Properties:
+      Domain: vpc
+
+  NatGateway:
+    Type: AWS::EC2::NatGateway
+    Properties:
+      AllocationId: !GetAtt NatGatewayEIP.AllocationId
+      SubnetId: !Ref Subnet01
+      Tags:
+      - Key: Name
+        Value: !Sub '${AWS::StackName}-NatGateway'
+
+  RouteTable:
+    Type: AWS::EC2::RouteTable
+    Properties:
+      VpcId: !Ref VPC
+      Tags:
+      - Key: Name
+        Value: Public Subnets
+      - Key: Network
+        Value: Public
+
+  PrivateRouteTable:
+    Type: AWS::EC2::RouteTable
+    Properties:
+      VpcId: !Ref VPC
+      Tags:
+      - Key: Name
+        Value: Private Subnets
+      - Key: Network
+        Value: Private
+
+  Route:
+    DependsOn: VPCGatewayAttachment
+    Type: AWS::EC2::Route
+    Properties:
+      RouteTableId: !Ref RouteTable
+      DestinationCidrBlock: 0.0.0.0/0
+      GatewayId: !Ref InternetGateway
+
+  IPv6Route:
+    DependsOn: VPCGatewayAttachment
+    Type: AWS::EC2::Route
+    Properties:
+      RouteTableId: !Ref RouteTable
+      DestinationIpv6CidrBlock: ::/0
+      GatewayId: !Ref InternetGateway
+
+  PrivateRoute:
+    Type: AWS::EC2::Route
+    Properties:
+      RouteTableId: !Ref PrivateRouteTable
+      DestinationCidrBlock: 0.0.0.0/0
+      NatGatewayId: !Ref NatGateway
+
+  PrivateIPv6Route:
+    Type: AWS::EC2::Route
+    Properties:
+      RouteTableId: !Ref PrivateRouteTable
+      DestinationIpv6CidrBlock: ::/0
+      EgressOnlyInternetGatewayId: !Ref EgressOnlyInternetGateway
+
+  Subnet01:
+    DependsOn: IPv6CidrBlock
+    Type: AWS::EC2::Subnet
+    Metadata:
+      Comment: Subnet 01
+    Properties:
+      MapPublicIpOnLaunch: true
+      AssignIpv6AddressOnCreation: true
+      AvailabilityZone:
+        Fn::Select:
+        - '0'
+        - Fn::GetAZs:
+            Ref: AWS::Region
+      CidrBlock:
+        Ref: Subnet01Block
+      Ipv6CidrBlock:
+        Fn::Select:
+        - 0
+        - Fn::Cidr:
+          - Fn::Select: [0, !GetAtt VPC.Ipv6CidrBlocks]
+          - 6
+          - 64
+      VpcId:
+        Ref: VPC
+      Tags:
+      - Key: Name
+        Value: !Sub "${AWS::StackName}-Subnet01"
+      - Key: kubernetes.io/role/elb
+        Value: 1
+
+  Subnet02:
+    DependsOn: IPv6CidrBlock
+    Type: AWS::EC2::Subnet
+    Metadata:
+      Comment: Subnet 02
+    Properties:
+      MapPublicIpOnLaunch: true
+      AssignIpv6AddressOnCreation: true
+      AvailabilityZone:
+        Fn::Select:
+        - '1'
+        - Fn::GetAZs:
+            Ref: AWS::Region
+      CidrBlock:
+        Ref: Subnet02Block
+      Ipv6CidrBlock:
+        Fn::Select:
+        - 1
+        - Fn::Cidr:
+          - Fn::Select: [0, !GetAtt VPC.Ipv6CidrBlocks]
+          - 6
+          - 64
+      VpcId:
+        Ref: VPC
+      Tags:
+      - Key: Name
+        Value: !Sub "${AWS::StackName}-Subnet02"
+      - Key: kubernetes.io/role/elb
+        Value: 1
+
+  Subnet03:
+    Condition: HasMoreThan2Azs
+    DependsOn: IPv6CidrBlock
+    Type: AWS::EC2::Subnet
+    Metadata:
+      Comment: Subnet 03
+    Properties:
+      MapPublicIpOnLaunch: true
+      AssignIpv6AddressOnCreation: true
+      AvailabilityZone:
+        Fn::Select:
+        - '2'
+        - Fn::GetAZs:
+            Ref: AWS::Region
+      CidrBlock:
+        Ref: Subnet03Block
+      Ipv6CidrBlock:
+        Fn::Select:
+        - 2
+        - Fn::Cidr:
+          - Fn::Select: [0, !GetAtt VPC.Ipv6CidrBlocks]
+          - 6
+          - 64
+      VpcId:
+        Ref: VPC
+      Tags:
+      - Key: Name
+        Value: !Sub "${AWS::StackName}-Subnet03"
+      - Key: kubernetes.io/role/elb
+        Value: 1
+
+  PrivateSubnet01:
+    DependsOn: IPv6CidrBlock
+    Type: AWS::EC2::Subnet
+    Metadata:
+      Comment: Private Subnet 01
+    Properties:
+      AssignIpv6AddressOnCreation: true
+      AvailabilityZone:
+        Fn::Select:
+        - '0'
+        - Fn::GetAZs:
+            Ref: AWS::Region
+      CidrBlock:
+        Ref: PrivateSubnet01Block
+      Ipv6CidrBlock:
+        Fn::Select:
+        - 3
+        - Fn::Cidr:
+          - Fn::Select: [0, !GetAtt VPC.Ipv6CidrBlocks]
+          - 6
+          - 64
+      VpcId:
+        Ref: VPC
+      Tags:
+      - Key: Name
+        Value: !Sub "${AWS::StackName}-PrivateSubnet01"
+      - Key: kubernetes.io/role/internal-elb
+        Value: 1
+
+  PrivateSubnet02:
+    DependsOn: IPv6CidrBlock
+    Type: AWS::EC2::Subnet
+    Metadata:
+      Comment: Private Subnet 02
+    Properties:
+      AssignIpv6AddressOnCreation: true
+      AvailabilityZone:
+        Fn::Select:
+        - '1'
+        - Fn::GetAZs:
+            Ref: AWS::Region
+      CidrBlock:
+        Ref: PrivateSubnet02Block
+      Ipv6CidrBlock:
+        Fn::Select:
+        - 4
+        - Fn::Cidr:
+          - Fn::Select: [0, !GetAtt VPC.Ipv6CidrBlocks]
+          - 6
+          - 64
+      VpcId:
+        Ref: VPC
+      Tags:
+      - Key: Name
+        Value: !Sub "${AWS::StackName}-PrivateSubnet02"
+      - Key: kubernetes.io/role/internal-elb
+        Value: 1
+
+  PrivateSubnet03:
+    Condition: HasMoreThan2Azs
+    DependsOn: IPv6CidrBlock
+    Type: AWS::EC2::Subnet
+    Metadata:
+      Comment: Private Subnet 03
+    Properties:
+      AssignIpv6AddressOnCreation: true
+      AvailabilityZone:
+        Fn::Select:
+        - '2'
+        - Fn::GetAZs:
+            Ref: AWS::Region
+      CidrBlock:
+        Ref: PrivateSubnet03Block
+      Ipv6CidrBlock:
+        Fn::Select:
+        - 5
+        - Fn::Cidr:
+          - Fn::Select: [0, !GetAtt VPC.Ipv6CidrBlocks]
+          - 6
+          - 64
+      VpcId:
+        Ref: VPC
+      Tags:
+      - Key: Name
+        Value: !Sub "${AWS::StackName}-PrivateSubnet03"
+      - Key: kubernetes.io/role/internal-elb
+        Value: 1
+
+  Subnet01RouteTableAssociation:
+    Type: AWS::EC2::SubnetRouteTableAssociation
+    Properties:
+      SubnetId: !Ref Subnet01
+      RouteTableId: !Ref RouteTable
+
+  Subnet02RouteTableAssociation:
+    Type: AWS::EC2::SubnetRouteTableAssociation
+    Properties:
+      SubnetId: !Ref Subnet02
+      RouteTableId: !Ref RouteTable
+
+  Subnet03RouteTableAssociation:
+    Condition: HasMoreThan2Azs
+    Type: AWS::EC2::SubnetRouteTableAssociation
+    Properties:
+      SubnetId: !Ref Subnet03
+      RouteTableId: !Ref RouteTable
+
+  PrivateSubnet01RouteTableAssociation:
+    Type: AWS::EC2::SubnetRouteTableAssociation
+    Properties:
+      SubnetId: !Ref PrivateSubnet01
+      RouteTableId: !Ref PrivateRouteTable
+
+  PrivateSubnet02RouteTableAssociation:
+    Type: AWS::EC2::SubnetRouteTableAssociation
+    Properties:
+      SubnetId: !Ref PrivateSubnet02
+      RouteTableId: !Ref PrivateRouteTable
+
+  PrivateSubnet03RouteTableAssociation:
+    Condition: HasMoreThan2Azs
+    Type: AWS::EC2::SubnetRouteTableAssociation
+    Properties:
+      SubnetId: !Ref PrivateSubnet03
+      RouteTableId: !Ref PrivateRouteTable
+
+Outputs:
+
+  SubnetIds:
+    Description: All subnets in the VPC
+    Value:
+      Fn::If:
+      - HasMoreThan2Azs
+      - !Join [ ",", [ !Ref Subnet01, !Ref Subnet02, !Ref Subnet03, !Ref PrivateSubnet01, !Ref PrivateSubnet02, !Ref PrivateSubnet03 ] ]
+      - !Join [ ",", [ !Ref Subnet01, !Ref Subnet02, !Ref PrivateSubnet01, !Ref PrivateSubnet02 ] ]
+
   VpcId:
     Description: The VPC Id
     Value: !Ref VPC
@@ -201,6 +604,17 @@ AWSTemplateFormatVersion: 2010-09-09
 Description: Amazon EKS - Node Group
 
 
+Parameters:
+
+  EnableIPv6:
+    Type: String
+    Default: "false"
+    AllowedValues: ["true", "false"]
+    Description: Whether the VPC CNI needs permissions to assign IPv6 addresses to pods
+
+Conditions:
+  IsIPv6: !Equals [!Ref EnableIPv6, "true"]
+
 Resources:
 
   NodeInstanceRole:
@@ -218,6 +632,26 @@ Resources:
         - arn:aws:iam::aws:policy/AmazonEKSWorkerNodePolicy
         - arn:aws:iam::aws:policy/AmazonEKS_CNI_Policy
         - arn:aws:iam::aws:policy/AmazonEC2ContainerRegistryReadOnly
+      Policies:
+        Fn::If:
+        - IsIPv6
+        - - PolicyName: AmazonEKS_CNI_IPv6_Policy
+            PolicyDocument:
+              Version: 2012-10-17
+              Statement:
+                - Effect: Allow
+                  Action:
+                    - ec2:AssignIpv6Addresses
+                    - ec2:DescribeInstances
+                    - ec2:DescribeTags
+                    - ec2:DescribeNetworkInterfaces
+                    - ec2:DescribeInstanceTypes
+                  Resource: "*"
+                - Effect: Allow
+                  Action:
+                    - ec2:CreateTags
+                  Resource: !Sub "arn:${AWS::Partition}:ec2:*:*:network-interface/*"
+        - !Ref AWS::NoValue
 
 Outputs:
 
@@ -299,5 +733,71 @@ Outputs:
     Export:
       Name: !Sub "${AWS::StackName}-RoleArn"
 
+`
+	KMSKeyTemplate = `---
+AWSTemplateFormatVersion: '2010-09-09'
+Description: 'Amazon EKS Secrets Encryption Key'
+
+
+Parameters:
+
+  ClusterRoleArn:
+    Type: String
+    Description: The ARN of the role of the cluster, which is allowed to use the key
+
+  AliasName:
+    Type: String
+    Description: The alias of the key
+
+  PendingWindowInDays:
+    Type: Number
+    Default: 30
+    MinValue: 7
+    MaxValue: 30
+    Description: The number of days before the key is deleted once the stack is deleted
+
+Resources:
+
+  SecretsEncryptionKey:
+    Type: AWS::KMS::Key
+    Properties:
+      Description: !Sub "Encryption key for the secrets of the EKS cluster of stack ${AWS::StackName}"
+      EnableKeyRotation: true
+      PendingWindowInDays: !Ref PendingWindowInDays
+      KeyPolicy:
+        Version: '2012-10-17'
+        Statement:
+        - Sid: AllowAccountAdministration
+          Effect: Allow
+          Principal:
+            AWS: !Sub "arn:${AWS::Partition}:iam::${AWS::AccountId}:root"
+          Action: kms:*
+          Resource: '*'
+        - Sid: AllowClusterRole
+          Effect: Allow
+          Principal:
+            AWS: !Ref ClusterRoleArn
+          Action:
+          - kms:Encrypt
+          - kms:Decrypt
+          - kms:DescribeKey
+          - kms:CreateGrant
+          - kms:ListGrants
+          Resource: '*'
+
+  SecretsEncryptionKeyAlias:
+    Type: AWS::KMS::Alias
+    Properties:
+      AliasName: !Ref AliasName
+      TargetKeyId: !Ref SecretsEncryptionKey
+
+Outputs:
+
+  KeyArn:
+    Description: The key that EKS will use to encrypt the secrets of the cluster
+    Value: !GetAtt SecretsEncryptionKey.Arn
+    Export:
+      Name: !Sub "${AWS::StackName}-KeyArn"
+
 `
 )